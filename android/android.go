@@ -0,0 +1,349 @@
+// Package android converts Android string resources, as in
+// res/values/strings.xml, to and from Fluent.
+package android
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A File is a parsed strings.xml file.
+type File struct {
+	Resources []Resource
+}
+
+// A Kind is the kind of a resource.
+type Kind int
+
+const (
+	String Kind = iota
+	Plurals
+	StringArray
+)
+
+// A Resource is a <string>, <plurals> or <string-array> element.
+type Resource struct {
+	Kind Kind
+	Name string
+
+	// Comment is the text of the XML comments before the element.
+	Comment string
+
+	// Value is the text of a string. Escape sequences are replaced by the
+	// characters they represent, while styling markup such as <b> is kept.
+	Value string
+
+	// Items are the items of plurals, with their quantity, or of a string
+	// array, with the text like Value.
+	Items []Item
+}
+
+// An Item is an item of plurals or a string array.
+type Item struct {
+	// Quantity is the plural category of the item, e.g. "one", if it is an
+	// item of plurals.
+	Quantity string
+
+	Value string
+}
+
+// xliffNamespace is the namespace of <xliff:g> elements, which mark text that
+// should not be translated, e.g. placeholders, and are replaced by their
+// content.
+const xliffNamespace = "urn:oasis:names:tc:xliff:document:1.2"
+
+// Parse parses a strings.xml file. Other elements than strings, plurals and
+// string arrays are skipped.
+func Parse(data []byte) (*File, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	file := &File{Resources: make([]Resource, 0)}
+
+	var comments []string
+	depth := 0
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.Comment:
+			if depth == 1 {
+				comments = append(comments, strings.TrimSpace(string(token)))
+			}
+		case xml.EndElement:
+			depth--
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				if token.Name.Local != "resources" {
+					return nil, fmt.Errorf("expected <resources>, got <%s>", token.Name.Local)
+				}
+				continue
+			}
+
+			resource := Resource{Name: attr(token, "name"), Comment: strings.Join(comments, "\n")}
+			comments = nil
+			switch token.Name.Local {
+			case "string":
+				resource.Kind = String
+				resource.Value, err = readText(d)
+			case "plurals":
+				resource.Kind = Plurals
+				resource.Items, err = readItems(d, true)
+			case "string-array":
+				resource.Kind = StringArray
+				resource.Items, err = readItems(d, false)
+			default:
+				err = d.Skip()
+				depth--
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			depth--
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %v", token.Name.Local, resource.Name, err)
+			}
+			file.Resources = append(file.Resources, resource)
+		}
+	}
+	return file, nil
+}
+
+func attr(element xml.StartElement, name string) string {
+	for _, a := range element.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// readItems reads the <item> elements of plurals or a string array, up to its
+// end.
+func readItems(d *xml.Decoder, plurals bool) ([]Item, error) {
+	var items []Item
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.EndElement:
+			return items, nil
+		case xml.StartElement:
+			if token.Name.Local != "item" {
+				return nil, fmt.Errorf("unexpected <%s>", token.Name.Local)
+			}
+			item := Item{}
+			if plurals {
+				item.Quantity = attr(token, "quantity")
+			}
+			item.Value, err = readText(d)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+}
+
+// readText reads the text of an element up to its end, unescaped, with
+// styling markup written as XML.
+func readText(d *xml.Decoder) (string, error) {
+	var u unescaper
+	depth := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch token := token.(type) {
+		case xml.CharData:
+			u.text(string(token))
+		case xml.StartElement:
+			depth++
+			if token.Name.Space != xliffNamespace {
+				u.writeString(startTagString(token))
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				return u.String(), nil
+			}
+			depth--
+			if token.Name.Space != xliffNamespace {
+				u.writeString("</" + token.Name.Local + ">")
+			}
+		}
+	}
+}
+
+func startTagString(element xml.StartElement) string {
+	var b strings.Builder
+	b.WriteString("<" + element.Name.Local)
+	for _, a := range element.Attr {
+		if a.Name.Space != "" {
+			continue // e.g. a namespace declaration
+		}
+		b.WriteString(" " + a.Name.Local + `="` + attrEscaper.Replace(a.Value) + `"`)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// unescaper unescapes the text of strings like aapt: whitespace is collapsed
+// and trimmed outside of double quotes, which are removed, and escape
+// sequences such as \' and \n are replaced.
+type unescaper struct {
+	b      strings.Builder
+	quoted bool
+	escape bool // whether a backslash precedes the next character
+	space  bool // whether whitespace precedes the next character
+
+	// unicode is the \uXXXX escape sequence being read, without the
+	// backslash.
+	unicode string
+}
+
+func (u *unescaper) text(s string) {
+	for _, r := range s {
+		switch {
+		case u.unicode != "" || u.escape && r == 'u':
+			u.escapeUnicode(r)
+		case u.escape:
+			u.escape = false
+			switch r {
+			case 'n':
+				u.write('\n')
+			case 't':
+				u.write('\t')
+			default:
+				u.write(r)
+			}
+		case r == '\\':
+			u.escape = true
+		case r == '"':
+			u.quoted = !u.quoted
+		case !u.quoted && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			u.space = u.b.Len() > 0
+		default:
+			u.write(r)
+		}
+	}
+}
+
+// escapeUnicode reads a \uXXXX escape sequence.
+func (u *unescaper) escapeUnicode(r rune) {
+	u.unicode += string(r)
+	u.escape = false
+	if len(u.unicode) < len("uXXXX") {
+		return
+	}
+	n, err := strconv.ParseUint(u.unicode[1:], 16, 16)
+	if err != nil {
+		u.writeString(u.unicode)
+	} else {
+		u.write(rune(n))
+	}
+	u.unicode = ""
+}
+
+func (u *unescaper) write(r rune) {
+	u.writeString(string(r))
+}
+
+func (u *unescaper) writeString(s string) {
+	if u.space {
+		u.b.WriteByte(' ')
+		u.space = false
+	}
+	u.b.WriteString(s)
+}
+
+func (u *unescaper) String() string {
+	return u.b.String()
+}
+
+// Write writes the file in strings.xml format.
+func Write(w io.Writer, file *File) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n")
+	for _, resource := range file.Resources {
+		if resource.Comment != "" {
+			for _, line := range strings.Split(resource.Comment, "\n") {
+				// "--" is not allowed in comments.
+				line = strings.Replace(line, "--", "- -", -1)
+				fmt.Fprintf(&buf, "    <!-- %s -->\n", line)
+			}
+		}
+
+		name := attrEscaper.Replace(resource.Name)
+		switch resource.Kind {
+		case String:
+			fmt.Fprintf(&buf, "    <string name=\"%s\">%s</string>\n", name, Escape(resource.Value))
+		case Plurals:
+			fmt.Fprintf(&buf, "    <plurals name=\"%s\">\n", name)
+			for _, item := range resource.Items {
+				fmt.Fprintf(&buf, "        <item quantity=\"%s\">%s</item>\n", attrEscaper.Replace(item.Quantity), Escape(item.Value))
+			}
+			buf.WriteString("    </plurals>\n")
+		case StringArray:
+			fmt.Fprintf(&buf, "    <string-array name=\"%s\">\n", name)
+			for _, item := range resource.Items {
+				fmt.Fprintf(&buf, "        <item>%s</item>\n", Escape(item.Value))
+			}
+			buf.WriteString("    </string-array>\n")
+		}
+	}
+	buf.WriteString("</resources>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// tagRegexp matches the start and end tags of styling markup.
+var tagRegexp = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9_-]*(?:\s+[a-zA-Z_][a-zA-Z0-9_-]*="[^"<]*")*\s*/?>`)
+
+var (
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+	textEscaper = strings.NewReplacer(
+		"&", "&amp;", "<", "&lt;", ">", "&gt;",
+		`\`, `\\`, `"`, `\"`, "'", `\'`, "\n", `\n`, "\t", `\t`,
+	)
+)
+
+// Escape returns the text of a string as written in strings.xml, the reverse
+// of the unescaping of Parse. Styling markup is kept, and text with leading,
+// trailing or repeated whitespace is quoted.
+func Escape(value string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range tagRegexp.FindAllStringIndex(value, -1) {
+		b.WriteString(textEscaper.Replace(value[last:m[0]]))
+		b.WriteString(value[m[0]:m[1]])
+		last = m[1]
+	}
+	b.WriteString(textEscaper.Replace(value[last:]))
+	s := b.String()
+
+	// A leading @ or ? would make the string a reference.
+	if strings.HasPrefix(s, "@") || strings.HasPrefix(s, "?") {
+		s = `\` + s
+	}
+
+	if strings.TrimSpace(value) != value || strings.Contains(value, "  ") {
+		s = `"` + s + `"`
+	}
+	return s
+}
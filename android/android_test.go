@@ -0,0 +1,129 @@
+package android
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	input := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <!-- Shown on the home screen. -->
+    <string name="app_name">My App</string>
+    <string name="welcome">Welcome, <xliff:g id="name" example="Bob">%1$s</xliff:g>!</string>
+    <string name="escapes">Don\'t \"quote\" \\ me\nplease é</string>
+    <string name="whitespace">
+        Collapsed    and
+        trimmed
+    </string>
+    <string name="quoted">"  Kept  as is  "</string>
+    <string name="styled">Hello <b>bold</b> <a href="https://example.com">link</a></string>
+    <dimen name="margin">16dp</dimen>
+    <!-- Line 1 -->
+    <!-- Line 2 -->
+    <plurals name="songs">
+        <item quantity="one">%d song</item>
+        <item quantity="other">%d songs</item>
+    </plurals>
+    <string-array name="planets">
+        <item>Mercury</item>
+        <item>Venus</item>
+    </string-array>
+</resources>
+`)
+
+	file, err := Parse(input)
+	require.NoError(t, err)
+	require.Equal(t, &File{Resources: []Resource{
+		{Kind: String, Name: "app_name", Comment: "Shown on the home screen.", Value: "My App"},
+		{Kind: String, Name: "welcome", Value: "Welcome, %1$s!"},
+		{Kind: String, Name: "escapes", Value: "Don't \"quote\" \\ me\nplease é"},
+		{Kind: String, Name: "whitespace", Value: "Collapsed and trimmed"},
+		{Kind: String, Name: "quoted", Value: "  Kept  as is  "},
+		{Kind: String, Name: "styled", Value: `Hello <b>bold</b> <a href="https://example.com">link</a>`},
+		{Kind: Plurals, Name: "songs", Comment: "Line 1\nLine 2", Items: []Item{
+			{Quantity: "one", Value: "%d song"},
+			{Quantity: "other", Value: "%d songs"},
+		}},
+		{Kind: StringArray, Name: "planets", Items: []Item{{Value: "Mercury"}, {Value: "Venus"}}},
+	}}, file)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`<string name="a">A</string>`, "expected <resources>, got <string>"},
+		{`<resources><plurals name="p"><string>A</string></plurals></resources>`, "plurals p: unexpected <string>"},
+		{`<resources><string name="a">A</resources>`, "string a: XML syntax error on line 1: element <string> closed by </resources>"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.input))
+		require.EqualError(t, err, tt.expected, tt.input)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	file := &File{Resources: []Resource{
+		{Kind: String, Name: "app_name", Comment: "Line 1\nLine -- 2", Value: "My App"},
+		{Kind: Plurals, Name: "songs", Items: []Item{
+			{Quantity: "one", Value: "%d song"},
+			{Quantity: "other", Value: "%d songs"},
+		}},
+		{Kind: StringArray, Name: "planets", Items: []Item{{Value: "Mercury"}, {Value: "Venus"}}},
+	}}
+
+	expected := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- Line 1 -->
+    <!-- Line - - 2 -->
+    <string name="app_name">My App</string>
+    <plurals name="songs">
+        <item quantity="one">%d song</item>
+        <item quantity="other">%d songs</item>
+    </plurals>
+    <string-array name="planets">
+        <item>Mercury</item>
+        <item>Venus</item>
+    </string-array>
+</resources>
+`
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, file))
+	require.Equal(t, expected, buf.String())
+
+	parsed, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	file.Resources[0].Comment = "Line 1\nLine - - 2"
+	require.Equal(t, file, parsed)
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"Hello", "Hello"},
+		{"Don't \"quote\" \\ me\n\tplease", `Don\'t \"quote\" \\ me\n\tplease`},
+		{"a < b & c > d", "a &lt; b &amp; c &gt; d"},
+		{"Hello <b>bold</b> <a href=\"x\">link</a>", "Hello <b>bold</b> <a href=\"x\">link</a>"},
+		{"@string/name", `\@string/name`},
+		{"?attr", `\?attr`},
+		{" padded", `" padded"`},
+		{"double  space", `"double  space"`},
+	}
+
+	for _, tt := range tests {
+		actual := Escape(tt.value)
+		require.Equal(t, tt.expected, actual, tt.value)
+
+		file, err := Parse([]byte("<resources><string name=\"s\">" + actual + "</string></resources>"))
+		require.NoError(t, err)
+		require.Equal(t, tt.value, file.Resources[0].Value, tt.value)
+	}
+}
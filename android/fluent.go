@@ -0,0 +1,427 @@
+package android
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/ident"
+	"github.com/michalnicp/fluent-go/internal/printf"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Options configure the conversion between strings.xml files and Fluent.
+type Options struct {
+	// Variables maps the names of resources to the names of the variables
+	// of their positional placeholders, e.g. "welcome" to ["name", "count"]
+	// maps %1$s to $name and %2$d to $count.
+	//
+	// Without a mapping, ToFluent names placeholders $arg1, $arg2 and so on
+	// by their position, except for the first integer placeholder of plurals,
+	// which becomes $count, and FromFluent numbers variables in the order in
+	// which they first appear.
+	Variables map[string][]string
+}
+
+// CountVariable is the variable plurals select on if they have no integer
+// placeholder.
+const CountVariable = "count"
+
+// ToFluent converts a strings.xml file to a Fluent resource.
+//
+// Strings become messages, with the name of the resource as ID, and "."
+// replaced by "_". IDs which are already used get the lowest number appended
+// which makes them unique, e.g. "a_b-2" for a.b after a_b. Plurals become a select expression on the variable of
+// their first integer placeholder, with the other item as the default. String
+// arrays become messages with an attribute for each item, named item-0,
+// item-1 and so on. The XML comments before resources become the comments of
+// their messages.
+//
+// In strings with placeholders, %s becomes a variable, %d becomes
+// NUMBER($var) and %.2f becomes NUMBER($var, minimumFractionDigits: 2,
+// maximumFractionDigits: 2). Styling markup such as <b> is kept as text.
+//
+// Resources which cannot be converted are reported in the error, which joins
+// an error for each of them, and left out.
+func ToFluent(file *File, options Options) (syntax.Resource, error) {
+	resource := syntax.Resource{Body: make([]syntax.Entry, 0)}
+	var errs []error
+	used := make(map[string]bool)
+
+	for _, r := range file.Resources {
+		id := strings.Replace(r.Name, ".", "_", -1)
+		if !ident.Valid(id) {
+			errs = append(errs, fmt.Errorf("%s: name is not a valid Fluent identifier", r.Name))
+			continue
+		}
+		names := placeholderNames{variables: options.Variables[r.Name]}
+
+		message := syntax.Message{
+			ID:         syntax.Identifier{Name: id},
+			Attributes: make([]syntax.Attribute, 0),
+		}
+		switch r.Kind {
+		case String:
+			value := pattern(r.Value, names)
+			message.Value = &value
+		case Plurals:
+			if len(r.Items) == 0 {
+				continue
+			}
+			value := pluralsPattern(r.Items, names)
+			message.Value = &value
+		case StringArray:
+			if len(r.Items) == 0 {
+				continue
+			}
+			for i, item := range r.Items {
+				message.Attributes = append(message.Attributes, syntax.Attribute{
+					ID:    syntax.Identifier{Name: "item-" + strconv.Itoa(i)},
+					Value: pattern(item.Value, names),
+				})
+			}
+		}
+		if r.Comment != "" {
+			message.Comment = &syntax.Comment{Content: r.Comment}
+		}
+		message.ID.Name = ident.Unique(used, id)
+		resource.Body = append(resource.Body, message)
+	}
+
+	return resource, errors.Join(errs...)
+}
+
+// placeholderRegexp matches a placeholder of java.util.Formatter, with an
+// optional position as in %1$s, flags, width, precision and conversion.
+var placeholderRegexp = regexp.MustCompile(`%(?:(\d+)\$)?[-#+ 0,(]*(?:\d+)?(?:\.(\d+))?([a-zA-Z%])`)
+
+// placeholderNames names the variables of placeholders by their position.
+type placeholderNames struct {
+	variables []string
+
+	// count is the position of the placeholder named $count, or 0.
+	count int
+}
+
+func (n placeholderNames) name(position int) string {
+	switch {
+	case position <= len(n.variables):
+		return n.variables[position-1]
+	case position == n.count:
+		return CountVariable
+	default:
+		return "arg" + strconv.Itoa(position)
+	}
+}
+
+// placeholders returns the placeholders of text with their positions.
+func placeholders(text string) (matches [][]int, positions []int) {
+	next := 0
+	for _, m := range placeholderRegexp.FindAllStringSubmatchIndex(text, -1) {
+		position := 0
+		switch conversion := text[m[6]:m[7]]; {
+		case conversion == "%" || conversion == "n":
+		case m[2] >= 0:
+			position, _ = strconv.Atoi(text[m[2]:m[3]])
+		default:
+			next++
+			position = next
+		}
+		matches = append(matches, m)
+		positions = append(positions, position)
+	}
+	return matches, positions
+}
+
+// pattern converts text to a pattern. Text without placeholders, other than
+// %% and %n, is not formatted by Android, and kept as it is.
+func pattern(text string, names placeholderNames) syntax.Pattern {
+	matches, positions := placeholders(text)
+	formatted := false
+	for _, position := range positions {
+		formatted = formatted || position > 0
+	}
+	if !formatted {
+		return syntax.TextPattern(text)
+	}
+
+	var elements []syntax.PatternElement
+	appendText := func(s string) {
+		if s == "" {
+			return
+		}
+		if n := len(elements); n > 0 {
+			if text, ok := elements[n-1].(syntax.TextElement); ok {
+				elements[n-1] = syntax.TextElement{Value: text.Value + s}
+				return
+			}
+		}
+		elements = append(elements, syntax.TextElement{Value: s})
+	}
+
+	last := 0
+	for i, m := range matches {
+		appendText(text[last:m[0]])
+		last = m[1]
+
+		conversion := text[m[6]:m[7]]
+		switch conversion {
+		case "%":
+			appendText("%")
+			continue
+		case "n":
+			appendText("\n")
+			continue
+		}
+
+		variable := syntax.VariableReference{ID: syntax.Identifier{Name: names.name(positions[i])}}
+		var expr syntax.Expression = variable
+		switch {
+		case conversion == "d":
+			expr = printf.Number(variable)
+		case conversion == "f" && m[4] >= 0:
+			digits := syntax.NumberLiteral{Value: text[m[4]:m[5]]}
+			expr = printf.Number(variable,
+				syntax.NamedArgument{Name: syntax.Identifier{Name: "minimumFractionDigits"}, Value: digits},
+				syntax.NamedArgument{Name: syntax.Identifier{Name: "maximumFractionDigits"}, Value: digits},
+			)
+		case conversion == "f":
+			expr = printf.Number(variable)
+		}
+		elements = append(elements, syntax.Placeable{Expr: expr})
+	}
+	appendText(text[last:])
+
+	return syntax.Pattern{Elements: elements}
+}
+
+// pluralsPattern returns a select expression on the variable of the first
+// integer placeholder of the items, with a variant for each item.
+func pluralsPattern(items []Item, names placeholderNames) syntax.Pattern {
+	for _, item := range items {
+		matches, positions := placeholders(item.Value)
+		for i, m := range matches {
+			if item.Value[m[6]:m[7]] == "d" && names.count == 0 {
+				names.count = positions[i]
+			}
+		}
+	}
+	selector := CountVariable
+	if names.count > 0 {
+		selector = names.name(names.count)
+	}
+
+	hasOther := false
+	for _, item := range items {
+		hasOther = hasOther || item.Quantity == "other"
+	}
+
+	variants := make([]syntax.Variant, len(items))
+	for i, item := range items {
+		variants[i] = syntax.Variant{
+			Key:     syntax.Identifier{Name: item.Quantity},
+			Value:   pattern(item.Value, names),
+			Default: item.Quantity == "other" || !hasOther && i == len(items)-1,
+		}
+	}
+
+	return syntax.Pattern{Elements: []syntax.PatternElement{
+		syntax.Placeable{Expr: syntax.SelectExpression{
+			Selector: syntax.VariableReference{ID: syntax.Identifier{Name: selector}},
+			Variants: variants,
+		}},
+	}}
+}
+
+// FromFluent converts a Fluent resource to a strings.xml file, e.g. to
+// generate Android resources from the reference locale at build time.
+//
+// Messages become resources, with "-" in their IDs replaced by "_". Messages
+// whose value is a select expression on a number with plural categories as
+// keys become plurals, and messages without a value whose attributes are
+// named item-0, item-1 and so on become string arrays. Other attributes
+// become strings named after the message and the attribute, e.g.
+// "login_title" for login.title. Terms are not converted, but references to
+// terms and messages are replaced by their value, if it has no variables.
+//
+// Variables become positional placeholders, e.g. %1$s, and calls of NUMBER
+// become %1$d, or %1$.2f with two fraction digits.
+//
+// Messages which cannot be converted, e.g. because they use other functions
+// or select expressions which are not plurals, are reported in the error,
+// which joins an error for each of them, and left out.
+func FromFluent(resource syntax.Resource, options Options) (*File, error) {
+	file := &File{Resources: make([]Resource, 0)}
+	c := converter{printf.NewReferences(resource)}
+
+	var errs []error
+	names := make(map[string]string)
+	add := func(id string, r Resource) {
+		if other, ok := names[r.Name]; ok {
+			errs = append(errs, fmt.Errorf("%s: name %s is also used by %s", id, r.Name, other))
+			return
+		}
+		names[r.Name] = id
+		file.Resources = append(file.Resources, r)
+	}
+
+	for _, entry := range resource.Body {
+		message, ok := entry.(syntax.Message)
+		if !ok {
+			continue
+		}
+		id := message.ID.Name
+		name := strings.Replace(id, "-", "_", -1)
+		comment := ""
+		if message.Comment != nil {
+			comment = message.Comment.Content
+		}
+
+		resources, err := c.message(message, name, options.Variables[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", id, err))
+			continue
+		}
+		for i, r := range resources {
+			if i == 0 {
+				r.Comment = comment
+			}
+			add(id, r)
+		}
+	}
+
+	return file, errors.Join(errs...)
+}
+
+type converter struct {
+	refs *printf.References
+}
+
+// message converts a message to resources.
+func (c *converter) message(message syntax.Message, name string, variables []string) ([]Resource, error) {
+	var resources []Resource
+
+	if message.Value != nil {
+		r := Resource{Kind: String, Name: name}
+		f := c.formatter(variables)
+		if items, ok := pluralItems(*message.Value); ok {
+			r.Kind = Plurals
+			r.Items = make([]Item, len(items))
+			for i, item := range items {
+				r.Items[i] = Item{Quantity: item.Quantity, Value: format(f, item.pattern)}
+			}
+		} else {
+			r.Value = format(f, *message.Value)
+		}
+		if f.Err() != nil {
+			return nil, f.Err()
+		}
+		resources = append(resources, r)
+	}
+
+	if message.Value == nil && isArray(message.Attributes) {
+		r := Resource{Kind: StringArray, Name: name}
+		f := c.formatter(variables)
+		for _, attribute := range message.Attributes {
+			r.Items = append(r.Items, Item{Value: format(f, attribute.Value)})
+		}
+		if f.Err() != nil {
+			return nil, f.Err()
+		}
+		return append(resources, r), nil
+	}
+
+	for _, attribute := range message.Attributes {
+		attributeName := name + "_" + strings.Replace(attribute.ID.Name, "-", "_", -1)
+		f := c.formatter(nil)
+		r := Resource{Kind: String, Name: attributeName, Value: format(f, attribute.Value)}
+		if f.Err() != nil {
+			return nil, fmt.Errorf("attribute %s: %v", attribute.ID.Name, f.Err())
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// isArray reports whether attributes are named item-0, item-1 and so on.
+func isArray(attributes []syntax.Attribute) bool {
+	for i, attribute := range attributes {
+		if attribute.ID.Name != "item-"+strconv.Itoa(i) {
+			return false
+		}
+	}
+	return len(attributes) > 0
+}
+
+// quantities are the quantities of plurals.
+var quantities = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true, "other": true}
+
+type pluralItem struct {
+	Quantity string
+	pattern  syntax.Pattern
+}
+
+// pluralItems returns the items of the plurals of a pattern which is a select
+// expression on a number with plural categories as keys, and whether it is.
+// The default variant is also the other item, if there is none.
+func pluralItems(pattern syntax.Pattern) ([]pluralItem, bool) {
+	if len(pattern.Elements) != 1 {
+		return nil, false
+	}
+	placeable, ok := pattern.Elements[0].(syntax.Placeable)
+	if !ok {
+		return nil, false
+	}
+	expr, ok := placeable.Expr.(syntax.SelectExpression)
+	if !ok {
+		return nil, false
+	}
+	switch selector := expr.Selector.(type) {
+	case syntax.VariableReference:
+	case syntax.FunctionReference:
+		if selector.ID.Name != "NUMBER" || len(selector.Arguments.Named) > 0 {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	var (
+		items        []pluralItem
+		other        bool
+		defaultValue syntax.Pattern
+	)
+	for _, variant := range expr.Variants {
+		key, ok := variant.Key.(syntax.Identifier)
+		if !ok || !quantities[key.Name] {
+			return nil, false
+		}
+		other = other || key.Name == "other"
+		if variant.Default {
+			defaultValue = variant.Value
+		}
+		items = append(items, pluralItem{Quantity: key.Name, pattern: variant.Value})
+	}
+	if !other {
+		items = append(items, pluralItem{Quantity: "other", pattern: defaultValue})
+	}
+	return items, true
+}
+
+// formatter returns a formatter for the patterns of a resource, whose
+// placeholders share their positions.
+func (c *converter) formatter(variables []string) *printf.Formatter {
+	f := printf.NewFormatter(c.refs, variables)
+	f.Conversion = func(string) string { return "s" }
+	f.Integer = "d"
+	f.SelectError = "select expressions other than plurals cannot be converted"
+	return f
+}
+
+// format returns the text of a pattern. % is escaped if there are
+// placeholders.
+func format(f *printf.Formatter, pattern syntax.Pattern) string {
+	return printf.Join(f.Parts(pattern, 0))
+}
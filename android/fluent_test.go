@@ -0,0 +1,182 @@
+package android
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestToFluent(t *testing.T) {
+	file := &File{Resources: []Resource{
+		{Kind: String, Name: "app_name", Comment: "Shown on the home screen.", Value: "My App"},
+		{Kind: String, Name: "welcome", Value: "Welcome, %1$s! You have %2$d messages."},
+		{Kind: String, Name: "progress", Value: "%s: %.1f%% done%n"},
+		{Kind: String, Name: "percent", Value: "100%"},
+		{Kind: String, Name: "empty"},
+		{Kind: String, Name: "settings.title", Value: "Settings"},
+		{Kind: String, Name: "settings_title", Value: "Title"},
+		{Kind: Plurals, Name: "songs", Items: []Item{
+			{Quantity: "one", Value: "%d song by %2$s"},
+			{Quantity: "other", Value: "%1$d songs by %2$s"},
+		}},
+		{Kind: Plurals, Name: "no_placeholder", Items: []Item{
+			{Quantity: "one", Value: "One"},
+			{Quantity: "many", Value: "Many"},
+		}},
+		{Kind: StringArray, Name: "planets", Items: []Item{{Value: "Mercury"}, {Value: "Venus"}}},
+		{Kind: String, Name: "_hidden", Value: "Hidden"},
+	}}
+
+	resource, err := ToFluent(file, Options{Variables: map[string][]string{
+		"welcome": {"name", "count"},
+	}})
+	require.EqualError(t, err, "_hidden: name is not a valid Fluent identifier")
+
+	var buf bytes.Buffer
+	require.NoError(t, syntax.Fprint(&buf, resource))
+	require.Equal(t, `# Shown on the home screen.
+app_name = My App
+welcome = Welcome, { $name }! You have { NUMBER($count) } messages.
+progress =
+    { $arg1 }: { NUMBER($arg2, minimumFractionDigits: 1, maximumFractionDigits: 1) }% done{ "\u000A" }
+percent = 100%
+empty = { "" }
+settings_title = Settings
+settings_title-2 = Title
+songs =
+    { $count ->
+        [one] { NUMBER($count) } song by { $arg2 }
+       *[other] { NUMBER($count) } songs by { $arg2 }
+    }
+no_placeholder =
+    { $count ->
+        [one] One
+       *[many] Many
+    }
+planets =
+    .item-0 = Mercury
+    .item-1 = Venus
+`, buf.String())
+}
+
+func TestFromFluent(t *testing.T) {
+	resource, err := syntax.Parse([]byte(`-brand = Firefox
+# Shown on the home screen.
+app-name = { -brand } for Android
+welcome = Welcome, { $name }! You have { NUMBER($count) } messages at { $name }.
+progress = { NUMBER($done, minimumFractionDigits: 1, maximumFractionDigits: 1) }% done
+percent = 100%
+songs =
+    { $count ->
+        [one] { $count } song by { $artist }
+       *[other] { $count } songs by { $artist }
+    }
+emails =
+    { NUMBER($n) ->
+        [one] One email
+       *[many] { $n } emails
+    }
+planets =
+    .item-0 = Mercury
+    .item-1 = { app-name }
+login = Log in
+    .title = Login
+    .placeholder = { "" }
+login-button = { login.title }
+`))
+	require.NoError(t, err)
+
+	file, err := FromFluent(resource, Options{Variables: map[string][]string{
+		"songs": {"artist", "count"},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, &File{Resources: []Resource{
+		{Kind: String, Name: "app_name", Comment: "Shown on the home screen.", Value: "Firefox for Android"},
+		{Kind: String, Name: "welcome", Value: "Welcome, %1$s! You have %2$d messages at %1$s."},
+		{Kind: String, Name: "progress", Value: "%1$.1f%% done"},
+		{Kind: String, Name: "percent", Value: "100%"},
+		{Kind: Plurals, Name: "songs", Items: []Item{
+			{Quantity: "one", Value: "%2$s song by %1$s"},
+			{Quantity: "other", Value: "%2$s songs by %1$s"},
+		}},
+		{Kind: Plurals, Name: "emails", Items: []Item{
+			{Quantity: "one", Value: "One email"},
+			{Quantity: "many", Value: "%1$s emails"},
+			{Quantity: "other", Value: "%1$s emails"},
+		}},
+		{Kind: StringArray, Name: "planets", Items: []Item{{Value: "Mercury"}, {Value: "Firefox for Android"}}},
+		{Kind: String, Name: "login", Value: "Log in"},
+		{Kind: String, Name: "login_title", Value: "Login"},
+		{Kind: String, Name: "login_placeholder", Value: ""},
+		{Kind: String, Name: "login_button", Value: "Login"},
+	}}, file)
+}
+
+func TestFromFluentErrors(t *testing.T) {
+	resource, err := syntax.Parse([]byte(`ok = OK
+platform = { PLATFORM() }
+gender =
+    { $gender ->
+        [female] She
+       *[other] They
+    }
+exact =
+    { $n ->
+        [0] None
+       *[other] Some
+    }
+cyclic = { cyclic }
+unknown = { missing }
+with-variable = { welcome }
+welcome = Welcome, { $name }
+term = { -brand(case: "genitive") }
+digits = { NUMBER($n, maximumFractionDigits: 2) }
+ok_again = Duplicate
+ok-again = Duplicate
+`))
+	require.NoError(t, err)
+
+	file, err := FromFluent(resource, Options{})
+	require.EqualError(t, err, "platform: PLATFORM cannot be converted\n"+
+		"gender: select expressions other than plurals cannot be converted\n"+
+		"exact: select expressions other than plurals cannot be converted\n"+
+		"cyclic: reference cyclic is cyclic\n"+
+		"unknown: unknown reference missing\n"+
+		"with-variable: reference welcome has variables\n"+
+		"term: reference to -brand with arguments cannot be converted\n"+
+		"digits: options of NUMBER other than equal minimumFractionDigits and maximumFractionDigits cannot be converted\n"+
+		"ok-again: name ok_again is also used by ok_again")
+	require.Equal(t, []string{"ok", "welcome", "ok_again"}, names(file))
+}
+
+func names(file *File) []string {
+	var names []string
+	for _, r := range file.Resources {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+// TestRoundTrip checks that resources converted to Fluent and back are kept.
+func TestRoundTrip(t *testing.T) {
+	file := &File{Resources: []Resource{
+		{Kind: String, Name: "app_name", Comment: "The name.", Value: "My App"},
+		{Kind: String, Name: "welcome", Value: "Welcome, %1$s! You have %2$d messages."},
+		{Kind: String, Name: "progress", Value: "%1$.1f%% done"},
+		{Kind: String, Name: "styled", Value: "Hello <b>%1$s</b>"},
+		{Kind: Plurals, Name: "songs", Items: []Item{
+			{Quantity: "one", Value: "%1$d song"},
+			{Quantity: "other", Value: "%1$d songs"},
+		}},
+		{Kind: StringArray, Name: "planets", Items: []Item{{Value: "Mercury"}, {Value: "Venus"}}},
+	}}
+
+	resource, err := ToFluent(file, Options{})
+	require.NoError(t, err)
+	actual, err := FromFluent(resource, Options{})
+	require.NoError(t, err)
+	require.Equal(t, file, actual)
+}
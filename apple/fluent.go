@@ -0,0 +1,519 @@
+package apple
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/ident"
+	"github.com/michalnicp/fluent-go/internal/printf"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Options configure the conversion between Apple string resources and Fluent.
+type Options struct {
+	// Variables maps keys to the names of the variables of their arguments,
+	// e.g. "welcome" to ["name", "count"] maps %1$@ to $name and %2$d to
+	// $count.
+	//
+	// Without a mapping, ToFluent names plural variables, written as
+	// %#@songs@, like their variable in the .stringsdict file, and the other
+	// arguments $arg1, $arg2 and so on by their position, and FromFluent
+	// numbers variables in the order in which they first appear.
+	Variables map[string][]string
+}
+
+// ToFluent converts a .strings file and the .stringsdict file next to it to a
+// Fluent resource. Either may be nil.
+//
+// Entries become messages, with the key as ID if it is a valid Fluent
+// identifier, or an ID derived from it otherwise, e.g. "Open %@" becomes
+// "open". The comments of entries of the .strings file become the comments of
+// their messages.
+//
+// Entries of the .stringsdict file replace the entries of the .strings file
+// with the same key, like they do on Apple platforms. Their plural variables
+// become select expressions on the variable of their argument, with a variant
+// for each plural category, [0] for the zero form, which Apple uses for 0 in
+// all languages, and the other form as the default.
+//
+// In strings with placeholders, %@ becomes a variable, %d and %ld become
+// NUMBER($var) and %.2f becomes NUMBER($var, minimumFractionDigits: 2,
+// maximumFractionDigits: 2).
+func ToFluent(file *Strings, dict *Stringsdict, options Options) (syntax.Resource, error) {
+	resource := syntax.Resource{Body: make([]syntax.Entry, 0)}
+	if file == nil {
+		file = &Strings{}
+	}
+	if dict == nil {
+		dict = &Stringsdict{}
+	}
+
+	ids := make(map[string]string)
+	used := make(map[string]bool)
+	index := make(map[string]int)
+	id := func(key string) string {
+		if id, ok := ids[key]; ok {
+			return id
+		}
+		id := key
+		if !ident.Valid(key) {
+			id = ident.Slug(placeholderRegexp.ReplaceAllString(key, " "))
+		}
+		id = ident.Unique(used, id)
+		ids[key] = id
+		return id
+	}
+
+	for _, entry := range file.Entries {
+		names := argumentNames{variables: options.Variables[entry.Key]}
+		value := pattern(entry.Value, names, -1, nil)
+		message := syntax.Message{
+			ID:         syntax.Identifier{Name: id(entry.Key)},
+			Value:      &value,
+			Attributes: make([]syntax.Attribute, 0),
+		}
+		if entry.Comment != "" {
+			message.Comment = &syntax.Comment{Content: entry.Comment}
+		}
+		if i, ok := index[entry.Key]; ok {
+			resource.Body[i] = message
+			continue
+		}
+		index[entry.Key] = len(resource.Body)
+		resource.Body = append(resource.Body, message)
+	}
+
+	var errs []error
+	for _, entry := range dict.Entries {
+		value, err := pluralPattern(entry, options.Variables[entry.Key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", entry.Key, err))
+			continue
+		}
+
+		if i, ok := index[entry.Key]; ok {
+			message := resource.Body[i].(syntax.Message)
+			message.Value = &value
+			resource.Body[i] = message
+			continue
+		}
+		index[entry.Key] = len(resource.Body)
+		resource.Body = append(resource.Body, syntax.Message{
+			ID:         syntax.Identifier{Name: id(entry.Key)},
+			Value:      &value,
+			Attributes: make([]syntax.Attribute, 0),
+		})
+	}
+
+	return resource, errors.Join(errs...)
+}
+
+// placeholderRegexp matches a placeholder of a format string, with an
+// optional position as in %1$@, and a plural variable as in %#@songs@, or
+// flags, width, precision, length and conversion.
+var placeholderRegexp = regexp.MustCompile(`%(?:(\d+)\$)?(?:#@([^@]*)@|[-+ #0']*(?:\d+)?(?:\.(\d+))?(?:hh|h|ll|l|q|z|t|j|L)?([@dDiuUxXoOfFeEgGaAcCsSp%]))`)
+
+// argumentNames names the variables of arguments by their position.
+type argumentNames struct {
+	variables []string
+
+	// plurals are the names of the plural variables at their positions.
+	plurals map[int]string
+}
+
+func (n argumentNames) name(position int) string {
+	if position <= len(n.variables) {
+		return n.variables[position-1]
+	}
+	if name, ok := n.plurals[position]; ok && ident.Valid(name) {
+		return name
+	}
+	return "arg" + strconv.Itoa(position)
+}
+
+type placeholder struct {
+	start, end int
+
+	// position is the position of the argument, or 0 for %%.
+	position int
+
+	// conversion is the conversion, or "" for plural variables.
+	conversion string
+	plural     string
+	precision  string
+}
+
+// placeholders returns the placeholders of text. Placeholders without a
+// position take the next one, or, if the position of the argument of a plural
+// variable is given by variable, its position.
+func placeholders(text string, variable int) []placeholder {
+	var placeholders []placeholder
+	next := 0
+	for _, m := range placeholderRegexp.FindAllStringSubmatchIndex(text, -1) {
+		p := placeholder{start: m[0], end: m[1]}
+		if m[6] >= 0 {
+			p.precision = text[m[6]:m[7]]
+		}
+		switch {
+		case m[4] >= 0:
+			p.plural = text[m[4]:m[5]]
+		default:
+			p.conversion = text[m[8]:m[9]]
+		}
+
+		switch {
+		case p.conversion == "%":
+		case m[2] >= 0:
+			p.position, _ = strconv.Atoi(text[m[2]:m[3]])
+		case variable > 0:
+			p.position = variable
+		default:
+			next++
+			p.position = next
+		}
+		placeholders = append(placeholders, p)
+	}
+	return placeholders
+}
+
+// pattern converts text to a pattern. Text without placeholders, other than
+// %%, is not a format string, and kept as it is. In the forms of a plural
+// variable, variable is the position of its argument, and plurals are the
+// select expressions of the plural variables of the format.
+func pattern(text string, names argumentNames, variable int, plurals map[string]syntax.SelectExpression) syntax.Pattern {
+	placeholders := placeholders(text, variable)
+	formatted := variable > 0
+	for _, p := range placeholders {
+		formatted = formatted || p.position > 0
+	}
+	if !formatted {
+		return syntax.TextPattern(text)
+	}
+
+	var elements []syntax.PatternElement
+	appendText := func(s string) {
+		if s == "" {
+			return
+		}
+		if n := len(elements); n > 0 {
+			if text, ok := elements[n-1].(syntax.TextElement); ok {
+				elements[n-1] = syntax.TextElement{Value: text.Value + s}
+				return
+			}
+		}
+		elements = append(elements, syntax.TextElement{Value: s})
+	}
+
+	last := 0
+	for _, p := range placeholders {
+		appendText(text[last:p.start])
+		last = p.end
+
+		if p.conversion == "%" {
+			appendText("%")
+			continue
+		}
+		if expr, ok := plurals[p.plural]; ok && p.plural != "" {
+			elements = append(elements, syntax.Placeable{Expr: expr})
+			continue
+		}
+
+		variable := syntax.VariableReference{ID: syntax.Identifier{Name: names.name(p.position)}}
+		var expr syntax.Expression = variable
+		switch {
+		case strings.ContainsAny(p.conversion, "dDiuU"):
+			expr = printf.Number(variable)
+		case strings.ContainsAny(p.conversion, "fF") && p.precision != "":
+			digits := syntax.NumberLiteral{Value: p.precision}
+			expr = printf.Number(variable,
+				syntax.NamedArgument{Name: syntax.Identifier{Name: "minimumFractionDigits"}, Value: digits},
+				syntax.NamedArgument{Name: syntax.Identifier{Name: "maximumFractionDigits"}, Value: digits},
+			)
+		case strings.ContainsAny(p.conversion, "fFeEgG"):
+			expr = printf.Number(variable)
+		}
+		elements = append(elements, syntax.Placeable{Expr: expr})
+	}
+	appendText(text[last:])
+
+	if len(elements) == 0 {
+		return syntax.TextPattern("")
+	}
+	return syntax.Pattern{Elements: elements}
+}
+
+// pluralPattern converts the format of a .stringsdict entry to a pattern, with
+// its plural variables replaced by select expressions.
+func pluralPattern(entry Plural, variables []string) (syntax.Pattern, error) {
+	names := argumentNames{variables: variables, plurals: make(map[int]string)}
+	positions := make(map[string]int)
+	for _, p := range placeholders(entry.Format, -1) {
+		if p.plural != "" {
+			names.plurals[p.position] = p.plural
+			positions[p.plural] = p.position
+		}
+	}
+
+	plurals := make(map[string]syntax.SelectExpression)
+	for _, variable := range entry.Variables {
+		position, ok := positions[variable.Name]
+		if !ok {
+			continue
+		}
+		if len(variable.Forms) == 0 {
+			return syntax.Pattern{}, fmt.Errorf("variable %s has no forms", variable.Name)
+		}
+
+		hasOther := false
+		for _, form := range variable.Forms {
+			hasOther = hasOther || form.Category == "other"
+		}
+		expr := syntax.SelectExpression{
+			Selector: syntax.VariableReference{ID: syntax.Identifier{Name: names.name(position)}},
+		}
+		for i, form := range variable.Forms {
+			var key syntax.VariantKey = syntax.Identifier{Name: form.Category}
+			if form.Category == "zero" {
+				key = syntax.NumberLiteral{Value: "0"}
+			}
+			expr.Variants = append(expr.Variants, syntax.Variant{
+				Key:     key,
+				Value:   pattern(form.Value, names, position, nil),
+				Default: form.Category == "other" || !hasOther && i == len(variable.Forms)-1,
+			})
+		}
+		plurals[variable.Name] = expr
+	}
+
+	for name := range positions {
+		if _, ok := plurals[name]; !ok {
+			return syntax.Pattern{}, fmt.Errorf("missing variable %s", name)
+		}
+	}
+	return pattern(entry.Format, names, -1, plurals), nil
+}
+
+// FromFluent converts a Fluent resource to a .strings file and a .stringsdict
+// file, e.g. to generate the resources of an iOS app from the same files as
+// other platforms.
+//
+// Message values and attributes become entries, with the ID as key, e.g.
+// "login" and "login.title". Those with select expressions on a number with
+// plural categories as keys become entries of the .stringsdict file, with a
+// plural variable named like the variable for each select expression, and
+// the others entries of the .strings file. Terms are not converted, but
+// references to terms and messages are replaced by their value, if it has no
+// variables.
+//
+// Variables become positional placeholders, e.g. %1$@, and calls of NUMBER
+// and variables selected on become %1$ld, or %1$.2f with two fraction digits.
+//
+// Messages which cannot be converted, e.g. because they use other functions
+// or select expressions which are not plurals, are reported in the error,
+// which joins an error for each of them, and left out.
+func FromFluent(resource syntax.Resource, options Options) (*Strings, *Stringsdict, error) {
+	file := &Strings{Entries: make([]Entry, 0)}
+	dict := &Stringsdict{Entries: make([]Plural, 0)}
+	refs := printf.NewReferences(resource)
+
+	var errs []error
+	for _, entry := range resource.Body {
+		message, ok := entry.(syntax.Message)
+		if !ok {
+			continue
+		}
+		comment := ""
+		if message.Comment != nil {
+			comment = message.Comment.Content
+		}
+
+		type part struct {
+			key   string
+			value syntax.Pattern
+		}
+		var parts []part
+		if message.Value != nil {
+			parts = append(parts, part{message.ID.Name, *message.Value})
+		}
+		for _, attribute := range message.Attributes {
+			parts = append(parts, part{message.ID.Name + "." + attribute.ID.Name, attribute.Value})
+		}
+
+		var (
+			entries []Entry
+			plurals []Plural
+			err     error
+		)
+		for _, part := range parts {
+			f := newFormatter(refs, options.Variables[part.key])
+			format := f.format(part.value)
+			if len(f.plurals) == 0 {
+				entries = append(entries, Entry{Key: part.key, Value: format})
+			} else {
+				plurals = append(plurals, Plural{Key: part.key, Format: format, Variables: f.plurals})
+			}
+			if f.Err() != nil {
+				err = f.Err()
+				break
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", message.ID.Name, err))
+			continue
+		}
+		if len(entries) > 0 {
+			entries[0].Comment = comment
+		}
+		file.Entries = append(file.Entries, entries...)
+		dict.Entries = append(dict.Entries, plurals...)
+	}
+
+	return file, dict, errors.Join(errs...)
+}
+
+// formatter formats the pattern of an entry, whose placeholders share their
+// positions.
+type formatter struct {
+	*printf.Formatter
+
+	// plurals are the plural variables of the select expressions of the
+	// pattern.
+	plurals []Variable
+
+	// numbers are the variables selected on, which are numbers, and written
+	// like them.
+	numbers map[string]bool
+}
+
+func newFormatter(refs *printf.References, variables []string) *formatter {
+	f := &formatter{Formatter: printf.NewFormatter(refs, variables)}
+	f.Conversion = func(variable string) string {
+		if f.numbers[variable] {
+			return PluralValueType
+		}
+		return "@"
+	}
+	f.Integer = PluralValueType
+	f.SelectError = "nested select expressions cannot be converted"
+	return f
+}
+
+// PluralValueType is the value type of the plural variables written by
+// FromFluent, which matches Int in Swift.
+const PluralValueType = "ld"
+
+// format returns the format of a pattern. % is escaped if there are
+// placeholders.
+func (f *formatter) format(pattern syntax.Pattern) string {
+	f.numbers = make(map[string]bool)
+	for _, element := range pattern.Elements {
+		if placeable, ok := element.(syntax.Placeable); ok {
+			if expr, ok := placeable.Expr.(syntax.SelectExpression); ok {
+				if variable, ok := selectorVariable(expr); ok {
+					f.numbers[variable.ID.Name] = true
+				}
+			}
+		}
+	}
+
+	var parts []printf.Part
+	for _, element := range pattern.Elements {
+		switch element := element.(type) {
+		case syntax.TextElement:
+			parts = append(parts, printf.Part{Text: element.Value})
+		case syntax.Placeable:
+			if expr, ok := element.Expr.(syntax.SelectExpression); ok {
+				parts = append(parts, f.selectExpression(expr))
+			} else {
+				parts = append(parts, f.Expression(element.Expr, 0)...)
+			}
+		}
+	}
+	return printf.Join(parts)
+}
+
+// selectExpression adds the plural variable of a select expression, and
+// returns its placeholder.
+func (f *formatter) selectExpression(expr syntax.SelectExpression) printf.Part {
+	variable, ok := selectorVariable(expr)
+	if !ok {
+		f.Fail("select expressions other than plurals cannot be converted")
+		return printf.Part{}
+	}
+
+	name := variable.ID.Name
+	for n := 2; f.hasPlural(name); n++ {
+		name = variable.ID.Name + strconv.Itoa(n)
+	}
+	plural := Variable{Name: name, ValueType: PluralValueType}
+
+	forms := make(map[string]syntax.Pattern)
+	var defaultValue syntax.Pattern
+	for _, variant := range expr.Variants {
+		category := ""
+		switch key := variant.Key.(type) {
+		case syntax.Identifier:
+			category = key.Name
+		case syntax.NumberLiteral:
+			if key.Value == "0" {
+				category = "zero"
+			}
+		}
+		if _, ok := forms[category]; ok || !isCategory(category) {
+			f.Fail("select expressions other than plurals cannot be converted")
+			return printf.Part{}
+		}
+		forms[category] = variant.Value
+		if variant.Default {
+			defaultValue = variant.Value
+		}
+	}
+	if _, ok := forms["other"]; !ok {
+		forms["other"] = defaultValue
+	}
+
+	for _, category := range PluralCategories {
+		value, ok := forms[category]
+		if !ok {
+			continue
+		}
+		plural.Forms = append(plural.Forms, Form{Category: category, Value: printf.Join(f.Parts(value, 0))})
+	}
+	f.plurals = append(f.plurals, plural)
+
+	return printf.Part{Text: "%" + f.Position(variable.ID.Name) + "$#@" + name + "@", Placeholder: true}
+}
+
+// selectorVariable returns the variable a select expression selects on, if
+// it is a variable or a call of NUMBER without options.
+func selectorVariable(expr syntax.SelectExpression) (syntax.VariableReference, bool) {
+	switch selector := expr.Selector.(type) {
+	case syntax.VariableReference:
+		return selector, true
+	case syntax.FunctionReference:
+		variable, ok := printf.OnlyVariable(selector)
+		return variable, ok && selector.ID.Name == "NUMBER" && len(selector.Arguments.Named) == 0
+	}
+	return syntax.VariableReference{}, false
+}
+
+func (f *formatter) hasPlural(name string) bool {
+	for _, plural := range f.plurals {
+		if plural.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isCategory(category string) bool {
+	for _, c := range PluralCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
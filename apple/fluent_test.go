@@ -0,0 +1,215 @@
+package apple
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestToFluent(t *testing.T) {
+	file := &Strings{Entries: []Entry{
+		{Comment: "Shown on the home screen.", Key: "app_name", Value: "My App"},
+		{Key: "welcome", Value: "Welcome, %1$@! You have %2$ld messages."},
+		{Key: "Open %@ in %@", Value: "Öffne %2$@ in %1$@"},
+		{Key: "progress", Value: "%.1f%% done"},
+		{Key: "percent", Value: "100%"},
+		{Key: "empty", Value: ""},
+		{Comment: "Replaced by the plural.", Key: "songs", Value: "%d songs by %@"},
+	}}
+	dict := &Stringsdict{Entries: []Plural{
+		{
+			Key:    "songs",
+			Format: "%#@songs@ by %@",
+			Variables: []Variable{{Name: "songs", ValueType: "d", Forms: []Form{
+				{Category: "zero", Value: "No songs"},
+				{Category: "one", Value: "One song"},
+				{Category: "other", Value: "%d songs"},
+			}}},
+		},
+		{
+			Key:    "files",
+			Format: "%2$#@files@ in %1$#@folders@",
+			Variables: []Variable{
+				{Name: "folders", ValueType: "d", Forms: []Form{
+					{Category: "one", Value: "one folder"},
+					{Category: "many", Value: "%d folders"},
+				}},
+				{Name: "files", ValueType: "d", Forms: []Form{
+					{Category: "other", Value: "%d files"},
+				}},
+			},
+		},
+		{Key: "missing", Format: "%#@n@"},
+	}}
+
+	resource, err := ToFluent(file, dict, Options{Variables: map[string][]string{
+		"welcome": {"name", "count"},
+	}})
+	require.EqualError(t, err, "missing: missing variable n")
+
+	var buf bytes.Buffer
+	require.NoError(t, syntax.Fprint(&buf, resource))
+	require.Equal(t, `# Shown on the home screen.
+app_name = My App
+welcome = Welcome, { $name }! You have { NUMBER($count) } messages.
+open-in = Öffne { $arg2 } in { $arg1 }
+progress = { NUMBER($arg1, minimumFractionDigits: 1, maximumFractionDigits: 1) }% done
+percent = 100%
+empty = { "" }
+# Replaced by the plural.
+songs =
+    { $songs ->
+        [0] No songs
+        [one] One song
+       *[other] { NUMBER($songs) } songs
+    } by { $arg2 }
+files =
+    { $files ->
+       *[other] { NUMBER($files) } files
+    } in { $folders ->
+        [one] one folder
+       *[many] { NUMBER($folders) } folders
+    }
+`, buf.String())
+}
+
+func TestFromFluent(t *testing.T) {
+	resource, err := syntax.Parse([]byte(`-brand = Firefox
+# Shown on the home screen.
+app-name = { -brand } for iOS
+welcome = Welcome, { $name }! You have { NUMBER($count) } messages at { $name }.
+progress = { NUMBER($done, minimumFractionDigits: 1, maximumFractionDigits: 1) }% done
+percent = 100%
+songs =
+    { $count ->
+        [0] No songs
+        [one] One song by { $artist }
+       *[other] { $count } songs by { $artist }
+    } (100%)
+emails =
+    { NUMBER($n) ->
+        [one] One email
+       *[many] { $n } emails
+    } and { $n ->
+       *[other] { $n }
+    }
+login = Log in
+    .title = Login
+    .placeholder = Email
+login-button = { login.title }
+`))
+	require.NoError(t, err)
+
+	file, dict, err := FromFluent(resource, Options{Variables: map[string][]string{
+		"songs": {"artist", "count"},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, &Strings{Entries: []Entry{
+		{Comment: "Shown on the home screen.", Key: "app-name", Value: "Firefox for iOS"},
+		{Key: "welcome", Value: "Welcome, %1$@! You have %2$ld messages at %1$@."},
+		{Key: "progress", Value: "%1$.1f%% done"},
+		{Key: "percent", Value: "100%"},
+		{Key: "login", Value: "Log in"},
+		{Key: "login.title", Value: "Login"},
+		{Key: "login.placeholder", Value: "Email"},
+		{Key: "login-button", Value: "Login"},
+	}}, file)
+	require.Equal(t, &Stringsdict{Entries: []Plural{
+		{
+			Key:    "songs",
+			Format: "%2$#@count@ (100%%)",
+			Variables: []Variable{{Name: "count", ValueType: "ld", Forms: []Form{
+				{Category: "zero", Value: "No songs"},
+				{Category: "one", Value: "One song by %1$@"},
+				{Category: "other", Value: "%2$ld songs by %1$@"},
+			}}},
+		},
+		{
+			Key:    "emails",
+			Format: "%1$#@n@ and %1$#@n2@",
+			Variables: []Variable{
+				{Name: "n", ValueType: "ld", Forms: []Form{
+					{Category: "one", Value: "One email"},
+					{Category: "many", Value: "%1$ld emails"},
+					{Category: "other", Value: "%1$ld emails"},
+				}},
+				{Name: "n2", ValueType: "ld", Forms: []Form{
+					{Category: "other", Value: "%1$ld"},
+				}},
+			},
+		},
+	}}, dict)
+}
+
+func TestFromFluentErrors(t *testing.T) {
+	resource, err := syntax.Parse([]byte(`ok = OK
+platform = { PLATFORM() }
+gender =
+    { $gender ->
+        [female] She
+       *[other] They
+    }
+exact =
+    { $n ->
+        [1] One
+       *[other] Some
+    }
+nested =
+    { $n ->
+       *[other] { $m ->
+           *[other] Other
+        }
+    }
+cyclic = { cyclic }
+unknown = { missing }
+with-variable = { welcome }
+welcome = Welcome, { $name }
+term = { -brand(case: "genitive") }
+digits = { NUMBER($n, maximumFractionDigits: 2) }
+`))
+	require.NoError(t, err)
+
+	file, dict, err := FromFluent(resource, Options{})
+	require.EqualError(t, err, "platform: PLATFORM cannot be converted\n"+
+		"gender: select expressions other than plurals cannot be converted\n"+
+		"exact: select expressions other than plurals cannot be converted\n"+
+		"nested: nested select expressions cannot be converted\n"+
+		"cyclic: reference cyclic is cyclic\n"+
+		"unknown: unknown reference missing\n"+
+		"with-variable: reference welcome has variables\n"+
+		"term: reference to -brand with arguments cannot be converted\n"+
+		"digits: options of NUMBER other than equal minimumFractionDigits and maximumFractionDigits cannot be converted")
+	require.Equal(t, &Strings{Entries: []Entry{
+		{Key: "ok", Value: "OK"},
+		{Key: "welcome", Value: "Welcome, %1$@"},
+	}}, file)
+	require.Empty(t, dict.Entries)
+}
+
+// TestRoundTrip checks that resources converted to Fluent and back are kept.
+func TestRoundTrip(t *testing.T) {
+	file := &Strings{Entries: []Entry{
+		{Comment: "The name.", Key: "app_name", Value: "My App"},
+		{Key: "welcome", Value: "Welcome, %1$@! You have %2$ld messages."},
+		{Key: "progress", Value: "%1$.1f%% done"},
+	}}
+	dict := &Stringsdict{Entries: []Plural{{
+		Key:    "songs",
+		Format: "%1$#@songs@ by %2$@",
+		Variables: []Variable{{Name: "songs", ValueType: "ld", Forms: []Form{
+			{Category: "zero", Value: "No songs"},
+			{Category: "one", Value: "One song"},
+			{Category: "other", Value: "%1$ld songs"},
+		}}},
+	}}}
+
+	resource, err := ToFluent(file, dict, Options{})
+	require.NoError(t, err)
+	actualFile, actualDict, err := FromFluent(resource, Options{})
+	require.NoError(t, err)
+	require.Equal(t, file, actualFile)
+	require.Equal(t, dict, actualDict)
+}
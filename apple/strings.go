@@ -0,0 +1,295 @@
+// Package apple converts Apple string resources, Localizable.strings files and
+// .stringsdict plural dictionaries, to and from Fluent.
+package apple
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// A Strings is a parsed .strings file.
+type Strings struct {
+	Entries []Entry
+}
+
+// An Entry is a "key" = "value"; pair of a .strings file.
+type Entry struct {
+	// Comment is the text of the comments before the entry.
+	Comment string
+
+	Key   string
+	Value string
+}
+
+// ParseStrings parses a .strings file, in UTF-8 or, with a byte order mark,
+// UTF-16.
+func ParseStrings(data []byte) (*Strings, error) {
+	text, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := stringsParser{text: text, line: 1}
+	file := &Strings{Entries: make([]Entry, 0)}
+	for {
+		comment := p.skipSpace()
+		if p.pos == len(p.text) {
+			return file, nil
+		}
+
+		entry := Entry{Comment: comment}
+		entry.Key, err = p.string()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", p.line, err)
+		}
+		p.skipSpace()
+		if p.peek() == ';' {
+			// "key"; is short for "key" = "key";.
+			entry.Value = entry.Key
+		} else {
+			if err := p.expect('='); err != nil {
+				return nil, fmt.Errorf("line %d: %v", p.line, err)
+			}
+			p.skipSpace()
+			entry.Value, err = p.string()
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", p.line, err)
+			}
+			p.skipSpace()
+		}
+		if err := p.expect(';'); err != nil {
+			return nil, fmt.Errorf("line %d: %v", p.line, err)
+		}
+		file.Entries = append(file.Entries, entry)
+	}
+}
+
+// decode returns the text of a file in UTF-8, or UTF-16 with a byte order
+// mark, without the byte order mark.
+func decode(data []byte) (string, error) {
+	var order func([]byte) uint16
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		data = data[3:]
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		order = func(b []byte) uint16 { return uint16(b[0])<<8 | uint16(b[1]) }
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		order = func(b []byte) uint16 { return uint16(b[1])<<8 | uint16(b[0]) }
+	}
+	if order == nil {
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("invalid UTF-8")
+		}
+		return string(data), nil
+	}
+
+	data = data[2:]
+	if len(data)%2 != 0 {
+		return "", fmt.Errorf("invalid UTF-16")
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order(data[2*i:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+type stringsParser struct {
+	text string
+	pos  int
+	line int
+}
+
+func (p *stringsParser) peek() byte {
+	if p.pos == len(p.text) {
+		return 0
+	}
+	return p.text[p.pos]
+}
+
+func (p *stringsParser) expect(c byte) error {
+	if p.peek() != c {
+		return fmt.Errorf("expected %q, got %s", c, p.next())
+	}
+	p.pos++
+	return nil
+}
+
+// next describes the text at the position, for errors.
+func (p *stringsParser) next() string {
+	if p.pos == len(p.text) {
+		return "end of file"
+	}
+	r, _ := utf8.DecodeRuneInString(p.text[p.pos:])
+	return strconv.QuoteRune(r)
+}
+
+// skipSpace skips whitespace and comments, and returns the text of the
+// comments.
+func (p *stringsParser) skipSpace() string {
+	var comments []string
+	for p.pos < len(p.text) {
+		rest := p.text[p.pos:]
+		switch {
+		case rest[0] == '\n':
+			p.line++
+			p.pos++
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r':
+			p.pos++
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest) - 2
+			}
+			comment := rest[2 : 2+end]
+			comments = append(comments, strings.TrimSpace(comment))
+			p.line += strings.Count(comment, "\n")
+			p.pos += 2 + end + len("*/")
+			if p.pos > len(p.text) {
+				p.pos = len(p.text)
+			}
+		case strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			comments = append(comments, strings.TrimSpace(rest[2:end]))
+			p.pos += end
+		default:
+			return strings.Join(comments, "\n")
+		}
+	}
+	return strings.Join(comments, "\n")
+}
+
+// isUnquoted reports whether c may be part of an unquoted string.
+func isUnquoted(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("_$+/:.-", c) >= 0
+}
+
+// string reads a quoted or unquoted string.
+func (p *stringsParser) string() (string, error) {
+	if p.peek() != '"' {
+		start := p.pos
+		for p.pos < len(p.text) && isUnquoted(p.text[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start {
+			return "", fmt.Errorf("expected a string, got %s", p.next())
+		}
+		return p.text[start:p.pos], nil
+	}
+
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.pos == len(p.text) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := p.text[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\n':
+			p.line++
+			b.WriteByte(c)
+		case '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+// escape reads an escape sequence after the backslash.
+func (p *stringsParser) escape(b *strings.Builder) error {
+	if p.pos == len(p.text) {
+		return fmt.Errorf("unterminated string")
+	}
+	c := p.text[p.pos]
+	p.pos++
+	switch c {
+	case 'n':
+		b.WriteByte('\n')
+	case 't':
+		b.WriteByte('\t')
+	case 'r':
+		b.WriteByte('\r')
+	case 'a':
+		b.WriteByte('\a')
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'v':
+		b.WriteByte('\v')
+	case 'U', 'u':
+		r, ok := hex4(p.text[p.pos:])
+		if !ok {
+			return fmt.Errorf("invalid escape sequence \\%c", c)
+		}
+		p.pos += 4
+		// Characters outside the BMP are written as two escape sequences
+		// of a surrogate pair.
+		rest := p.text[p.pos:]
+		if utf16.IsSurrogate(r) && (strings.HasPrefix(rest, `\U`) || strings.HasPrefix(rest, `\u`)) {
+			if low, ok := hex4(rest[2:]); ok {
+				r = utf16.DecodeRune(r, low)
+				p.pos += 6
+			}
+		}
+		b.WriteRune(r)
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		start := p.pos - 1
+		for p.pos < len(p.text) && p.pos < start+3 && '0' <= p.text[p.pos] && p.text[p.pos] <= '7' {
+			p.pos++
+		}
+		n, _ := strconv.ParseUint(p.text[start:p.pos], 8, 8)
+		b.WriteRune(rune(n))
+	default:
+		b.WriteByte(c)
+	}
+	return nil
+}
+
+// hex4 returns the character of the 4 hex digits s starts with.
+func hex4(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s[:4], 16, 16)
+	return rune(n), err == nil
+}
+
+// WriteStrings writes the file in .strings format, in UTF-8.
+func WriteStrings(w io.Writer, file *Strings) error {
+	var buf bytes.Buffer
+	for i, entry := range file.Entries {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		if entry.Comment != "" {
+			// "*/" would end the comment.
+			fmt.Fprintf(&buf, "/* %s */\n", strings.Replace(entry.Comment, "*/", "* /", -1))
+		}
+		fmt.Fprintf(&buf, "%s = %s;\n", Quote(entry.Key), Quote(entry.Value))
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// Quote returns s as a quoted string of a .strings file.
+func Quote(s string) string {
+	return `"` + quoteEscaper.Replace(s) + `"`
+}
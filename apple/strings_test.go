@@ -0,0 +1,84 @@
+package apple
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStrings(t *testing.T) {
+	input := []byte(`/* Shown on the home screen. */
+"app_name" = "My App";
+
+// Line 1
+// Line 2
+"welcome" = "Welcome, %@!\nYou have \"%d\" messages.";
+unquoted = "Tab\tbackslash \\ \U00e9 😀 \101";
+"short";
+"multi
+line" /* inline */ = "value" ;
+`)
+
+	file, err := ParseStrings(input)
+	require.NoError(t, err)
+	require.Equal(t, &Strings{Entries: []Entry{
+		{Comment: "Shown on the home screen.", Key: "app_name", Value: "My App"},
+		{Comment: "Line 1\nLine 2", Key: "welcome", Value: "Welcome, %@!\nYou have \"%d\" messages."},
+		{Key: "unquoted", Value: "Tab\tbackslash \\ é 😀 A"},
+		{Key: "short", Value: "short"},
+		{Key: "multi\nline", Value: "value"},
+	}}, file)
+}
+
+func TestParseStringsUTF16(t *testing.T) {
+	// "a" = "é"; in UTF-16 with a little-endian byte order mark.
+	input := []byte{0xff, 0xfe}
+	for _, r := range `"a" = "é";` {
+		input = append(input, byte(r), byte(r>>8))
+	}
+
+	file, err := ParseStrings(input)
+	require.NoError(t, err)
+	require.Equal(t, &Strings{Entries: []Entry{{Key: "a", Value: "é"}}}, file)
+}
+
+func TestParseStringsErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a" "b";`, `line 1: expected '=', got '"'`},
+		{"\"a\" = \"b\"\n\"c\" = \"d\";", `line 2: expected ';', got '"'`},
+		{`"a" = "b`, "line 1: unterminated string"},
+		{`"a" = "\U00zz";`, `line 1: invalid escape sequence \U`},
+		{`"a" = ;`, `line 1: expected a string, got ';'`},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseStrings([]byte(tt.input))
+		require.EqualError(t, err, tt.expected, tt.input)
+	}
+}
+
+func TestWriteStrings(t *testing.T) {
+	file := &Strings{Entries: []Entry{
+		{Comment: "Shown on the home screen. */", Key: "app_name", Value: "My App"},
+		{Key: "welcome", Value: "Welcome, \"%1$@\"!\n\\"},
+	}}
+
+	expected := `/* Shown on the home screen. * / */
+"app_name" = "My App";
+
+"welcome" = "Welcome, \"%1$@\"!\n\\";
+`
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteStrings(&buf, file))
+	require.Equal(t, expected, buf.String())
+
+	parsed, err := ParseStrings(buf.Bytes())
+	require.NoError(t, err)
+	file.Entries[0].Comment = "Shown on the home screen. * /"
+	require.Equal(t, file, parsed)
+}
@@ -0,0 +1,249 @@
+package apple
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A Stringsdict is a parsed .stringsdict file.
+type Stringsdict struct {
+	Entries []Plural
+}
+
+// A Plural is an entry of a .stringsdict file.
+type Plural struct {
+	Key string
+
+	// Format is the NSStringLocalizedFormatKey, in which variables written
+	// as %#@name@ are replaced by the form of the variable selected by the
+	// plural category of their argument.
+	Format string
+
+	Variables []Variable
+}
+
+// A Variable is a plural variable of the format of an entry.
+type Variable struct {
+	Name string
+
+	// ValueType is the NSStringFormatValueTypeKey, the conversion of the
+	// argument, e.g. "d".
+	ValueType string
+
+	// Forms are the strings of the plural categories, in the order of
+	// PluralCategories.
+	Forms []Form
+}
+
+// A Form is the string of a plural category.
+type Form struct {
+	// Category is "zero", which is used for 0 in all languages, or a CLDR
+	// plural category.
+	Category string
+
+	Value string
+}
+
+// PluralCategories are the keys of the forms of variables, in the order in
+// which they are written.
+var PluralCategories = []string{"zero", "one", "two", "few", "many", "other"}
+
+const (
+	formatKey        = "NSStringLocalizedFormatKey"
+	specTypeKey      = "NSStringFormatSpecTypeKey"
+	valueTypeKey     = "NSStringFormatValueTypeKey"
+	pluralRuleType   = "NSStringPluralRuleType"
+	plistDoctype     = `<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">`
+	plistElementName = "plist"
+)
+
+// ParseStringsdict parses a .stringsdict file. Variables of other rule types
+// than NSStringPluralRuleType are reported as errors.
+func ParseStringsdict(data []byte) (*Stringsdict, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	root, err := readPlist(d)
+	if err != nil {
+		return nil, err
+	}
+	entries, ok := root.(dict)
+	if !ok {
+		return nil, fmt.Errorf("expected a dict")
+	}
+
+	file := &Stringsdict{Entries: make([]Plural, 0)}
+	for _, entry := range entries {
+		values, ok := entry.value.(dict)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a dict", entry.key)
+		}
+		plural := Plural{Key: entry.key}
+		for _, value := range values {
+			if value.key == formatKey {
+				if plural.Format, ok = value.value.(string); !ok {
+					return nil, fmt.Errorf("%s: expected a string as %s", entry.key, formatKey)
+				}
+				continue
+			}
+			variable, err := readVariable(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", entry.key, err)
+			}
+			plural.Variables = append(plural.Variables, variable)
+		}
+		file.Entries = append(file.Entries, plural)
+	}
+	return file, nil
+}
+
+func readVariable(entry dictEntry) (Variable, error) {
+	variable := Variable{Name: entry.key}
+	values, ok := entry.value.(dict)
+	if !ok {
+		return variable, fmt.Errorf("variable %s: expected a dict", entry.key)
+	}
+	if spec := values.get(specTypeKey); spec != pluralRuleType {
+		return variable, fmt.Errorf("variable %s: unsupported %s %q", entry.key, specTypeKey, spec)
+	}
+	variable.ValueType = values.get(valueTypeKey)
+	for _, category := range PluralCategories {
+		for _, value := range values {
+			if value.key != category {
+				continue
+			}
+			s, ok := value.value.(string)
+			if !ok {
+				return variable, fmt.Errorf("variable %s: expected a string as %s", entry.key, category)
+			}
+			variable.Forms = append(variable.Forms, Form{Category: category, Value: s})
+		}
+	}
+	return variable, nil
+}
+
+// dict is a plist dictionary, with the entries in order.
+type dict []dictEntry
+
+type dictEntry struct {
+	key   string
+	value interface{} // string or dict
+}
+
+// get returns the string value of a key, or "".
+func (d dict) get(key string) string {
+	for _, entry := range d {
+		if s, ok := entry.value.(string); ok && entry.key == key {
+			return s
+		}
+	}
+	return ""
+}
+
+// readPlist reads the value of a plist element.
+func readPlist(d *xml.Decoder) (interface{}, error) {
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("expected <%s>", plistElementName)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != plistElementName {
+				return nil, fmt.Errorf("expected <%s>, got <%s>", plistElementName, start.Name.Local)
+			}
+			value, end, err := readValue(d)
+			if err != nil {
+				return nil, err
+			}
+			if end {
+				return nil, fmt.Errorf("empty <%s>", plistElementName)
+			}
+			return value, nil
+		}
+	}
+}
+
+// readValue reads a <string> or <dict> value, or the end of the enclosing
+// element, reported by end. Values of other types are read as strings.
+func readValue(d *xml.Decoder) (value interface{}, end bool, err error) {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, false, err
+		}
+		switch token := token.(type) {
+		case xml.EndElement:
+			return nil, true, nil
+		case xml.StartElement:
+			if token.Name.Local != "dict" {
+				var s string
+				err := d.DecodeElement(&s, &token)
+				return s, false, err
+			}
+			var values dict
+			for {
+				key, end, err := readValue(d)
+				if err != nil {
+					return nil, false, err
+				}
+				if end {
+					return values, false, nil
+				}
+				k, ok := key.(string)
+				if !ok {
+					return nil, false, fmt.Errorf("expected <key>, got <dict>")
+				}
+				value, end, err := readValue(d)
+				if err != nil {
+					return nil, false, err
+				}
+				if end {
+					return nil, false, fmt.Errorf("missing value of key %s", k)
+				}
+				values = append(values, dictEntry{key: k, value: value})
+			}
+		}
+	}
+}
+
+// WriteStringsdict writes the file in .stringsdict format.
+func WriteStringsdict(w io.Writer, file *Stringsdict) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(xml.Header + plistDoctype + "\n<plist version=\"1.0\">\n<dict>\n")
+	for _, entry := range file.Entries {
+		writeElement(&buf, 1, "key", entry.Key)
+		buf.WriteString("\t<dict>\n")
+		writeElement(&buf, 2, "key", formatKey)
+		writeElement(&buf, 2, "string", entry.Format)
+		for _, variable := range entry.Variables {
+			writeElement(&buf, 2, "key", variable.Name)
+			buf.WriteString("\t\t<dict>\n")
+			writeElement(&buf, 3, "key", specTypeKey)
+			writeElement(&buf, 3, "string", pluralRuleType)
+			writeElement(&buf, 3, "key", valueTypeKey)
+			writeElement(&buf, 3, "string", variable.ValueType)
+			for _, form := range variable.Forms {
+				writeElement(&buf, 3, "key", form.Category)
+				writeElement(&buf, 3, "string", form.Value)
+			}
+			buf.WriteString("\t\t</dict>\n")
+		}
+		buf.WriteString("\t</dict>\n")
+	}
+	buf.WriteString("</dict>\n</plist>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeElement writes an element with text, indented by depth tabs.
+func writeElement(buf *bytes.Buffer, depth int, name, text string) {
+	buf.WriteString(strings.Repeat("\t", depth) + "<" + name + ">")
+	xml.EscapeText(buf, []byte(text))
+	buf.WriteString("</" + name + ">\n")
+}
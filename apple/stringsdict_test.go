@@ -0,0 +1,80 @@
+package apple
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var stringsdict = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>songs</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@songs@ by %@</string>
+		<key>songs</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>zero</key>
+			<string>No songs</string>
+			<key>one</key>
+			<string>One song</string>
+			<key>other</key>
+			<string>%d songs &amp; more</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`
+
+func TestParseStringsdict(t *testing.T) {
+	file, err := ParseStringsdict([]byte(stringsdict))
+	require.NoError(t, err)
+	require.Equal(t, &Stringsdict{Entries: []Plural{{
+		Key:    "songs",
+		Format: "%#@songs@ by %@",
+		Variables: []Variable{{
+			Name:      "songs",
+			ValueType: "d",
+			Forms: []Form{
+				{Category: "zero", Value: "No songs"},
+				{Category: "one", Value: "One song"},
+				{Category: "other", Value: "%d songs & more"},
+			},
+		}},
+	}}}, file)
+}
+
+func TestParseStringsdictErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`<dict></dict>`, "expected <plist>, got <dict>"},
+		{`<plist><string>a</string></plist>`, "expected a dict"},
+		{`<plist><dict><key>a</key><string>b</string></dict></plist>`, "a: expected a dict"},
+		{`<plist><dict><key>a</key><dict><key>v</key><dict><key>NSStringFormatSpecTypeKey</key><string>NSStringDeviceSpecificRuleType</string></dict></dict></dict></plist>`,
+			`a: variable v: unsupported NSStringFormatSpecTypeKey "NSStringDeviceSpecificRuleType"`},
+		{`<plist><dict><key>a</key></dict></plist>`, "missing value of key a"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseStringsdict([]byte(tt.input))
+		require.EqualError(t, err, tt.expected, tt.input)
+	}
+}
+
+func TestWriteStringsdict(t *testing.T) {
+	file, err := ParseStringsdict([]byte(stringsdict))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteStringsdict(&buf, file))
+	require.Equal(t, stringsdict, buf.String())
+}
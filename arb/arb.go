@@ -0,0 +1,130 @@
+// Package arb converts Flutter Application Resource Bundle (.arb) files to
+// Fluent.
+package arb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A File is a parsed .arb file.
+type File struct {
+	// Locale is the @@locale of the file, e.g. "en".
+	Locale string
+
+	Entries []Entry
+}
+
+// An Entry is a message of an .arb file, with its @key metadata.
+type Entry struct {
+	Key string
+
+	// Value is the message in ICU MessageFormat.
+	Value string
+
+	Description  string
+	Placeholders []Placeholder
+}
+
+// A Placeholder describes an argument of a message.
+type Placeholder struct {
+	Name string
+
+	// Type is the Dart type of the argument, e.g. "int" or "DateTime".
+	Type string
+
+	// Format is the name of the NumberFormat or DateFormat constructor which
+	// formats the argument, e.g. "compact" or "yMd".
+	Format string
+}
+
+type metadata struct {
+	Description  string `json:"description"`
+	Placeholders map[string]struct {
+		Type   string `json:"type"`
+		Format string `json:"format"`
+	} `json:"placeholders"`
+}
+
+// Parse parses an .arb file, in the order of the keys. Messages which are not
+// strings, and metadata which is not an object, are reported in the error,
+// which joins an error for each of them, and left out of the returned file.
+// The file is nil if the JSON is not a valid object.
+func Parse(data []byte) (*File, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("expected an object")
+	}
+
+	file := &File{Entries: make([]Entry, 0)}
+	metadatas := make(map[string]metadata)
+	var errs []error
+	for d.More() {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		key := token.(string)
+
+		var value json.RawMessage
+		if err := d.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case key == "@@locale":
+			if err := json.Unmarshal(value, &file.Locale); err != nil {
+				errs = append(errs, fmt.Errorf("%s: expected a string", key))
+			}
+		case strings.HasPrefix(key, "@@"):
+			// Other global metadata, e.g. @@last_modified.
+		case strings.HasPrefix(key, "@"):
+			var m metadata
+			if err := json.Unmarshal(value, &m); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid metadata: %v", key, err))
+				continue
+			}
+			metadatas[key[1:]] = m
+		default:
+			entry := Entry{Key: key}
+			if err := json.Unmarshal(value, &entry.Value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: expected a string, got %s", key, value))
+				continue
+			}
+			file.Entries = append(file.Entries, entry)
+		}
+	}
+	if _, err := d.Token(); err != nil {
+		return nil, err
+	}
+
+	// Metadata may be written before or after its message.
+	for i, entry := range file.Entries {
+		m, ok := metadatas[entry.Key]
+		if !ok {
+			continue
+		}
+		file.Entries[i].Description = m.Description
+		for name, placeholder := range m.Placeholders {
+			file.Entries[i].Placeholders = append(file.Entries[i].Placeholders, Placeholder{
+				Name:   name,
+				Type:   placeholder.Type,
+				Format: placeholder.Format,
+			})
+		}
+		sort.Slice(file.Entries[i].Placeholders, func(a, b int) bool {
+			return file.Entries[i].Placeholders[a].Name < file.Entries[i].Placeholders[b].Name
+		})
+	}
+
+	return file, errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package arb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	input := []byte(`{
+  "@@locale": "en",
+  "@@last_modified": "2023-01-01",
+  "@greeting": {
+    "description": "Greets the user",
+    "placeholders": {
+      "name": {"type": "String"},
+      "date": {"type": "DateTime", "format": "yMd"}
+    }
+  },
+  "greeting": "Hello, {name}! Today is {date}.",
+  "count": 1,
+  "title": "Title",
+  "@title": "Not an object",
+  "plain": "Plain"
+}`)
+
+	file, err := Parse(input)
+	require.EqualError(t, err, "count: expected a string, got 1\n@title: invalid metadata: json: cannot unmarshal string into Go value of type arb.metadata")
+	require.Equal(t, &File{
+		Locale: "en",
+		Entries: []Entry{
+			{
+				Key:         "greeting",
+				Value:       "Hello, {name}! Today is {date}.",
+				Description: "Greets the user",
+				Placeholders: []Placeholder{
+					{Name: "date", Type: "DateTime", Format: "yMd"},
+					{Name: "name", Type: "String"},
+				},
+			},
+			{Key: "title", Value: "Title"},
+			{Key: "plain", Value: "Plain"},
+		},
+	}, file)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`["a"]`, "expected an object"},
+		{`{"a": "b"`, "unexpected end of JSON input"},
+		{`{"a" "b"}`, "invalid character '\"' after object key"},
+	}
+
+	for _, tt := range tests {
+		file, err := Parse([]byte(tt.input))
+		require.EqualError(t, err, tt.expected, tt.input)
+		require.Nil(t, file)
+	}
+}
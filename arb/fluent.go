@@ -0,0 +1,140 @@
+package arb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/icu"
+	"github.com/michalnicp/fluent-go/internal/ident"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// ToFluent converts an .arb file to a Fluent resource.
+//
+// Messages become Fluent messages, with the key as ID, and the description of
+// their metadata as comment. Their ICU MessageFormat bodies are converted by
+// icu.ToFluent, e.g. {count, plural, one {# item} other {# items}} becomes a
+// select expression on $count.
+//
+// Placeholders of type DateTime become calls of DATETIME, with the dateStyle
+// of their format if it is one of yMd, yMMMd, yMMMMd and yMMMMEEEEd, and
+// placeholders of the types int, double and num with a format become calls of
+// NUMBER, with style "percent" for percentPattern.
+//
+// Messages which cannot be expressed in Fluent, e.g. with currency formats,
+// are reported in the error, which joins an error for each of them, and left
+// out.
+func ToFluent(file *File) (syntax.Resource, error) {
+	resource := syntax.Resource{Body: make([]syntax.Entry, 0)}
+
+	var errs []error
+	ids := make(map[string]bool)
+	for _, entry := range file.Entries {
+		value, err := pattern(entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", entry.Key, err))
+			continue
+		}
+
+		message := syntax.Message{
+			ID:         syntax.Identifier{Name: ident.Unique(ids, ident.FromKey(entry.Key))},
+			Value:      &value,
+			Attributes: make([]syntax.Attribute, 0),
+		}
+		if entry.Description != "" {
+			message.Comment = &syntax.Comment{Content: entry.Description}
+		}
+		resource.Body = append(resource.Body, message)
+	}
+
+	return resource, errors.Join(errs...)
+}
+
+// dateStyles are the dateStyle options of DATETIME for the formats of
+// DateTime placeholders.
+var dateStyles = map[string]string{
+	"yMd":        "short",
+	"yMMMd":      "medium",
+	"yMMMMd":     "long",
+	"yMMMMEEEEd": "full",
+}
+
+// pattern converts the value of an entry to a pattern.
+func pattern(entry Entry) (syntax.Pattern, error) {
+	if entry.Value == "" {
+		return syntax.TextPattern(""), nil
+	}
+	value, err := icu.ToFluent(entry.Value)
+	if err != nil {
+		return value, err
+	}
+
+	functions := make(map[string]syntax.FunctionReference)
+	for _, placeholder := range entry.Placeholders {
+		var options []syntax.NamedArgument
+		option := func(name, value string) {
+			options = append(options, syntax.NamedArgument{
+				Name:  syntax.Identifier{Name: name},
+				Value: syntax.StringLiteral{Value: value},
+			})
+		}
+
+		function := ""
+		switch {
+		case placeholder.Type == "DateTime":
+			function = "DATETIME"
+			if style, ok := dateStyles[placeholder.Format]; ok {
+				option("dateStyle", style)
+			}
+		case placeholder.Format == "" || placeholder.Type != "int" && placeholder.Type != "double" && placeholder.Type != "num":
+			continue
+		case strings.Contains(strings.ToLower(placeholder.Format), "currency"):
+			return value, fmt.Errorf("format %s of placeholder %s cannot be expressed in Fluent", placeholder.Format, placeholder.Name)
+		case placeholder.Format == "percentPattern":
+			function = "NUMBER"
+			option("style", "percent")
+		default:
+			function = "NUMBER"
+		}
+		functions[placeholder.Name] = syntax.FunctionReference{
+			ID: syntax.Identifier{Name: function},
+			Arguments: syntax.CallArguments{
+				Positional: []syntax.InlineExpression{syntax.VariableReference{ID: syntax.Identifier{Name: placeholder.Name}}},
+				Named:      options,
+			},
+		}
+	}
+	return withFunctions(value, functions), nil
+}
+
+// withFunctions returns a pattern with placeables of variables replaced by
+// the calls of functions for them.
+func withFunctions(pattern syntax.Pattern, functions map[string]syntax.FunctionReference) syntax.Pattern {
+	if len(functions) == 0 {
+		return pattern
+	}
+	elements := make([]syntax.PatternElement, len(pattern.Elements))
+	for i, element := range pattern.Elements {
+		elements[i] = element
+		placeable, ok := element.(syntax.Placeable)
+		if !ok {
+			continue
+		}
+		switch expr := placeable.Expr.(type) {
+		case syntax.VariableReference:
+			if function, ok := functions[expr.ID.Name]; ok {
+				elements[i] = syntax.Placeable{Expr: function}
+			}
+		case syntax.SelectExpression:
+			variants := make([]syntax.Variant, len(expr.Variants))
+			for j, variant := range expr.Variants {
+				variant.Value = withFunctions(variant.Value, functions)
+				variants[j] = variant
+			}
+			expr.Variants = variants
+			elements[i] = syntax.Placeable{Expr: expr}
+		}
+	}
+	return syntax.Pattern{Elements: elements}
+}
@@ -0,0 +1,68 @@
+package arb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestToFluent(t *testing.T) {
+	file := &File{
+		Locale: "en",
+		Entries: []Entry{
+			{
+				Key:         "greeting",
+				Value:       "Hello, {name}! Today is {date}.",
+				Description: "Greets the user",
+				Placeholders: []Placeholder{
+					{Name: "date", Type: "DateTime", Format: "yMd"},
+					{Name: "name", Type: "String"},
+				},
+			},
+			{
+				Key:   "items",
+				Value: "{count, plural, =0{No items} one{One item} other{{count} items}}",
+				Placeholders: []Placeholder{
+					{Name: "count", Type: "int", Format: "compact"},
+				},
+			},
+			{
+				Key:          "progress",
+				Value:        "{ratio} done",
+				Placeholders: []Placeholder{{Name: "ratio", Type: "double", Format: "percentPattern"}},
+			},
+			{
+				Key:          "price",
+				Value:        "{amount}",
+				Placeholders: []Placeholder{{Name: "amount", Type: "double", Format: "simpleCurrency"}},
+			},
+			{Key: "count", Value: "{n}", Placeholders: []Placeholder{{Name: "n", Type: "int"}}},
+			{Key: "broken", Value: "{a, plural, one {x}"},
+			{Key: "_private", Value: "Private"},
+			{Key: "empty", Value: ""},
+		},
+	}
+
+	resource, err := ToFluent(file)
+	require.EqualError(t, err, "price: format simpleCurrency of placeholder amount cannot be expressed in Fluent\n"+
+		"broken: offset 19: expected \"}\", got end of message")
+
+	var buf bytes.Buffer
+	require.NoError(t, syntax.Fprint(&buf, resource))
+	require.Equal(t, `# Greets the user
+greeting = Hello, { $name }! Today is { DATETIME($date, dateStyle: "short") }.
+items =
+    { $count ->
+        [0] No items
+        [one] One item
+       *[other] { NUMBER($count) } items
+    }
+progress = { NUMBER($ratio, style: "percent") } done
+count = { $n }
+msg-_private = Private
+empty = { "" }
+`, buf.String())
+}
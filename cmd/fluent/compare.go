@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/michalnicp/fluent-go/compare"
+)
+
+var compareUsage = `Usage: fluent compare [options] reference locale...
+
+Compares the .ftl files in the directories of locales with those in the
+reference directory, e.g. fluent compare l10n/en-US l10n/de l10n/fr. Files
+are matched by their path in the directory. Reports:
+
+  C0001  a file of the reference is missing,
+  C0002  a file is not in the reference,
+  C0003  a message of the reference is missing,
+  C0004  an attribute of a message in the reference is missing,
+  C0005  a term of the reference is missing (error),
+  C0006  a message or term is not in the reference,
+  C0007  an attribute is not in the reference,
+  C0008  a variable is used which the reference does not use (error).
+
+Prints a summary per locale. Exits with 1 if there are errors or a locale
+exceeds one of the thresholds.
+
+Options:
+  -format FORMAT        Write diagnostics as text, json, sarif or github
+                        workflow commands. Defaults to text.
+  -min-completion PCT   Fail if less than PCT percent of the messages of a
+                        locale are translated. Defaults to 0.
+  -max-missing N        Fail if a locale is missing more than N messages.
+                        Defaults to no limit.
+  -max-obsolete N       Fail if a locale has more than N messages which are not
+                        in the reference. Defaults to no limit.
+  -q, -quiet            Only print the summary.
+  -h, -help             Print this message and exit.`
+
+func runCompare(args []string) int {
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, compareUsage) }
+
+	var (
+		format        string
+		minCompletion float64
+		maxMissing    int
+		maxObsolete   int
+		quiet         bool
+		helpRequested bool
+	)
+
+	flags.StringVar(&format, "format", "text", "")
+	flags.Float64Var(&minCompletion, "min-completion", 0, "")
+	flags.IntVar(&maxMissing, "max-missing", -1, "")
+	flags.IntVar(&maxObsolete, "max-obsolete", -1, "")
+	flags.BoolVar(&quiet, "quiet", false, "")
+	flags.BoolVar(&quiet, "q", false, "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(compareUsage)
+		return 0
+	}
+
+	if flags.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, compareUsage)
+		return 2
+	}
+
+	var out io.Writer = os.Stdout
+	if quiet {
+		out = io.Discard
+	}
+	reporter, err := newReporter(format, out, newRenderer())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	code := 0
+	dirs := flags.Args()
+	locales := make([]compare.Locale, len(dirs))
+	for i, dir := range dirs {
+		var ok bool
+		locales[i], ok, err = readLocale(dir, reporter)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			reporter.Close()
+			return 1
+		}
+		if !ok {
+			code = 1
+		}
+	}
+
+	reference := locales[0]
+	var summaries []compare.Summary
+	for i, target := range locales[1:] {
+		problems, summary := compare.Compare(reference, target)
+		summaries = append(summaries, summary)
+
+		for _, p := range problems {
+			dir, locale := dirs[i+1], target
+			if p.Reference {
+				dir, locale = dirs[0], reference
+			}
+			if err := reporter.Report(filepath.Join(dir, filepath.FromSlash(p.Path)), fileInput(locale, p.Path), p.Diagnostic); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				reporter.Close()
+				return 1
+			}
+		}
+
+		if summary.Errors > 0 ||
+			summary.Completion() < minCompletion ||
+			maxMissing >= 0 && summary.Missing > maxMissing ||
+			maxObsolete >= 0 && summary.Obsolete > maxObsolete {
+			code = 1
+		}
+	}
+
+	if err := reporter.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// Keep machine readable output on stdout parseable.
+	var w io.Writer = os.Stdout
+	if format != "text" {
+		w = os.Stderr
+	}
+	if err := writeSummaries(w, summaries); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return code
+}
+
+// readLocale reads and parses the .ftl files in dir and its subdirectories,
+// reporting parse errors. The name of the locale is the name of the directory.
+func readLocale(dir string, reporter reporter) (compare.Locale, bool, error) {
+	locale := compare.Locale{Name: filepath.Base(filepath.Clean(dir))}
+
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".ftl") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return locale, false, err
+	}
+
+	sources, ok, err := parseFiles(files, reporter)
+	if err != nil {
+		return locale, false, err
+	}
+
+	for _, s := range sources {
+		rel, err := filepath.Rel(dir, s.file)
+		if err != nil {
+			return locale, false, err
+		}
+		locale.Files = append(locale.Files, compare.File{
+			Path:     filepath.ToSlash(rel),
+			Input:    s.input,
+			Resource: s.resource,
+		})
+	}
+	return locale, ok, nil
+}
+
+// fileInput returns the input of the file with the path in locale, or nil if
+// there is none.
+func fileInput(locale compare.Locale, path string) []byte {
+	for _, file := range locale.Files {
+		if file.Path == path {
+			return file.Input
+		}
+	}
+	return nil
+}
+
+func writeSummaries(w io.Writer, summaries []compare.Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Locale\tMessages\tTranslated\tMissing\tObsolete\tErrors\tWarnings\tComplete\t")
+	for _, s := range summaries {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%.1f%%\t\n",
+			s.Locale, s.Messages, s.Translated, s.Missing, s.Obsolete, s.Errors, s.Warnings, s.Completion())
+	}
+	return tw.Flush()
+}
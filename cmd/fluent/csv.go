@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/compare"
+	"github.com/michalnicp/fluent-go/spreadsheet"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+var csvUsage = `Usage: fluent csv export [options] reference [translation]...
+       fluent csv import file target...
+
+Converts locales to and from CSV spreadsheets, for review of translations in
+spreadsheet applications.
+
+export converts the .ftl files in the reference directory, e.g. l10n/en-US,
+and the translation directories, e.g. l10n/de, to a spreadsheet and prints it,
+or writes it to the file given with -o. Each message value and attribute
+becomes a row, with columns for the file, the ID, the comment and the pattern
+of each locale, named by its directory.
+
+import applies the edited cells of the spreadsheet to the .ftl files in the
+target directories, e.g. l10n/de, whose names are the names of columns, and
+which are created if they do not exist. Cells are parsed as Fluent patterns,
+and cells which are not valid patterns are reported and left out. Empty cells
+are not imported, and files are only written if they changed.
+
+Export options:
+  -o FILE     Write to FILE instead of stdout.
+
+Options:
+  -h, -help   Print this message and exit.`
+
+func runCSV(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "export":
+			return runCSVExport(args[1:])
+		case "import":
+			return runCSVImport(args[1:])
+		case "-h", "-help", "--help":
+			fmt.Println(csvUsage)
+			return 0
+		}
+	}
+	fmt.Fprintln(os.Stderr, csvUsage)
+	return 2
+}
+
+func runCSVExport(args []string) int {
+	flags := flag.NewFlagSet("csv export", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, csvUsage) }
+
+	var (
+		output        string
+		helpRequested bool
+	)
+
+	flags.StringVar(&output, "o", "", "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(csvUsage)
+		return 0
+	}
+
+	if flags.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, csvUsage)
+		return 2
+	}
+
+	reporter, err := newReporter("text", os.Stderr, syntax.Renderer{Context: 2})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	code := 0
+	var locales []compare.Locale
+	for _, dir := range flags.Args() {
+		locale, ok, err := readLocale(dir, reporter)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !ok {
+			code = 1
+		}
+		locales = append(locales, locale)
+	}
+
+	sheet := &spreadsheet.Sheet{}
+	for _, locale := range locales {
+		sheet.Locales = append(sheet.Locales, locale.Name)
+	}
+	for _, file := range locales[0].Files {
+		translations := make([]*syntax.Resource, len(locales)-1)
+		for i, locale := range locales[1:] {
+			for j, f := range locale.Files {
+				if f.Path == file.Path {
+					translations[i] = &locale.Files[j].Resource
+				}
+			}
+		}
+		sheet.Rows = append(sheet.Rows, spreadsheet.Export(file.Path, file.Resource, translations)...)
+	}
+
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, sheet); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = ioutil.WriteFile(output, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return code
+}
+
+func runCSVImport(args []string) int {
+	flags := flag.NewFlagSet("csv import", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, csvUsage) }
+
+	var helpRequested bool
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(csvUsage)
+		return 0
+	}
+
+	if flags.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, csvUsage)
+		return 2
+	}
+	file := flags.Arg(0)
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	sheet, err := spreadsheet.Parse(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		return 1
+	}
+
+	// paths are the files of the rows, in order.
+	var paths []string
+	seen := make(map[string]bool)
+	code := 0
+	for _, row := range sheet.Rows {
+		if seen[row.File] {
+			continue
+		}
+		seen[row.File] = true
+
+		// The file must be a relative path, which stays in the target
+		// directory.
+		p := path.Clean(row.File)
+		if row.File == "" || path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") || !strings.HasSuffix(p, ".ftl") {
+			fmt.Fprintf(os.Stderr, "%s: %s: invalid file %q\n", file, row.ID, row.File)
+			code = 1
+			continue
+		}
+		paths = append(paths, row.File)
+	}
+
+	reporter, err := newReporter("text", os.Stderr, syntax.Renderer{Context: 2})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	for _, target := range flags.Args()[1:] {
+		// A target which does not exist yet is created.
+		locale, ok := compare.Locale{Name: filepath.Base(filepath.Clean(target))}, true
+		if _, err := os.Stat(target); !os.IsNotExist(err) {
+			locale, ok, err = readLocale(target, reporter)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		if !ok {
+			code = 1
+		}
+
+		column := -1
+		for i, name := range sheet.Locales {
+			if name == locale.Name {
+				column = i
+			}
+		}
+		if column < 0 {
+			fmt.Fprintf(os.Stderr, "%s: no column for locale %s\n", file, locale.Name)
+			code = 1
+			continue
+		}
+
+		for _, p := range paths {
+			var resource syntax.Resource
+			for _, f := range locale.Files {
+				if f.Path == path.Clean(p) {
+					resource = f.Resource
+				}
+			}
+			var before bytes.Buffer
+			if err := syntax.Fprint(&before, resource); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+
+			resource, err := spreadsheet.Import(p, resource, sheet.Rows, column)
+			if err != nil {
+				for _, line := range strings.Split(err.Error(), "\n") {
+					fmt.Fprintf(os.Stderr, "%s: %s: %s: %s\n", file, locale.Name, p, line)
+				}
+				code = 1
+			}
+
+			var buf bytes.Buffer
+			if err := syntax.Fprint(&buf, resource); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			// Files without edits are not reformatted.
+			if bytes.Equal(buf.Bytes(), before.Bytes()) {
+				continue
+			}
+
+			name := filepath.Join(target, filepath.FromSlash(path.Clean(p)))
+			if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			if err := ioutil.WriteFile(name, buf.Bytes(), 0644); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+	}
+	return code
+}
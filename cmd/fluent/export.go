@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/android"
+	"github.com/michalnicp/fluent-go/apple"
+	"github.com/michalnicp/fluent-go/po"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+var exportUsage = `Usage: fluent export [options] file
+
+Converts a Fluent file of the reference locale to another localization format
+for translation and prints it, or writes it to the file given with -o. Use
+fluent import to convert the translated file back.
+
+Formats:
+  po    gettext .po files. Each message value and attribute becomes an entry
+        with the ID as msgctxt, and the pattern in Fluent syntax as msgid.
+  android
+        Android strings.xml files, e.g. to generate the resources of each
+        locale at build time. Messages selecting on a number by plural
+        category become plurals, messages with only the attributes item-0,
+        item-1 and so on become string arrays, and variables become
+        positional placeholders such as %1$s. Messages which cannot be
+        expressed are reported and left out.
+  apple
+        Apple Localizable.strings files, written to the file given with -o,
+        which is required. Message values and attributes become entries
+        with their ID as key, e.g. login.title. Those selecting on a number
+        by plural category are written to the .stringsdict file with the
+        same name instead. Variables become positional placeholders such
+        as %1$@.
+
+Options:
+  -to FORMAT          Write FORMAT. Defaults to po.
+  -o FILE             Write to FILE instead of stdout.
+  -translation FILE   Fill in the translations of the Fluent file FILE, e.g. to
+                      update a translated file after the reference changed.
+                      Only for po.
+  -lang LANG          The language of the translations, e.g. pt_BR.
+  -variables FILE     Number the placeholders of Android and Apple strings by
+                      the JSON object in FILE, which maps resource names or
+                      keys to lists of variable names, e.g.
+                      {"welcome": ["name", "count"]}.
+  -h, -help           Print this message and exit.`
+
+func runExport(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, exportUsage) }
+
+	var (
+		format        string
+		output        string
+		translation   string
+		language      string
+		variables     string
+		helpRequested bool
+	)
+
+	flags.StringVar(&format, "to", "po", "")
+	flags.StringVar(&output, "o", "", "")
+	flags.StringVar(&translation, "translation", "", "")
+	flags.StringVar(&language, "lang", "", "")
+	flags.StringVar(&variables, "variables", "", "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(exportUsage)
+		return 0
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, exportUsage)
+		return 2
+	}
+	switch {
+	case format != "po" && format != "android" && format != "apple":
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", format)
+		return 2
+	case format == "apple" && output == "":
+		fmt.Fprintln(os.Stderr, "-o is required for apple")
+		return 2
+	case format != "po" && translation != "":
+		fmt.Fprintf(os.Stderr, "-translation is not supported for %s\n", format)
+		return 2
+	}
+
+	names, err := readVariables(variables)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	reporter, err := newReporter("text", os.Stderr, syntax.Renderer{Context: 2})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	files := []string{flags.Arg(0)}
+	if translation != "" {
+		files = append(files, translation)
+	}
+	sources, ok, err := parseFiles(files, reporter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(sources) != len(files) {
+		return 1
+	}
+	code := 0
+	if !ok {
+		code = 1
+	}
+
+	var (
+		buf bytes.Buffer
+		// extra is another file to write, the .stringsdict file of apple.
+		extra     bytes.Buffer
+		extraFile string
+	)
+	switch format {
+	case "po":
+		var translated *syntax.Resource
+		if translation != "" {
+			translated = &sources[1].resource
+		}
+		file := po.Export(sources[0].resource, translated)
+		if language != "" {
+			file.Header = append(file.Header, po.Field{Name: "Language", Value: language})
+		}
+		err = po.Write(&buf, file)
+	case "android":
+		file, invalid := android.FromFluent(sources[0].resource, android.Options{Variables: names})
+		if invalid != nil {
+			reportInvalid(files[0], invalid)
+			code = 1
+		}
+		err = android.Write(&buf, file)
+	case "apple":
+		file, dict, invalid := apple.FromFluent(sources[0].resource, apple.Options{Variables: names})
+		if invalid != nil {
+			reportInvalid(files[0], invalid)
+			code = 1
+		}
+		err = apple.WriteStrings(&buf, file)
+		if err == nil && len(dict.Entries) > 0 {
+			extraFile = strings.TrimSuffix(output, filepath.Ext(output)) + ".stringsdict"
+			err = apple.WriteStringsdict(&extra, dict)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = ioutil.WriteFile(output, buf.Bytes(), 0644)
+	}
+	if err == nil && extraFile != "" {
+		err = ioutil.WriteFile(extraFile, extra.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return code
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/android"
+	"github.com/michalnicp/fluent-go/apple"
+	"github.com/michalnicp/fluent-go/arb"
+	"github.com/michalnicp/fluent-go/i18next"
+	"github.com/michalnicp/fluent-go/po"
+	"github.com/michalnicp/fluent-go/properties"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+var importUsage = `Usage: fluent import [options] file
+
+Converts a file of another localization format to Fluent and prints it, or
+writes it to the file given with -o. The format is chosen by the extension of
+the file, or with -from.
+
+Formats:
+  po    gettext .po and .pot files. Messages get IDs derived from their msgid,
+        plural forms select on $count, and printf placeholders become
+        variables. Files written by fluent export are converted back to
+        the exported messages instead, and their translations are checked
+        to be valid Fluent patterns.
+  android
+        Android strings.xml files. Strings become messages, plurals select
+        on the variable of their first integer placeholder or $count, and
+        string arrays become messages with the attributes item-0, item-1
+        and so on. Positional placeholders such as %1$s become variables
+        named by -variables, or $arg1, $arg2 and so on.
+  apple
+        Apple Localizable.strings and .stringsdict files. The .stringsdict
+        file next to a .strings file with the same name is read too, and
+        its entries replace those of the .strings file. Keys become IDs,
+        plural variables such as %#@songs@ become select expressions on
+        $songs, and placeholders such as %@ and %1$d become variables named
+        by -variables, or $arg1, $arg2 and so on.
+  properties
+        Java .properties files, in UTF-8 or ISO-8859-1. Keys become IDs,
+        comments become the comments of messages, and values with
+        MessageFormat placeholders such as {0} are converted with them
+        becoming $arg0, $arg1 and so on.
+  i18next
+        i18next JSON files. Nested keys become IDs joined by "-", strings with
+        plural suffixes such as _one and _other become a select expression
+        on $count, and {{name}} becomes $name.
+  arb   Flutter .arb files. The ICU MessageFormat bodies of messages are
+        converted, the descriptions of their @key metadata become comments,
+        and DateTime and formatted number placeholders become calls of
+        DATETIME and NUMBER.
+
+Options:
+  -from FORMAT    Read the file as FORMAT.
+  -o FILE         Write to FILE instead of stdout.
+  -lang LANG      The language of the translations, e.g. pt_BR. Defaults to
+                  the language given in the file.
+  -source         Convert the source strings instead of the translations, e.g.
+                  to create the reference locale from a .pot file.
+  -fuzzy          Include fuzzy translations of .po files.
+  -context        Derive IDs from msgctxt instead of msgid where present.
+                  Ignored for files written by fluent export.
+  -prefix PREFIX  Prepend PREFIX to all IDs.
+  -variables FILE Name the placeholders of Android and Apple strings by the
+                  JSON object in FILE, which maps resource names or keys to
+                  lists of variable names, e.g. {"welcome": ["name", "count"]}.
+  -h, -help       Print this message and exit.`
+
+// importFormats maps file extensions to formats.
+var importFormats = map[string]string{
+	".po":          "po",
+	".pot":         "po",
+	".xml":         "android",
+	".strings":     "apple",
+	".stringsdict": "apple",
+	".properties":  "properties",
+	".json":        "i18next",
+	".arb":         "arb",
+}
+
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, importUsage) }
+
+	var (
+		format        string
+		output        string
+		poOptions     po.Options
+		variables     string
+		helpRequested bool
+	)
+
+	flags.StringVar(&format, "from", "", "")
+	flags.StringVar(&output, "o", "", "")
+	flags.StringVar(&poOptions.Language, "lang", "", "")
+	flags.BoolVar(&poOptions.Source, "source", false, "")
+	flags.BoolVar(&poOptions.Fuzzy, "fuzzy", false, "")
+	flags.BoolVar(&poOptions.Context, "context", false, "")
+	flags.StringVar(&poOptions.Prefix, "prefix", "", "")
+	flags.StringVar(&variables, "variables", "", "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(importUsage)
+		return 0
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, importUsage)
+		return 2
+	}
+	file := flags.Arg(0)
+
+	if format == "" {
+		format = importFormats[strings.ToLower(filepath.Ext(file))]
+		if format == "" {
+			fmt.Fprintf(os.Stderr, "unknown format of %s, use -from\n", file)
+			return 2
+		}
+	}
+
+	names, err := readVariables(variables)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var (
+		resource syntax.Resource
+		// invalid reports translations which were left out, while the
+		// others are still written.
+		invalid error
+	)
+	switch format {
+	case "po":
+		var f *po.File
+		f, err = po.Parse(data)
+		switch {
+		case err != nil:
+		case f.HeaderField(po.SyntaxHeader) != "":
+			resource, invalid = po.Import(f, poOptions)
+		default:
+			resource, err = po.ToFluent(f, poOptions)
+		}
+	case "android":
+		var f *android.File
+		f, err = android.Parse(data)
+		if err == nil {
+			resource, invalid = android.ToFluent(f, android.Options{Variables: names})
+		}
+	case "apple":
+		resource, invalid, err = importApple(file, data, apple.Options{Variables: names})
+	case "properties":
+		var f *properties.File
+		f, err = properties.Parse(data)
+		if err == nil {
+			resource, invalid = properties.ToFluent(f)
+		}
+	case "i18next":
+		var f *i18next.File
+		f, err = i18next.Parse(data)
+		// The file is returned with errors of values which are not
+		// strings, which are reported with the others left out.
+		if f != nil {
+			var errs error
+			resource, errs = i18next.ToFluent(f)
+			invalid, err = errors.Join(err, errs), nil
+		}
+	case "arb":
+		var f *arb.File
+		f, err = arb.Parse(data)
+		if f != nil {
+			var errs error
+			resource, errs = arb.ToFluent(f)
+			invalid, err = errors.Join(err, errs), nil
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", format)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		return 1
+	}
+
+	var buf bytes.Buffer
+	if err := syntax.Fprint(&buf, resource); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = ioutil.WriteFile(output, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if invalid != nil {
+		reportInvalid(file, invalid)
+		return 1
+	}
+	return 0
+}
+
+// reportInvalid prints the errors joined in err, of messages which were left
+// out, prefixed by the file.
+func reportInvalid(file string, err error) {
+	for _, line := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", file, line)
+	}
+}
+
+// importApple converts a .strings file, with the .stringsdict file next to
+// it if there is one, or a .stringsdict file.
+func importApple(file string, data []byte, options apple.Options) (resource syntax.Resource, invalid, err error) {
+	var (
+		f    *apple.Strings
+		dict *apple.Stringsdict
+	)
+	if strings.ToLower(filepath.Ext(file)) == ".stringsdict" {
+		dict, err = apple.ParseStringsdict(data)
+	} else {
+		f, err = apple.ParseStrings(data)
+		if err != nil {
+			return resource, nil, err
+		}
+
+		dictFile := strings.TrimSuffix(file, filepath.Ext(file)) + ".stringsdict"
+		data, err = ioutil.ReadFile(dictFile)
+		switch {
+		case os.IsNotExist(err):
+			err = nil
+		case err == nil:
+			dict, err = apple.ParseStringsdict(data)
+			if err != nil {
+				err = fmt.Errorf("%s: %v", dictFile, err)
+			}
+		}
+	}
+	if err != nil {
+		return resource, nil, err
+	}
+
+	resource, invalid = apple.ToFluent(f, dict, options)
+	return resource, invalid, nil
+}
+
+// readVariables reads the names of the placeholders of Android strings from a
+// JSON file, if given.
+func readVariables(file string) (map[string][]string, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var variables map[string][]string
+	if err := json.Unmarshal(data, &variables); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return variables, nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+var jsonUsage = `Usage: fluent json [options] [file|-]
+
+Prints the syntax tree of a file as JSON, in the format of the reference
+implementation. Reads stdin if the file is - or missing. Parse errors are
+written to stderr and appear as Junk in the tree.
+
+Options:
+  -spans       Include the span of every node.
+  -compact     Print the tree on one line instead of indented.
+  -id IDS      Print only the messages and terms with the comma separated
+               IDs, e.g. -id hello,-brand. May be repeated.
+  -h, -help    Print this message and exit.`
+
+// idList is a flag value collecting comma separated IDs.
+type idList []string
+
+func (l *idList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *idList) Set(value string) error {
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			*l = append(*l, id)
+		}
+	}
+	return nil
+}
+
+func runJSON(args []string) int {
+	flags := flag.NewFlagSet("json", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, jsonUsage) }
+
+	var (
+		spans         bool
+		compact       bool
+		ids           idList
+		helpRequested bool
+	)
+
+	flags.BoolVar(&spans, "spans", false, "")
+	flags.BoolVar(&compact, "compact", false, "")
+	flags.Var(&ids, "id", "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(jsonUsage)
+		return 0
+	}
+
+	if flags.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, jsonUsage)
+		return 2
+	}
+
+	file := flags.Arg(0)
+	var input []byte
+	var err error
+	if file == "" || file == "-" {
+		file = "<stdin>"
+		input, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		input, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "read %s: %v\n", file, err)
+		return 1
+	}
+
+	code := 0
+	resource, err := syntax.Parse(input)
+	if err != nil {
+		code = 1
+		if perrs, ok := err.(*syntax.ParseErrors); ok {
+			reporter := &textReporter{
+				w:        os.Stderr,
+				renderer: syntax.Renderer{Color: isTerminal(os.Stderr) && os.Getenv("NO_COLOR") == ""},
+			}
+			for _, perr := range perrs.Errors() {
+				if err := reporter.Report(file, input, perr.Diagnostic()); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					return 1
+				}
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "parse %s: %v\n", file, err)
+		}
+	}
+
+	if len(ids) > 0 {
+		var missing []string
+		resource.Body, missing = filterEntries(resource.Body, ids)
+		for _, id := range missing {
+			fmt.Fprintf(os.Stderr, "%s: no message or term %q\n", file, id)
+			code = 1
+		}
+	}
+
+	if err := writeJSON(os.Stdout, resource, spans, compact); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return code
+}
+
+// filterEntries returns the messages and terms with the given IDs, in the
+// order of entries, and the IDs which were not found. Term IDs start with "-".
+func filterEntries(entries []syntax.Entry, ids []string) ([]syntax.Entry, []string) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = false
+	}
+
+	filtered := make([]syntax.Entry, 0)
+	for _, entry := range entries {
+		var id string
+		switch entry := entry.(type) {
+		case syntax.Message:
+			id = entry.ID.Name
+		case syntax.Term:
+			id = "-" + entry.ID.Name
+		default:
+			continue
+		}
+		if _, ok := wanted[id]; ok {
+			wanted[id] = true
+			filtered = append(filtered, entry)
+		}
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !wanted[id] {
+			missing = append(missing, id)
+			wanted[id] = true // report once
+		}
+	}
+	return filtered, missing
+}
+
+// writeJSON writes the resource as JSON followed by a newline.
+func writeJSON(w io.Writer, resource syntax.Resource, spans, compact bool) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(resource); err != nil {
+		return err
+	}
+
+	if !spans {
+		dec := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+		dec.UseNumber()
+		var stripped bytes.Buffer
+		if err := stripSpans(dec, &stripped); err != nil {
+			return err
+		}
+		stripped.WriteByte('\n')
+		buf = stripped
+	}
+
+	if !compact {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+			return err
+		}
+		buf = indented
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// stripSpans copies the next JSON value from dec to buf, leaving out the "span"
+// members of objects. Unlike decoding into a map, it keeps the order of
+// members, so that "type" stays first.
+func stripSpans(dec *json.Decoder, buf *bytes.Buffer) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		switch tok {
+		case '{':
+			buf.WriteByte('{')
+			first := true
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if key == "span" {
+					var skip json.RawMessage
+					if err := dec.Decode(&skip); err != nil {
+						return err
+					}
+					continue
+				}
+				if !first {
+					buf.WriteByte(',')
+				}
+				first = false
+				if err := writeJSONValue(buf, key); err != nil {
+					return err
+				}
+				buf.WriteByte(':')
+				if err := stripSpans(dec, buf); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte('}')
+		case '[':
+			buf.WriteByte('[')
+			for i := 0; dec.More(); i++ {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				if err := stripSpans(dec, buf); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte(']')
+		}
+		// Consume the closing delimiter.
+		_, err := dec.Token()
+		return err
+	default:
+		return writeJSONValue(buf, tok)
+	}
+}
+
+// writeJSONValue writes a scalar value without HTML escaping.
+func writeJSONValue(buf *bytes.Buffer, v interface{}) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1) // trailing newline
+	return nil
+}
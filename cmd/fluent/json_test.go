@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/michalnicp/fluent-go/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripSpans(t *testing.T) {
+	tests := []struct {
+		name, input, expected string
+	}{
+		{
+			name:     "scalar",
+			input:    `"<b>"`,
+			expected: `"<b>"`,
+		},
+		{
+			name:     "nested",
+			input:    `{"type":"Message","span":{"start":0,"end":9},"value":{"elements":[{"type":"Placeable","span":{"start":6,"end":9},"expression":{"span":[1,2],"value":1.50}}],"span":null},"attributes":[[{"span":1}],[]]}`,
+			expected: `{"type":"Message","value":{"elements":[{"type":"Placeable","expression":{"value":1.50}}]},"attributes":[[{}],[]]}`,
+		},
+		{
+			name:     "span values",
+			input:    `{"id":{"name":"span"},"comment":{"content":"span & <span>"}}`,
+			expected: `{"id":{"name":"span"},"comment":{"content":"span & <span>"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := json.NewDecoder(bytes.NewReader([]byte(tt.input)))
+			dec.UseNumber()
+			var buf bytes.Buffer
+			require.NoError(t, stripSpans(dec, &buf))
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestFilterEntries(t *testing.T) {
+	resource, err := syntax.Parse([]byte("# Comment\n\nhello = Hello\n-brand = Firefox\nbye = Bye\n"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		ids      []string
+		expected []string
+		missing  []string
+	}{
+		{
+			name:     "messages and terms",
+			ids:      []string{"bye", "-brand"},
+			expected: []string{"-brand", "bye"},
+		},
+		{
+			name:     "missing",
+			ids:      []string{"hello", "brand", "missing", "missing"},
+			expected: []string{"hello"},
+			missing:  []string{"brand", "missing"},
+		},
+		{
+			name:     "none",
+			ids:      []string{"-hello"},
+			expected: []string{},
+			missing:  []string{"-hello"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, missing := filterEntries(resource.Body, tt.ids)
+			ids := make([]string, 0)
+			for _, entry := range filtered {
+				switch entry := entry.(type) {
+				case syntax.Message:
+					ids = append(ids, entry.ID.Name)
+				case syntax.Term:
+					ids = append(ids, "-"+entry.ID.Name)
+				}
+			}
+			require.Equal(t, tt.expected, ids)
+			require.Equal(t, tt.missing, missing)
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	resource, err := syntax.Parse([]byte("a = <b>\n"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		spans, compact bool
+		expected       string
+	}{
+		{
+			name:    "compact",
+			compact: true,
+			expected: `{"type":"Resource","body":[{"type":"Message","id":{"type":"Identifier","name":"a"},"value":{"type":"Pattern","elements":[{"type":"TextElement","value":"<b>"}]},"attributes":[],"comment":null}]}
+`,
+		},
+		{
+			name:    "spans",
+			spans:   true,
+			compact: true,
+			expected: `{"type":"Resource","body":[{"type":"Message","id":{"type":"Identifier","name":"a","span":{"type":"Span","start":0,"end":1}},"value":{"type":"Pattern","elements":[{"type":"TextElement","value":"<b>","span":{"type":"Span","start":4,"end":7}}],"span":{"type":"Span","start":4,"end":7}},"attributes":[],"comment":null,"span":{"type":"Span","start":0,"end":7}}],"span":{"type":"Span","start":0,"end":8}}
+`,
+		},
+		{
+			name: "indented",
+			expected: `{
+  "type": "Resource",
+  "body": [
+    {
+      "type": "Message",
+      "id": {
+        "type": "Identifier",
+        "name": "a"
+      },
+      "value": {
+        "type": "Pattern",
+        "elements": [
+          {
+            "type": "TextElement",
+            "value": "<b>"
+          }
+        ]
+      },
+      "attributes": [],
+      "comment": null
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeJSON(&buf, resource, tt.spans, tt.compact))
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/lint"
+)
+
+var lintUsage = `Usage: fluent lint [options] [file]...
+
+Checks files for style problems. Rules are configured in the JSON file given
+with -config, or .fluentlint.json in the current directory if it exists:
+
+  {
+    "rules": {"max-length": false},
+    "idPattern": "^[a-z][a-z0-9]*(-[a-z0-9]+)*$",
+    "prefixes": {"browser/*.ftl": ["browser-"]},
+    "maxLength": 200
+  }
+
+Rules are disabled in a file with a comment, for the message or term it is
+attached to, or else for the rest of the file:
+
+  # fluent-lint-disable [rule]...
+
+Rules:
+%s
+Options:
+  -config FILE    Read the configuration from FILE.
+  -format FORMAT  Write diagnostics as text, json, sarif or github workflow
+                  commands. Defaults to text.
+  -h, -help       Print this message and exit.`
+
+func runLint(args []string) (code int) {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.Usage = func() { printLintUsage(os.Stderr) }
+
+	var (
+		configFile    string
+		format        string
+		helpRequested bool
+	)
+
+	flags.StringVar(&configFile, "config", "", "")
+	flags.StringVar(&format, "format", "text", "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		printLintUsage(os.Stdout)
+		return 0
+	}
+
+	if flags.NArg() == 0 {
+		printLintUsage(os.Stderr)
+		return 2
+	}
+
+	var config lint.Config
+	if configFile == "" {
+		if _, err := os.Stat(lint.ConfigFile); err == nil {
+			configFile = lint.ConfigFile
+		}
+	}
+	if configFile != "" {
+		var err error
+		config, err = lint.ReadConfig(configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+
+	linter, err := lint.New(config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", configFile, err)
+		return 2
+	}
+
+	reporter, err := newReporter(format, os.Stdout, newRenderer())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer func() {
+		if err := reporter.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+		}
+	}()
+
+	sources, ok, err := parseFiles(flags.Args(), reporter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !ok {
+		code = 1
+	}
+
+	for _, source := range sources {
+		diagnostics := linter.Lint(source.file, source.input, source.resource)
+		if len(diagnostics) > 0 {
+			code = 1
+		}
+		if err := source.report(reporter, diagnostics); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	return code
+}
+
+func printLintUsage(w io.Writer) {
+	var rules strings.Builder
+	for _, rule := range lint.Rules {
+		fmt.Fprintf(&rules, "  %-20s %s %s\n", rule.Name, rule.Code, rule.Description)
+	}
+	fmt.Fprintf(w, lintUsage+"\n", rules.String())
+}
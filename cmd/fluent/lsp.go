@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/michalnicp/fluent-go/lsp"
+)
+
+var lspUsage = `Usage: fluent lsp [options]
+
+Runs a Language Server Protocol server for .ftl files, which communicates with
+an editor over stdin and stdout.
+
+The server reports syntax errors as you type, shows the comment of a message
+and its value in the other locales on hover, goes to the definitions of and
+finds the references to messages and terms, completes message and term IDs,
+variables and functions, lists the messages and terms of a file as symbols, and
+formats files.
+
+Files are grouped into locales by the directory named after the locale, e.g.
+l10n/en-US/main.ftl and l10n/de/main.ftl, relative to the workspace root.
+
+Options:
+  -h, -help   Print this message and exit.`
+
+func runLSP(args []string) int {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, lspUsage) }
+
+	var helpRequested bool
+
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(lspUsage)
+		return 0
+	}
+
+	if flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, lspUsage)
+		return 2
+	}
+
+	server := &lsp.Server{Version: version}
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		if err != lsp.ErrNoShutdown {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return 1
+	}
+	return 0
+}
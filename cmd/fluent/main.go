@@ -12,20 +12,68 @@ import (
 var version = "0.0.0"
 
 var usage = `Usage: fluent [options] [file]...
+       fluent <command> [options] [argument]...
+
+Checks files for syntax errors, or runs a command.
+
+Commands:
+  compare    Compare the files of locales with those of a reference locale.
+  csv        Convert locales to and from CSV spreadsheets for review.
+  export     Convert a Fluent file to another localization format.
+  import     Convert a file of another localization format to Fluent.
+  json       Print the syntax tree of a file as JSON.
+  lint       Check files for style problems.
+  lsp        Run a language server for editors.
+  pseudo     Pseudolocalize the files of a locale.
+  rename     Rename a message, term or attribute in all locales.
+  tmx        Write a translation memory of locales as TMX 1.4.
+  xliff      Convert locales to and from XLIFF 2.0 documents.
 
 Options:
-  -h, -help      Print this message and exit.
-  -v, -version   Print the version and exit.`
+  -format FORMAT  Write diagnostics as text, json, sarif or github workflow
+                  commands. Defaults to text.
+  -validate       Also check the files together for duplicate definitions and
+                  references to undefined messages, terms and attributes.
+  -h, -help       Print this message and exit.
+  -v, -version    Print the version and exit.`
+
+// commands maps the names of subcommands to functions running them with the
+// arguments following the name. They return the exit code.
+var commands = map[string]func(args []string) int{
+	"compare": runCompare,
+	"csv":     runCSV,
+	"export":  runExport,
+	"import":  runImport,
+	"json":    runJSON,
+	"lint":    runLint,
+	"lsp":     runLSP,
+	"pseudo":  runPseudo,
+	"rename":  runRename,
+	"tmx":     runTMX,
+	"xliff":   runXLIFF,
+}
 
 func main() {
 	var code int
 	defer func() { os.Exit(code) }()
 
+	if len(os.Args) > 1 {
+		if run, ok := commands[os.Args[1]]; ok {
+			code = run(os.Args[2:])
+			return
+		}
+	}
+
 	var (
+		format           string
+		validate         bool
 		helpRequested    bool
 		versionRequested bool
 	)
 
+	flag.StringVar(&format, "format", "text", "")
+	flag.BoolVar(&validate, "validate", false, "")
+
 	flag.BoolVar(&helpRequested, "help", false, "")
 	flag.BoolVar(&helpRequested, "h", false, "")
 	flag.BoolVar(&versionRequested, "version", false, "")
@@ -48,16 +96,119 @@ func main() {
 		return
 	}
 
-	for _, file := range flag.Args() {
+	reporter, err := newReporter(format, os.Stdout, newRenderer())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = 2
+		return
+	}
+	defer func() {
+		if err := reporter.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+		}
+	}()
+
+	sources, ok, err := parseFiles(flag.Args(), reporter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = 1
+		return
+	}
+	if !ok {
+		code = 1
+	}
+
+	if validate {
+		resources := make([]syntax.Resource, len(sources))
+		for i, source := range sources {
+			resources[i] = source.resource
+		}
+		for i, diagnostics := range syntax.Validate(resources...) {
+			if len(diagnostics) > 0 {
+				code = 1
+			}
+			if err := sources[i].report(reporter, diagnostics); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				code = 1
+				return
+			}
+		}
+	}
+}
+
+// source is a parsed file.
+type source struct {
+	file     string
+	input    []byte
+	resource syntax.Resource
+}
+
+// parseFiles reads and parses files and reports their parse errors. It returns
+// the files which could be read, and whether all files were read and parsed
+// without errors. The error is from writing a report.
+func parseFiles(files []string, reporter reporter) ([]source, bool, error) {
+	var sources []source
+	ok := true
+
+	for _, file := range files {
 		input, err := ioutil.ReadFile(file)
 		if err != nil {
-			fmt.Printf("read %s: %v\n", file, err)
-			code = 1
+			fmt.Fprintf(os.Stderr, "read %s: %v\n", file, err)
+			ok = false
+			continue
 		}
 
-		if _, err := syntax.Parse(input); err != nil {
-			fmt.Printf("parse %s:\n%+v\n", file, err)
-			code = 1
+		s := source{file: file, input: input}
+		s.resource, err = syntax.Parse(input)
+		if err != nil {
+			ok = false
+			perrs, isParseErrors := err.(*syntax.ParseErrors)
+			if !isParseErrors {
+				fmt.Fprintf(os.Stderr, "parse %s: %v\n", file, err)
+				continue
+			}
+			diagnostics := make([]syntax.Diagnostic, len(perrs.Errors()))
+			for i, perr := range perrs.Errors() {
+				diagnostics[i] = perr.Diagnostic()
+			}
+			if err := s.report(reporter, diagnostics); err != nil {
+				return nil, false, err
+			}
 		}
+
+		sources = append(sources, s)
+	}
+
+	return sources, ok, nil
+}
+
+// report reports diagnostics in the source.
+func (s source) report(reporter reporter, diagnostics []syntax.Diagnostic) error {
+	for _, d := range diagnostics {
+		if err := reporter.Report(s.file, s.input, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newRenderer returns the renderer for text output, which is colored when
+// written to a terminal, unless disabled with the NO_COLOR environment
+// variable.
+func newRenderer() syntax.Renderer {
+	return syntax.Renderer{
+		Color:   isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "",
+		Context: 2,
+	}
+}
+
+// isTerminal reports whether f is a terminal, in which case output may be
+// colored.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
 	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/pseudo"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+var pseudoUsage = `Usage: fluent pseudo [options] source target
+
+Pseudolocalizes the .ftl files in the source directory, e.g. l10n/en-US, and
+writes them to the same paths in the target directory, e.g. l10n/qps-ploc.
+Only text is changed; IDs, variables, variant keys and literals are kept.
+
+Strategies:
+%s
+Options:
+  -strategy NAMES  Apply the comma separated strategies in order. Defaults to
+                   accented.
+  -h, -help        Print this message and exit.`
+
+func runPseudo(args []string) int {
+	flags := flag.NewFlagSet("pseudo", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, pseudoUsageText()) }
+
+	var (
+		names         string
+		helpRequested bool
+	)
+
+	flags.StringVar(&names, "strategy", pseudo.Accented.Name, "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(pseudoUsageText())
+		return 0
+	}
+
+	if flags.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, pseudoUsageText())
+		return 2
+	}
+	source, target := flags.Arg(0), flags.Arg(1)
+
+	var strategies []pseudo.Strategy
+	for _, name := range strings.Split(names, ",") {
+		strategy, ok := pseudo.Lookup(strings.TrimSpace(name))
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown strategy %q\n", name)
+			return 2
+		}
+		strategies = append(strategies, strategy)
+	}
+
+	reporter, err := newReporter("text", os.Stderr, syntax.Renderer{Context: 2})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	code := 0
+	locale, ok, err := readLocale(source, reporter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !ok {
+		code = 1
+	}
+
+	for _, file := range locale.Files {
+		var buf bytes.Buffer
+		if err := syntax.Fprint(&buf, pseudo.Transform(file.Resource, strategies...)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		path := filepath.Join(target, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return code
+}
+
+func pseudoUsageText() string {
+	var strategies strings.Builder
+	for _, strategy := range pseudo.Strategies {
+		fmt.Fprintf(&strategies, "  %-10s %s\n", strategy.Name, strategy.Description)
+	}
+	return fmt.Sprintf(pseudoUsage, strategies.String())
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/index"
+	"github.com/michalnicp/fluent-go/rename"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+var renameUsage = `Usage: fluent rename [options] from to path...
+
+Renames a message, term or attribute in the .ftl files of the paths, which are
+files or directories searched recursively, e.g. the directory of all locales,
+and updates every reference to it. Only the IDs are changed, the rest of the
+files is left as it is.
+
+from and to are written as in references, e.g. welcome, -brand or
+login.title. Messages stay messages, terms stay terms, and attributes stay on
+their message or term; renaming a message or term also updates the references
+to its attributes. Nothing is renamed if from is not defined, to is already
+defined in any file, or a file has syntax errors.
+
+Options:
+  -n          Print the files which would change instead of writing them.
+  -h, -help   Print this message and exit.`
+
+func runRename(args []string) int {
+	flags := flag.NewFlagSet("rename", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, renameUsage) }
+
+	var (
+		dryRun        bool
+		helpRequested bool
+	)
+
+	flags.BoolVar(&dryRun, "n", false, "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(renameUsage)
+		return 0
+	}
+
+	if flags.NArg() < 3 {
+		fmt.Fprintln(os.Stderr, renameUsage)
+		return 2
+	}
+
+	from, err := index.ParseSymbol(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	to, err := index.ParseSymbol(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// Paths may overlap, e.g. l10n and l10n/de, but each file is renamed
+	// once.
+	var files []string
+	seen := make(map[string]bool)
+	for _, root := range flags.Args()[2:] {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			path = filepath.Clean(path)
+			if !info.IsDir() && strings.HasSuffix(path, ".ftl") && !seen[path] {
+				seen[path] = true
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	reporter, err := newReporter("text", os.Stderr, syntax.Renderer{Context: 2})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// References in Junk would not be renamed.
+	sources, ok, err := parseFiles(files, reporter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !ok {
+		return 1
+	}
+
+	resources := make([]syntax.Resource, len(sources))
+	for i, s := range sources {
+		resources[i] = s.resource
+	}
+	edits, err := rename.Rename(resources, from, to)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	for i, s := range sources {
+		if edits[i] == nil {
+			continue
+		}
+		if dryRun {
+			fmt.Println(s.file)
+			continue
+		}
+		if err := ioutil.WriteFile(s.file, rename.Apply(s.input, edits[i]), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// A reporter writes the diagnostics found in files in one output format.
+type reporter interface {
+	// Report reports a diagnostic in file, which contains input.
+	Report(file string, input []byte, d syntax.Diagnostic) error
+
+	// Close writes any buffered output.
+	Close() error
+}
+
+// formats lists the values accepted by the -format flag.
+var formats = []string{"text", "json", "sarif", "github"}
+
+func newReporter(format string, w io.Writer, renderer syntax.Renderer) (reporter, error) {
+	switch format {
+	case "text":
+		return &textReporter{w: w, renderer: renderer}, nil
+	case "json":
+		return &jsonReporter{enc: json.NewEncoder(w)}, nil
+	case "sarif":
+		return &sarifReporter{w: w}, nil
+	case "github":
+		return &githubReporter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(formats, ", "))
+	}
+}
+
+// location is the position of a diagnostic in a file. Lines and columns start
+// at 1 and columns count runes.
+type location struct {
+	line, column       int
+	endLine, endColumn int
+}
+
+func locate(input []byte, span syntax.Span) location {
+	var loc location
+	loc.line, loc.column = syntax.Position(input, span.Start)
+	loc.endLine, loc.endColumn = syntax.Position(input, span.End)
+	return loc
+}
+
+// textReporter renders diagnostics for humans.
+type textReporter struct {
+	w        io.Writer
+	renderer syntax.Renderer
+}
+
+func (r *textReporter) Report(file string, input []byte, d syntax.Diagnostic) error {
+	if err := r.renderer.Render(r.w, file, input, d); err != nil {
+		return err
+	}
+	_, err := io.WriteString(r.w, "\n")
+	return err
+}
+
+func (r *textReporter) Close() error {
+	return nil
+}
+
+// jsonReporter writes one JSON object per diagnostic and line.
+type jsonReporter struct {
+	enc *json.Encoder
+}
+
+type jsonDiagnostic struct {
+	Severity  string `json:"severity"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Hint      string `json:"hint,omitempty"`
+}
+
+func (r *jsonReporter) Report(file string, input []byte, d syntax.Diagnostic) error {
+	loc := locate(input, d.Span)
+	return r.enc.Encode(jsonDiagnostic{
+		Severity:  d.Severity.String(),
+		File:      file,
+		Line:      loc.line,
+		Column:    loc.column,
+		EndLine:   loc.endLine,
+		EndColumn: loc.endColumn,
+		Code:      d.Code,
+		Message:   d.Message,
+		Hint:      d.Hint,
+	})
+}
+
+func (r *jsonReporter) Close() error {
+	return nil
+}
+
+// sarifReporter writes a SARIF 2.1.0 log, for code scanning dashboards. The
+// log is written as a whole on Close.
+type sarifReporter struct {
+	w       io.Writer
+	rules   []sarifRule
+	results []sarifResult
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID   string        `json:"id"`
+	Help *sarifMessage `json:"help,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+func (r *sarifReporter) Report(file string, input []byte, d syntax.Diagnostic) error {
+	if d.Code != "" && !r.hasRule(d.Code) {
+		rule := sarifRule{ID: d.Code}
+		if d.Hint != "" {
+			rule.Help = &sarifMessage{Text: d.Hint}
+		}
+		r.rules = append(r.rules, rule)
+	}
+
+	loc := locate(input, d.Span)
+	r.results = append(r.results, sarifResult{
+		RuleID:  d.Code,
+		Level:   d.Severity.String(),
+		Message: sarifMessage{Text: d.Message},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepathToURI(file)},
+				Region: sarifRegion{
+					StartLine:   loc.line,
+					StartColumn: loc.column,
+					EndLine:     loc.endLine,
+					EndColumn:   loc.endColumn,
+				},
+			},
+		}},
+	})
+	return nil
+}
+
+func (r *sarifReporter) hasRule(id string) bool {
+	for _, rule := range r.rules {
+		if rule.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *sarifReporter) Close() error {
+	rules := r.rules
+	if rules == nil {
+		rules = make([]sarifRule, 0)
+	}
+	results := r.results
+	if results == nil {
+		results = make([]sarifResult, 0)
+	}
+
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "fluent",
+				Version:        version,
+				InformationURI: "https://github.com/michalnicp/fluent-go",
+				Rules:          rules,
+			}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// filepathToURI returns a URI reference for a file path: a file URI for an
+// absolute path and a relative reference otherwise.
+func filepathToURI(file string) string {
+	u := url.URL{Path: filepath.ToSlash(file)}
+	if filepath.IsAbs(file) {
+		u.Scheme = "file"
+		if !strings.HasPrefix(u.Path, "/") {
+			u.Path = "/" + u.Path // Windows drive letter
+		}
+	}
+	return u.String()
+}
+
+// githubReporter writes workflow commands which GitHub Actions shows as
+// annotations on the changed files.
+type githubReporter struct {
+	w io.Writer
+}
+
+func (r *githubReporter) Report(file string, input []byte, d syntax.Diagnostic) error {
+	loc := locate(input, d.Span)
+	properties := fmt.Sprintf("file=%s,line=%d,col=%d,endLine=%d,endColumn=%d",
+		escapeProperty(file), loc.line, loc.column, loc.endLine, loc.endColumn)
+	if d.Code != "" {
+		properties += ",title=" + escapeProperty(d.Code)
+	}
+	message := d.Message
+	if d.Hint != "" {
+		message += "\n" + d.Hint
+	}
+	_, err := fmt.Fprintf(r.w, "::%s %s::%s\n", d.Severity, properties, escapeData(message))
+	return err
+}
+
+func (r *githubReporter) Close() error {
+	return nil
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/michalnicp/fluent-go/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporters(t *testing.T) {
+	input := []byte("hello = Hello\nbye Bye\nemail = { $n ->\n    [one] ✉\n}\n")
+	diagnostics := []syntax.Diagnostic{
+		{
+			Code:    "E0003",
+			Message: `Expected token: "="`,
+			Span:    syntax.Span{Start: 18, End: 18},
+		},
+		{
+			Severity: syntax.SeverityWarning,
+			Code:     "L0001",
+			Message:  "100% of 1,2: unused",
+			Span:     syntax.Span{Start: 42, End: 51},
+			Hint:     "Remove it.",
+		},
+		{
+			Code:    "E0010",
+			Message: "Expected one of the variants to be marked as default (*)",
+			Span:    syntax.Span{Start: 42, End: 51},
+		},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "text",
+			expected: `error[E0003]: Expected token: "="
+ --> dir/a,b.ftl:2:5
+  |
+1 | hello = Hello
+2 | bye Bye
+  |     ^
+3 | email = { $n ->
+
+warning[L0001]: 100% of 1,2: unused
+ --> dir/a,b.ftl:4:5
+  |
+3 | email = { $n ->
+4 |     [one] ✉
+  |     ^^^^^^^
+5 | }
+  |
+  = hint: Remove it.
+
+error[E0010]: Expected one of the variants to be marked as default (*)
+ --> dir/a,b.ftl:4:5
+  |
+3 | email = { $n ->
+4 |     [one] ✉
+  |     ^^^^^^^
+5 | }
+
+`,
+		},
+		{
+			format: "json",
+			expected: `{"severity":"error","file":"dir/a,b.ftl","line":2,"column":5,"endLine":2,"endColumn":5,"code":"E0003","message":"Expected token: \"=\""}
+{"severity":"warning","file":"dir/a,b.ftl","line":4,"column":5,"endLine":4,"endColumn":12,"code":"L0001","message":"100% of 1,2: unused","hint":"Remove it."}
+{"severity":"error","file":"dir/a,b.ftl","line":4,"column":5,"endLine":4,"endColumn":12,"code":"E0010","message":"Expected one of the variants to be marked as default (*)"}
+`,
+		},
+		{
+			format: "sarif",
+			expected: `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "fluent",
+          "version": "0.0.0",
+          "informationUri": "https://github.com/michalnicp/fluent-go",
+          "rules": [
+            {
+              "id": "E0003"
+            },
+            {
+              "id": "L0001",
+              "help": {
+                "text": "Remove it."
+              }
+            },
+            {
+              "id": "E0010"
+            }
+          ]
+        }
+      },
+      "columnKind": "unicodeCodePoints",
+      "results": [
+        {
+          "ruleId": "E0003",
+          "level": "error",
+          "message": {
+            "text": "Expected token: \"=\""
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "dir/a,b.ftl"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 5,
+                  "endLine": 2,
+                  "endColumn": 5
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "L0001",
+          "level": "warning",
+          "message": {
+            "text": "100% of 1,2: unused"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "dir/a,b.ftl"
+                },
+                "region": {
+                  "startLine": 4,
+                  "startColumn": 5,
+                  "endLine": 4,
+                  "endColumn": 12
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "E0010",
+          "level": "error",
+          "message": {
+            "text": "Expected one of the variants to be marked as default (*)"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "dir/a,b.ftl"
+                },
+                "region": {
+                  "startLine": 4,
+                  "startColumn": 5,
+                  "endLine": 4,
+                  "endColumn": 12
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			format: "github",
+			expected: `::error file=dir/a%2Cb.ftl,line=2,col=5,endLine=2,endColumn=5,title=E0003::Expected token: "="
+::warning file=dir/a%2Cb.ftl,line=4,col=5,endLine=4,endColumn=12,title=L0001::100%25 of 1,2: unused%0ARemove it.
+::error file=dir/a%2Cb.ftl,line=4,col=5,endLine=4,endColumn=12,title=E0010::Expected one of the variants to be marked as default (*)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			reporter, err := newReporter(tt.format, &buf, syntax.Renderer{Context: 1})
+			require.NoError(t, err)
+
+			for _, d := range diagnostics {
+				require.NoError(t, reporter.Report("dir/a,b.ftl", input, d))
+			}
+			require.NoError(t, reporter.Close())
+
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestReportersEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	reporter, err := newReporter("sarif", &buf, syntax.Renderer{})
+	require.NoError(t, err)
+	require.NoError(t, reporter.Close())
+	require.Contains(t, buf.String(), `"rules": []`)
+	require.Contains(t, buf.String(), `"results": []`)
+
+	_, err = newReporter("xml", &buf, syntax.Renderer{})
+	require.EqualError(t, err, `unknown format "xml", expected one of text, json, sarif, github`)
+}
+
+func TestEscapeProperty(t *testing.T) {
+	tests := []struct {
+		input, expected string
+	}{
+		{"messages.ftl", "messages.ftl"},
+		{"C:\\l10n\\a,b.ftl", "C%3A\\l10n\\a%2Cb.ftl"},
+		{"100%\r\n", "100%25%0D%0A"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, escapeProperty(tt.input))
+	}
+}
+
+func TestFilepathToURI(t *testing.T) {
+	tests := []struct {
+		file, expected string
+	}{
+		{"messages.ftl", "messages.ftl"},
+		{filepath.Join("en-US", "main.ftl"), "en-US/main.ftl"},
+		{filepath.Join("l10n", "a b#1.ftl"), "l10n/a%20b%231.ftl"},
+		{"a:b.ftl", "./a:b.ftl"},
+	}
+	if runtime.GOOS == "windows" {
+		tests = append(tests, struct{ file, expected string }{`C:\l10n\main.ftl`, "file:///C:/l10n/main.ftl"})
+	} else {
+		tests = append(tests, struct{ file, expected string }{"/l10n/main.ftl", "file:///l10n/main.ftl"})
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, filepathToURI(tt.file))
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/compare"
+	"github.com/michalnicp/fluent-go/syntax"
+	"github.com/michalnicp/fluent-go/tmx"
+)
+
+var tmxUsage = `Usage: fluent tmx [options] reference translation...
+
+Writes a TMX 1.4 translation memory of the messages of the reference directory,
+e.g. l10n/en-US, and their translations in the translation directories, e.g.
+l10n/de, and prints it, or writes it to the file given with -o.
+
+Messages are aligned by the path of their file and their ID. Each message value
+and attribute becomes a translation unit, unless it has select expressions, in
+which case the text around them and each variant become units. Placeables
+become placeholders. The languages are the names of the directories, with "_"
+replaced by "-", e.g. pt_BR becomes pt-BR.
+
+Options:
+  -o FILE     Write to FILE instead of stdout.
+  -h, -help   Print this message and exit.`
+
+func runTMX(args []string) int {
+	flags := flag.NewFlagSet("tmx", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, tmxUsage) }
+
+	var (
+		output        string
+		helpRequested bool
+	)
+
+	flags.StringVar(&output, "o", "", "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(tmxUsage)
+		return 0
+	}
+
+	if flags.NArg() < 2 {
+		fmt.Fprintln(os.Stderr, tmxUsage)
+		return 2
+	}
+
+	reporter, err := newReporter("text", os.Stderr, syntax.Renderer{Context: 2})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	code := 0
+	var locales []compare.Locale
+	for _, dir := range flags.Args() {
+		locale, ok, err := readLocale(dir, reporter)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !ok {
+			code = 1
+		}
+		locales = append(locales, locale)
+	}
+
+	language := func(locale compare.Locale) string {
+		return strings.Replace(locale.Name, "_", "-", -1)
+	}
+	doc := &tmx.Document{
+		CreationTool:        "fluent",
+		CreationToolVersion: version,
+		SourceLanguage:      language(locales[0]),
+	}
+	for i, file := range locales[0].Files {
+		reference := tmx.Translation{Language: doc.SourceLanguage, Resource: &locales[0].Files[i].Resource}
+		var translations []tmx.Translation
+		for _, locale := range locales[1:] {
+			translation := tmx.Translation{Language: language(locale)}
+			for j, f := range locale.Files {
+				if f.Path == file.Path {
+					translation.Resource = &locale.Files[j].Resource
+				}
+			}
+			translations = append(translations, translation)
+		}
+		doc.Units = append(doc.Units, tmx.Export(file.Path, reference, translations)...)
+	}
+
+	var buf bytes.Buffer
+	if err := tmx.Write(&buf, doc); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = ioutil.WriteFile(output, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return code
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/compare"
+	"github.com/michalnicp/fluent-go/syntax"
+	"github.com/michalnicp/fluent-go/xliff"
+)
+
+var xliffUsage = `Usage: fluent xliff export [options] reference
+       fluent xliff import [options] file target
+
+Converts locales to and from XLIFF 2.0 documents, for translation with CAT
+tools.
+
+export converts the .ftl files in the reference directory, e.g. l10n/en-US,
+to a document and prints it, or writes it to the file given with -o. Each
+message value and attribute becomes a unit. Placeables become placeholders
+which cannot be changed, and the variants of select expressions become
+separate segments.
+
+import converts the translations of a document back and writes them to the
+same paths in the target directory, e.g. l10n/de. Messages are only written if
+all their segments are translated.
+
+Export options:
+  -o FILE             Write to FILE instead of stdout.
+  -translation DIR    Fill in the translations of the .ftl files in DIR, e.g.
+                      to update a document after the reference changed.
+  -source-lang LANG   The language of the reference. Defaults to the name of
+                      the reference directory.
+  -lang LANG          The language of the translations. Defaults to the name
+                      of the translation directory, if given.
+
+Options:
+  -h, -help           Print this message and exit.`
+
+func runXLIFF(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "export":
+			return runXLIFFExport(args[1:])
+		case "import":
+			return runXLIFFImport(args[1:])
+		case "-h", "-help", "--help":
+			fmt.Println(xliffUsage)
+			return 0
+		}
+	}
+	fmt.Fprintln(os.Stderr, xliffUsage)
+	return 2
+}
+
+func runXLIFFExport(args []string) int {
+	flags := flag.NewFlagSet("xliff export", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, xliffUsage) }
+
+	var (
+		output         string
+		translation    string
+		sourceLanguage string
+		targetLanguage string
+		helpRequested  bool
+	)
+
+	flags.StringVar(&output, "o", "", "")
+	flags.StringVar(&translation, "translation", "", "")
+	flags.StringVar(&sourceLanguage, "source-lang", "", "")
+	flags.StringVar(&targetLanguage, "lang", "", "")
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(xliffUsage)
+		return 0
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, xliffUsage)
+		return 2
+	}
+
+	reporter, err := newReporter("text", os.Stderr, syntax.Renderer{Context: 2})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	code := 0
+	reference, ok, err := readLocale(flags.Arg(0), reporter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !ok {
+		code = 1
+	}
+
+	var translated compare.Locale
+	if translation != "" {
+		translated, ok, err = readLocale(translation, reporter)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !ok {
+			code = 1
+		}
+		if targetLanguage == "" {
+			targetLanguage = translated.Name
+		}
+	}
+	if sourceLanguage == "" {
+		sourceLanguage = reference.Name
+	}
+
+	doc := &xliff.Document{SourceLanguage: sourceLanguage, TargetLanguage: targetLanguage}
+	for i, file := range reference.Files {
+		var resource *syntax.Resource
+		for j, f := range translated.Files {
+			if f.Path == file.Path {
+				resource = &translated.Files[j].Resource
+			}
+		}
+
+		f := xliff.Export(file.Path, file.Resource, resource)
+		f.ID = "f" + strconv.Itoa(i+1)
+		doc.Files = append(doc.Files, f)
+	}
+
+	var buf bytes.Buffer
+	if err := xliff.Write(&buf, doc); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = ioutil.WriteFile(output, buf.Bytes(), 0644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return code
+}
+
+func runXLIFFImport(args []string) int {
+	flags := flag.NewFlagSet("xliff import", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, xliffUsage) }
+
+	var helpRequested bool
+	flags.BoolVar(&helpRequested, "help", false, "")
+	flags.BoolVar(&helpRequested, "h", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if helpRequested {
+		fmt.Println(xliffUsage)
+		return 0
+	}
+
+	if flags.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, xliffUsage)
+		return 2
+	}
+	file, target := flags.Arg(0), flags.Arg(1)
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	doc, err := xliff.Parse(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		return 1
+	}
+
+	code := 0
+	for _, f := range doc.Files {
+		// The original must be a relative path, which stays in the target
+		// directory.
+		original := path.Clean(f.Original)
+		if f.Original == "" || path.IsAbs(original) || original == ".." || strings.HasPrefix(original, "../") {
+			fmt.Fprintf(os.Stderr, "%s: file %s: invalid original %q\n", file, f.ID, f.Original)
+			code = 1
+			continue
+		}
+
+		resource, err := xliff.Import(f)
+		if err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				fmt.Fprintf(os.Stderr, "%s: %s: %s\n", file, original, line)
+			}
+			code = 1
+		}
+
+		var buf bytes.Buffer
+		if err := syntax.Fprint(&buf, resource); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		path := filepath.Join(target, filepath.FromSlash(original))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return code
+}
@@ -0,0 +1,301 @@
+// Package compare checks the resources of a locale against those of a
+// reference locale, for missing and obsolete translations and for variables
+// which the reference does not provide.
+package compare
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// A File is a parsed resource of a locale.
+type File struct {
+	// Path is the slash separated path relative to the locale directory, by
+	// which files of different locales are matched.
+	Path     string
+	Input    []byte
+	Resource syntax.Resource
+}
+
+// A Locale is a set of files.
+type Locale struct {
+	Name  string
+	Files []File
+}
+
+// A Problem is a diagnostic in a file of the reference or the target locale.
+// The codes of the diagnostics are:
+//
+//   - C0001: a file of the reference is missing,
+//   - C0002: a file is not in the reference,
+//   - C0003: a message of the reference is missing,
+//   - C0004: an attribute of a message in the reference is missing,
+//   - C0005: a term of the reference is missing,
+//   - C0006: a message or term is not in the reference,
+//   - C0007: an attribute is not in the reference,
+//   - C0008: a variable is used which the reference does not use.
+//
+// Missing translations are warnings, because they fall back to the reference
+// at runtime. Missing terms and unknown variables are errors, because
+// references to them fail.
+type Problem struct {
+	// Reference is whether the span refers to the reference file, which is
+	// the case for missing files, messages and terms.
+	Reference bool
+
+	// Path is the path of the file, as in File.
+	Path string
+
+	syntax.Diagnostic
+}
+
+// A Summary counts the messages of a locale.
+type Summary struct {
+	Locale string
+
+	// Messages is the number of messages in the reference.
+	Messages int
+
+	// Translated is the number of messages which are translated with all
+	// attributes of the reference.
+	Translated int
+
+	// Missing is the number of messages in the reference which are not
+	// translated at all.
+	Missing int
+
+	// Obsolete is the number of messages which are not in the reference.
+	Obsolete int
+
+	Errors   int
+	Warnings int
+}
+
+// Completion returns the percentage of translated messages.
+func (s Summary) Completion() float64 {
+	if s.Messages == 0 {
+		return 100
+	}
+	return 100 * float64(s.Translated) / float64(s.Messages)
+}
+
+// Compare compares the target locale against the reference. Problems are
+// ordered by path. The problems of each path are the missing messages,
+// attributes and terms in the order of the reference file, then the other
+// problems in the order of the target file. Problems in the reference file
+// name the target locale, since they are reported once for each locale.
+func Compare(reference, target Locale) ([]Problem, Summary) {
+	c := comparison{summary: Summary{Locale: target.Name}}
+
+	targetFiles := make(map[string]File, len(target.Files))
+	for _, file := range target.Files {
+		targetFiles[file.Path] = file
+	}
+	referenceFiles := make(map[string]File, len(reference.Files))
+	for _, file := range reference.Files {
+		referenceFiles[file.Path] = file
+	}
+
+	var paths []string
+	for path := range referenceFiles {
+		paths = append(paths, path)
+	}
+	for path := range targetFiles {
+		if _, ok := referenceFiles[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		ref, inReference := referenceFiles[path]
+		file, inTarget := targetFiles[path]
+		switch {
+		case !inTarget:
+			c.report(true, path, syntax.SeverityWarning, "C0001", nil, "Missing file %s in %s", path, target.Name)
+			c.compareFile(ref, File{Path: path}, true)
+		case !inReference:
+			c.report(false, path, syntax.SeverityWarning, "C0002", nil, "File %s is not in the reference", path)
+			c.summary.Obsolete += len(entries(file.Resource).messages)
+		default:
+			c.compareFile(ref, file, false)
+		}
+	}
+
+	return c.problems, c.summary
+}
+
+type comparison struct {
+	problems []Problem
+	summary  Summary
+}
+
+func (c *comparison) report(reference bool, path string, severity syntax.Severity, code string, span *syntax.Span, format string, args ...interface{}) {
+	p := Problem{
+		Reference: reference,
+		Path:      path,
+		Diagnostic: syntax.Diagnostic{
+			Severity: severity,
+			Code:     code,
+			Message:  fmt.Sprintf(format, args...),
+		},
+	}
+	if span != nil {
+		p.Span = *span
+	}
+
+	if severity == syntax.SeverityError {
+		c.summary.Errors++
+	} else {
+		c.summary.Warnings++
+	}
+	c.problems = append(c.problems, p)
+}
+
+// compareFile compares a file with the reference file of the same path. A
+// missing file is compared as empty, without reporting each message.
+func (c *comparison) compareFile(ref, file File, missingFile bool) {
+	refEntries := entries(ref.Resource)
+	fileEntries := entries(file.Resource)
+
+	for _, message := range refEntries.messages {
+		c.summary.Messages++
+
+		translation, ok := fileEntries.message(message.ID.Name)
+		if !ok {
+			c.summary.Missing++
+			if !missingFile {
+				c.report(true, ref.Path, syntax.SeverityWarning, "C0003", message.ID.Span,
+					"Missing message \"%s\" in %s", message.ID.Name, c.summary.Locale)
+			}
+			continue
+		}
+
+		complete := true
+		for _, attribute := range message.Attributes {
+			if _, ok := findAttribute(translation.Attributes, attribute.ID.Name); !ok {
+				complete = false
+				c.report(false, file.Path, syntax.SeverityWarning, "C0004", translation.ID.Span,
+					"Message \"%s\" is missing the attribute \"%s\"", message.ID.Name, attribute.ID.Name)
+			}
+		}
+		if complete {
+			c.summary.Translated++
+		}
+	}
+
+	for _, term := range refEntries.terms {
+		if _, ok := fileEntries.term(term.ID.Name); !ok && !missingFile {
+			c.report(true, ref.Path, syntax.SeverityError, "C0005", term.ID.Span,
+				"Missing term \"-%s\" in %s", term.ID.Name, c.summary.Locale)
+		}
+	}
+
+	// Problems in the file are reported in the order of the file.
+	for _, entry := range file.Resource.Body {
+		switch entry := entry.(type) {
+		case syntax.Message:
+			message, ok := refEntries.message(entry.ID.Name)
+			if !ok {
+				c.summary.Obsolete++
+				c.report(false, file.Path, syntax.SeverityWarning, "C0006", entry.ID.Span,
+					"Message \"%s\" is not in the reference", entry.ID.Name)
+				continue
+			}
+			c.compareMessage(file.Path, message, entry)
+		case syntax.Term:
+			if _, ok := refEntries.term(entry.ID.Name); !ok {
+				c.report(false, file.Path, syntax.SeverityWarning, "C0006", entry.ID.Span,
+					"Term \"-%s\" is not in the reference", entry.ID.Name)
+			}
+		}
+	}
+}
+
+// compareMessage checks the attributes and variables of a translated message.
+// Variables are passed to the whole message, so the value and the attributes
+// may use any variable of the reference message.
+func (c *comparison) compareMessage(path string, ref, message syntax.Message) {
+	variables := make(map[string]bool)
+	collect := func(expr syntax.Expression) {
+		if v, ok := expr.(syntax.VariableReference); ok {
+			variables[v.ID.Name] = true
+		}
+	}
+	if ref.Value != nil {
+		syntax.InspectPattern(*ref.Value, collect)
+	}
+	for _, attribute := range ref.Attributes {
+		syntax.InspectPattern(attribute.Value, collect)
+	}
+
+	if message.Value != nil {
+		c.checkVariables(path, message.ID.Name, *message.Value, variables)
+	}
+	for _, attribute := range message.Attributes {
+		if _, ok := findAttribute(ref.Attributes, attribute.ID.Name); !ok {
+			c.report(false, path, syntax.SeverityWarning, "C0007", attribute.ID.Span,
+				"Attribute \"%s.%s\" is not in the reference", message.ID.Name, attribute.ID.Name)
+		}
+		c.checkVariables(path, message.ID.Name, attribute.Value, variables)
+	}
+}
+
+// checkVariables reports variables in pattern which are not in the reference,
+// and so are not provided by the code formatting the message.
+func (c *comparison) checkVariables(path, name string, pattern syntax.Pattern, variables map[string]bool) {
+	syntax.InspectPattern(pattern, func(expr syntax.Expression) {
+		if v, ok := expr.(syntax.VariableReference); ok && !variables[v.ID.Name] {
+			c.report(false, path, syntax.SeverityError, "C0008", v.Span,
+				"Variable $%s is not used in the reference of \"%s\"", v.ID.Name, name)
+		}
+	})
+}
+
+// entryIndex holds the messages and terms of a resource, in order and by
+// name.
+type entryIndex struct {
+	messages []syntax.Message
+	terms    []syntax.Term
+	byName   map[string]syntax.Entry
+}
+
+func entries(resource syntax.Resource) entryIndex {
+	index := entryIndex{byName: make(map[string]syntax.Entry)}
+	for _, entry := range resource.Body {
+		switch entry := entry.(type) {
+		case syntax.Message:
+			index.messages = append(index.messages, entry)
+			if _, ok := index.byName[entry.ID.Name]; !ok {
+				index.byName[entry.ID.Name] = entry
+			}
+		case syntax.Term:
+			index.terms = append(index.terms, entry)
+			if _, ok := index.byName["-"+entry.ID.Name]; !ok {
+				index.byName["-"+entry.ID.Name] = entry
+			}
+		}
+	}
+	return index
+}
+
+func (index entryIndex) message(name string) (syntax.Message, bool) {
+	message, ok := index.byName[name].(syntax.Message)
+	return message, ok
+}
+
+func (index entryIndex) term(name string) (syntax.Term, bool) {
+	term, ok := index.byName["-"+name].(syntax.Term)
+	return term, ok
+}
+
+func findAttribute(attributes []syntax.Attribute, name string) (syntax.Attribute, bool) {
+	for _, attribute := range attributes {
+		if attribute.ID.Name == name {
+			return attribute, true
+		}
+	}
+	return syntax.Attribute{}, false
+}
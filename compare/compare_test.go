@@ -0,0 +1,100 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference map[string]string
+		target    map[string]string
+		problems  []Problem
+		summary   Summary
+	}{
+		{
+			name:      "complete",
+			reference: map[string]string{"main.ftl": "-brand = Firefox\nhello = Hello { $name }\n    .title = { $count }\n"},
+			target:    map[string]string{"main.ftl": "-brand = Firefox\nhello = Hallo { $count }\n    .title = { $name }\n"},
+			summary:   Summary{Locale: "de", Messages: 1, Translated: 1},
+		},
+		{
+			name:      "missing",
+			reference: map[string]string{"main.ftl": "-brand = Firefox\nhello = Hello\n    .title = Title\nbye = Bye\n"},
+			target:    map[string]string{"main.ftl": "hello = Hallo\n"},
+			problems: []Problem{
+				{Path: "main.ftl", Diagnostic: warning("C0004", `Message "hello" is missing the attribute "title"`, 0, 5)},
+				{Reference: true, Path: "main.ftl", Diagnostic: warning("C0003", `Missing message "bye" in de`, 50, 53)},
+				{Reference: true, Path: "main.ftl", Diagnostic: syntax.Diagnostic{Code: "C0005", Message: `Missing term "-brand" in de`, Span: syntax.Span{Start: 1, End: 6}}},
+			},
+			summary: Summary{Locale: "de", Messages: 2, Missing: 1, Errors: 1, Warnings: 2},
+		},
+		{
+			name:      "obsolete",
+			reference: map[string]string{"main.ftl": "hello = Hello\n"},
+			target:    map[string]string{"main.ftl": "hello = Hallo\n    .title = Titel\nold = Alt\n-old = Alt\n"},
+			problems: []Problem{
+				{Path: "main.ftl", Diagnostic: warning("C0007", `Attribute "hello.title" is not in the reference`, 19, 24)},
+				{Path: "main.ftl", Diagnostic: warning("C0006", `Message "old" is not in the reference`, 33, 36)},
+				{Path: "main.ftl", Diagnostic: warning("C0006", `Term "-old" is not in the reference`, 44, 47)},
+			},
+			summary: Summary{Locale: "de", Messages: 1, Translated: 1, Obsolete: 1, Warnings: 3},
+		},
+		{
+			name:      "variables",
+			reference: map[string]string{"main.ftl": "hello = Hello { $name }\n"},
+			target:    map[string]string{"main.ftl": "hello = { $count ->\n   *[other] Hallo { $user }\n}\n"},
+			problems: []Problem{
+				{Path: "main.ftl", Diagnostic: syntax.Diagnostic{Code: "C0008", Message: `Variable $count is not used in the reference of "hello"`, Span: syntax.Span{Start: 10, End: 16}}},
+				{Path: "main.ftl", Diagnostic: syntax.Diagnostic{Code: "C0008", Message: `Variable $user is not used in the reference of "hello"`, Span: syntax.Span{Start: 40, End: 45}}},
+			},
+			summary: Summary{Locale: "de", Messages: 1, Translated: 1, Errors: 2},
+		},
+		{
+			name:      "files",
+			reference: map[string]string{"a.ftl": "a = A\nb = B\n-t = T\n", "b.ftl": "c = C\n"},
+			target:    map[string]string{"b.ftl": "c = C\n", "c.ftl": "d = D\ne = E\n"},
+			problems: []Problem{
+				{Reference: true, Path: "a.ftl", Diagnostic: warning("C0001", "Missing file a.ftl in de", 0, 0)},
+				{Path: "c.ftl", Diagnostic: warning("C0002", "File c.ftl is not in the reference", 0, 0)},
+			},
+			summary: Summary{Locale: "de", Messages: 3, Translated: 1, Missing: 2, Obsolete: 2, Warnings: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, summary := Compare(locale(t, "en-US", tt.reference), locale(t, "de", tt.target))
+			require.Equal(t, tt.problems, problems)
+			require.Equal(t, tt.summary, summary)
+		})
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	require.Equal(t, 100.0, Summary{}.Completion())
+	require.Equal(t, 75.0, Summary{Messages: 4, Translated: 3}.Completion())
+}
+
+func locale(t *testing.T, name string, files map[string]string) Locale {
+	l := Locale{Name: name}
+	for path, input := range files {
+		resource, err := syntax.Parse([]byte(input))
+		require.NoError(t, err)
+		l.Files = append(l.Files, File{Path: path, Input: []byte(input), Resource: resource})
+	}
+	return l
+}
+
+func warning(code, message string, start, end int) syntax.Diagnostic {
+	return syntax.Diagnostic{
+		Severity: syntax.SeverityWarning,
+		Code:     code,
+		Message:  message,
+		Span:     syntax.Span{Start: start, End: end},
+	}
+}
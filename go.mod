@@ -1,8 +1,14 @@
 module github.com/michalnicp/fluent-go
 
-go 1.12
+go 1.20
 
 require (
 	github.com/stretchr/testify v1.4.0
 	golang.org/x/text v0.3.2
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
@@ -0,0 +1,297 @@
+package i18next
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/ident"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// CountVariable is the variable plurals select on, the count option of
+// i18next.
+const CountVariable = "count"
+
+// ToFluent converts an i18next resource to a Fluent resource.
+//
+// Strings become messages, with IDs derived from their keys, where characters
+// other than ASCII letters, digits, "_" and "-" become "-", e.g. "home.title"
+// becomes "home-title". IDs are made unique by appending a number.
+//
+// Strings whose keys have plural suffixes, e.g. "item_one" and "item_other",
+// become a message with a select expression on $count, with a variant for
+// each plural category, [0] for the _zero suffix, which i18next uses for 0 in
+// all languages, and the other form as the default. Ordinal suffixes, as in
+// "place_ordinal_one", select on NUMBER($count, type: "ordinal"), and the
+// _plural suffix of older versions of i18next is the other form of the
+// string without it.
+//
+// Interpolations such as {{name}} become variables, {{n, number}} and
+// {{d, datetime}} calls of NUMBER and DATETIME, and nesting such as
+// $t(home.title) message references. Strings which cannot be expressed in
+// Fluent, e.g. with other formats, are reported in the error, which joins an
+// error for each of them, and left out.
+func ToFluent(file *File) (syntax.Resource, error) {
+	resource := syntax.Resource{Body: make([]syntax.Entry, 0)}
+	messages := groupPlurals(file.Entries)
+
+	ids := make(map[string]string)
+	used := make(map[string]bool)
+	for _, m := range messages {
+		m.id = ident.Unique(used, ident.FromKey(m.key))
+		if _, ok := ids[m.key]; !ok {
+			ids[m.key] = m.id
+		}
+	}
+
+	var errs []error
+	for _, m := range messages {
+		value, err := m.pattern(ids)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", m.keys[0], err))
+			continue
+		}
+		resource.Body = append(resource.Body, syntax.Message{
+			ID:         syntax.Identifier{Name: m.id},
+			Value:      &value,
+			Attributes: make([]syntax.Attribute, 0),
+		})
+	}
+
+	return resource, errors.Join(errs...)
+}
+
+// message is a string, or the strings of the plural forms of a key.
+type message struct {
+	// key is the key without plural suffix.
+	key string
+
+	// keys are the keys of the strings.
+	keys []string
+
+	id string
+
+	value string
+
+	ordinal bool
+	forms   []form
+}
+
+type form struct {
+	category string
+	value    string
+}
+
+// pluralRegexp matches the key of a plural form.
+var pluralRegexp = regexp.MustCompile(`^(.+?)(_ordinal)?_(zero|one|two|few|many|other)$`)
+
+// groupPlurals returns the messages of entries, with the plural forms of a
+// key grouped, in the order of their first entry.
+func groupPlurals(entries []Entry) []*message {
+	keys := make(map[string]bool)
+	for _, entry := range entries {
+		keys[entry.Key] = true
+	}
+
+	var messages []*message
+	plurals := make(map[string]*message)
+	add := func(key string, ordinal bool, entry Entry, category string) {
+		id := key
+		if ordinal {
+			id += "_ordinal"
+		}
+		m, ok := plurals[id]
+		if !ok {
+			m = &message{key: key, ordinal: ordinal}
+			plurals[id] = m
+			messages = append(messages, m)
+		}
+		m.keys = append(m.keys, entry.Key)
+		m.forms = append(m.forms, form{category: category, value: entry.Value})
+	}
+
+	for _, entry := range entries {
+		if m := pluralRegexp.FindStringSubmatch(entry.Key); m != nil && keys[m[1]+m[2]+"_other"] {
+			add(m[1], m[2] != "", entry, m[3])
+			continue
+		}
+		if key := strings.TrimSuffix(entry.Key, "_plural"); key != entry.Key && keys[key] {
+			add(key, false, entry, "other")
+			continue
+		}
+		if keys[entry.Key+"_plural"] {
+			add(entry.Key, false, entry, "one")
+			continue
+		}
+		messages = append(messages, &message{key: entry.Key, keys: []string{entry.Key}, value: entry.Value})
+	}
+	return messages
+}
+
+// pattern returns the pattern of a message.
+func (m *message) pattern(ids map[string]string) (syntax.Pattern, error) {
+	if m.forms == nil {
+		return pattern(m.value, ids)
+	}
+
+	var selector syntax.InlineExpression = syntax.VariableReference{ID: syntax.Identifier{Name: CountVariable}}
+	if m.ordinal {
+		selector = syntax.FunctionReference{
+			ID: syntax.Identifier{Name: "NUMBER"},
+			Arguments: syntax.CallArguments{
+				Positional: []syntax.InlineExpression{selector},
+				Named: []syntax.NamedArgument{
+					{Name: syntax.Identifier{Name: "type"}, Value: syntax.StringLiteral{Value: "ordinal"}},
+				},
+			},
+		}
+	}
+
+	expr := syntax.SelectExpression{Selector: selector}
+	for _, form := range m.forms {
+		value, err := pattern(form.value, ids)
+		if err != nil {
+			return syntax.Pattern{}, err
+		}
+		var key syntax.VariantKey = syntax.Identifier{Name: form.category}
+		if form.category == "zero" {
+			key = syntax.NumberLiteral{Value: "0"}
+		}
+		expr.Variants = append(expr.Variants, syntax.Variant{
+			Key:     key,
+			Value:   value,
+			Default: form.category == "other",
+		})
+	}
+
+	return syntax.Pattern{Elements: []syntax.PatternElement{syntax.Placeable{Expr: expr}}}, nil
+}
+
+// placeholderRegexp matches an interpolation, as in {{name}}, or nesting, as
+// in $t(key).
+var placeholderRegexp = regexp.MustCompile(`\{\{(.*?)\}\}|\$t\(([^)]*)\)`)
+
+var formatRegexp = regexp.MustCompile(`^([a-zA-Z]+)(?:\((.*)\))?$`)
+
+// pattern converts the value of a string to a pattern.
+func pattern(value string, ids map[string]string) (syntax.Pattern, error) {
+	if value == "" {
+		return syntax.TextPattern(""), nil
+	}
+
+	var elements []syntax.PatternElement
+	last := 0
+	for _, m := range placeholderRegexp.FindAllStringSubmatchIndex(value, -1) {
+		if m[0] > last {
+			elements = append(elements, syntax.TextElement{Value: value[last:m[0]]})
+		}
+		last = m[1]
+
+		var (
+			expr syntax.Expression
+			err  error
+		)
+		if m[2] >= 0 {
+			expr, err = interpolation(value[m[2]:m[3]])
+		} else {
+			expr, err = nesting(value[m[4]:m[5]], ids)
+		}
+		if err != nil {
+			return syntax.Pattern{}, err
+		}
+		elements = append(elements, syntax.Placeable{Expr: expr})
+	}
+	if last < len(value) {
+		elements = append(elements, syntax.TextElement{Value: value[last:]})
+	}
+
+	return syntax.Pattern{Elements: elements}, nil
+}
+
+// interpolation converts the text of an interpolation, e.g. "name" or
+// "n, number(minimumFractionDigits: 2)", to an expression.
+func interpolation(text string) (syntax.Expression, error) {
+	text = strings.TrimSpace(text)
+	// {{- name}} is not HTML-escaped, which is not done by Fluent either.
+	text = strings.TrimSpace(strings.TrimPrefix(text, "-"))
+
+	name, format := text, ""
+	if i := strings.Index(text, ","); i >= 0 {
+		name, format = strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:])
+	}
+	if !ident.Valid(name) {
+		return nil, fmt.Errorf("{{%s}}: %q is not a valid Fluent variable name", text, name)
+	}
+	variable := syntax.VariableReference{ID: syntax.Identifier{Name: name}}
+	if format == "" {
+		return variable, nil
+	}
+
+	m := formatRegexp.FindStringSubmatch(format)
+	if m == nil {
+		return nil, fmt.Errorf("{{%s}}: format %q cannot be expressed in Fluent", text, format)
+	}
+	function := ""
+	var options []syntax.NamedArgument
+	switch m[1] {
+	case "number":
+		function = "NUMBER"
+	case "datetime":
+		function = "DATETIME"
+	case "currency":
+		function = "NUMBER"
+		options = append(options, syntax.NamedArgument{
+			Name:  syntax.Identifier{Name: "style"},
+			Value: syntax.StringLiteral{Value: "currency"},
+		})
+		if code := strings.TrimSpace(m[2]); code != "" && !strings.Contains(code, ":") {
+			// currency(USD) is short for currency(currency: USD).
+			m[2] = "currency: " + code
+		}
+	default:
+		return nil, fmt.Errorf("{{%s}}: format %q cannot be expressed in Fluent", text, m[1])
+	}
+
+	if m[2] != "" {
+		for _, option := range strings.Split(m[2], ";") {
+			i := strings.Index(option, ":")
+			if i < 0 {
+				return nil, fmt.Errorf("{{%s}}: invalid format option %q", text, strings.TrimSpace(option))
+			}
+			name := strings.TrimSpace(option[:i])
+			value := strings.Trim(strings.TrimSpace(option[i+1:]), `"'`)
+			if !ident.Valid(name) {
+				return nil, fmt.Errorf("{{%s}}: invalid format option %q", text, strings.TrimSpace(option))
+			}
+			var literal syntax.InlineExpression = syntax.StringLiteral{Value: value}
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				literal = syntax.NumberLiteral{Value: value}
+			}
+			options = append(options, syntax.NamedArgument{Name: syntax.Identifier{Name: name}, Value: literal})
+		}
+	}
+
+	return syntax.FunctionReference{
+		ID: syntax.Identifier{Name: function},
+		Arguments: syntax.CallArguments{
+			Positional: []syntax.InlineExpression{variable},
+			Named:      options,
+		},
+	}, nil
+}
+
+// nesting converts the key of a nesting to a message reference.
+func nesting(text string, ids map[string]string) (syntax.Expression, error) {
+	key := strings.TrimSpace(text)
+	if strings.Contains(key, ",") {
+		return nil, fmt.Errorf("$t(%s): nesting with options cannot be expressed in Fluent", text)
+	}
+	id, ok := ids[key]
+	if !ok {
+		id = ident.FromKey(key)
+	}
+	return syntax.MessageReference{ID: syntax.Identifier{Name: id}}, nil
+}
@@ -0,0 +1,65 @@
+package i18next
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestToFluent(t *testing.T) {
+	file := &File{Entries: []Entry{
+		{Key: "home.title", Value: "Welcome to {{- app}}"},
+		{Key: "greeting", Value: "Hello, {{ name }}! { braces }"},
+		{Key: "item_zero", Value: "No items"},
+		{Key: "item_one", Value: "One item"},
+		{Key: "item_other", Value: "{{count}} items"},
+		{Key: "place_ordinal_one", Value: "{{count}}st"},
+		{Key: "place_ordinal_other", Value: "{{count}}th"},
+		{Key: "file", Value: "One file"},
+		{Key: "file_plural", Value: "{{count}} files"},
+		{Key: "only_other", Value: "{{count}} items"},
+		{Key: "formats", Value: "{{n, number(minimumFractionDigits: 2; maximumFractionDigits: 2)}} {{d, datetime}} {{p, currency(USD)}}"},
+		{Key: "nesting", Value: "$t(home.title) and $t(item)"},
+		{Key: "relative", Value: "{{t, relativetime}}"},
+		{Key: "nested-options", Value: "$t(item, {\"count\": 2})"},
+		{Key: "path", Value: "{{user.name}}"},
+		{Key: "empty", Value: ""},
+	}}
+
+	resource, err := ToFluent(file)
+	require.EqualError(t, err, `relative: {{t, relativetime}}: format "relativetime" cannot be expressed in Fluent`+"\n"+
+		`nested-options: $t(item, {"count": 2}): nesting with options cannot be expressed in Fluent`+"\n"+
+		`path: {{user.name}}: "user.name" is not a valid Fluent variable name`)
+
+	var buf bytes.Buffer
+	require.NoError(t, syntax.Fprint(&buf, resource))
+	require.Equal(t, `home-title = Welcome to { $app }
+greeting = Hello, { $name }! { "{" } braces { "}" }
+item =
+    { $count ->
+        [0] No items
+        [one] One item
+       *[other] { $count } items
+    }
+place =
+    { NUMBER($count, type: "ordinal") ->
+        [one] { $count }st
+       *[other] { $count }th
+    }
+file =
+    { $count ->
+        [one] One file
+       *[other] { $count } files
+    }
+only =
+    { $count ->
+       *[other] { $count } items
+    }
+formats = { NUMBER($n, minimumFractionDigits: 2, maximumFractionDigits: 2) } { DATETIME($d) } { NUMBER($p, style: "currency", currency: "USD") }
+nesting = { home-title } and { item }
+empty = { "" }
+`, buf.String())
+}
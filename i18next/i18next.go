@@ -0,0 +1,103 @@
+// Package i18next converts i18next JSON resources to Fluent.
+package i18next
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// A File is a parsed i18next JSON resource, with nested keys flattened.
+type File struct {
+	Entries []Entry
+}
+
+// An Entry is a string of a resource.
+type Entry struct {
+	// Key is the key of the string, with the keys of the objects it is
+	// nested in joined by ".", e.g. "home.title".
+	Key string
+
+	Value string
+}
+
+// Parse parses an i18next JSON resource, in the order of the keys. Values
+// other than strings and objects, e.g. arrays, are reported in the error,
+// which joins an error for each of them, and left out of the returned file.
+// The file is nil if the JSON is not a valid object.
+func Parse(data []byte) (*File, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("expected an object")
+	}
+
+	file := &File{Entries: make([]Entry, 0)}
+	var errs []error
+	if err := readObject(d, "", file, &errs); err != nil {
+		return nil, err
+	}
+	return file, errors.Join(errs...)
+}
+
+// readObject reads the members of an object up to its end, prefixing their
+// keys.
+func readObject(d *json.Decoder, prefix string, file *File, errs *[]error) error {
+	for d.More() {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		key := prefix + token.(string)
+
+		token, err = d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case string:
+			file.Entries = append(file.Entries, Entry{Key: key, Value: token})
+		case json.Delim:
+			if token == '{' {
+				if err := readObject(d, key+".", file, errs); err != nil {
+					return err
+				}
+				continue
+			}
+			*errs = append(*errs, fmt.Errorf("%s: arrays cannot be converted", key))
+			if err := skipArray(d); err != nil {
+				return err
+			}
+		default:
+			*errs = append(*errs, fmt.Errorf("%s: expected a string, got %v", key, token))
+		}
+	}
+
+	// The end of the object.
+	_, err := d.Token()
+	return err
+}
+
+// skipArray skips the values of an array up to its end.
+func skipArray(d *json.Decoder) error {
+	depth := 1
+	for depth > 0 {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('['), json.Delim('{'):
+			depth++
+		case json.Delim(']'), json.Delim('}'):
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package i18next
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	input := []byte(`{
+  "title": "Welcome",
+  "home": {
+    "greeting": "Hello, {{name}}!",
+    "nested": {"deep": "Deep"}
+  },
+  "list": ["a", ["b"], {"c": "d"}],
+  "count": 1,
+  "last": "Last"
+}`)
+
+	file, err := Parse(input)
+	require.EqualError(t, err, "list: arrays cannot be converted\ncount: expected a string, got 1")
+	require.Equal(t, &File{Entries: []Entry{
+		{Key: "title", Value: "Welcome"},
+		{Key: "home.greeting", Value: "Hello, {{name}}!"},
+		{Key: "home.nested.deep", Value: "Deep"},
+		{Key: "last", Value: "Last"},
+	}}, file)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`["a"]`, "expected an object"},
+		{`{"a": "b"`, "unexpected end of JSON input"},
+		{`{"a" "b"}`, "invalid character '\"' after object key"},
+	}
+
+	for _, tt := range tests {
+		file, err := Parse([]byte(tt.input))
+		require.EqualError(t, err, tt.expected, tt.input)
+		require.Nil(t, file)
+	}
+}
@@ -0,0 +1,282 @@
+package icu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// FromFluent converts a Fluent pattern to an ICU MessageFormat message, the
+// reverse of ToFluent.
+//
+// Select expressions become plural arguments if they select on a number, i.e.
+// they have a number key or a key which is a plural category other than
+// "other", selectordinal arguments if they select on NUMBER($n, type:
+// "ordinal"), and select arguments otherwise. ICU uses the other variant as
+// the default, so the default variant is also written as other if there is no
+// other variant.
+//
+// Constructs which cannot be expressed in ICU, such as message and term
+// references, functions other than NUMBER and DATETIME, and their options
+// other than those written by ToFluent, are reported in the error, which
+// joins an error for each of them. The message is returned with them left
+// out.
+func FromFluent(pattern syntax.Pattern) (string, error) {
+	var f formatter
+	f.pattern(pattern, "")
+	f.write("")
+	return f.b.String(), errors.Join(f.errs...)
+}
+
+type formatter struct {
+	b    strings.Builder
+	errs []error
+
+	// quoted is whether the message ends in quoted text, which is continued
+	// by special characters of following text.
+	quoted bool
+}
+
+func (f *formatter) errorf(format string, args ...interface{}) {
+	f.errs = append(f.errs, fmt.Errorf(format, args...))
+}
+
+// pattern writes a pattern. In the value of a variant of a plural argument,
+// plural is the name of its variable, written as #.
+func (f *formatter) pattern(pattern syntax.Pattern, plural string) {
+	for _, element := range pattern.Elements {
+		switch element := element.(type) {
+		case syntax.TextElement:
+			f.text(element.Value, plural != "")
+		case syntax.Placeable:
+			f.expression(element.Expr, plural)
+		}
+	}
+}
+
+// text writes text, quoting special characters. Consecutive special
+// characters are quoted together, since two apostrophes in quoted text are an
+// apostrophe rather than the end and start of quoted text.
+func (f *formatter) text(text string, inPlural bool) {
+	for _, r := range text {
+		special := r == '{' || r == '}' || r == '#' && inPlural
+		if special != f.quoted {
+			f.b.WriteByte('\'')
+			f.quoted = special
+		}
+		if r == '\'' {
+			f.b.WriteString("''")
+		} else {
+			f.b.WriteRune(r)
+		}
+	}
+}
+
+// write writes syntax, after ending quoted text.
+func (f *formatter) write(s string) {
+	if f.quoted {
+		f.b.WriteByte('\'')
+		f.quoted = false
+	}
+	f.b.WriteString(s)
+}
+
+func (f *formatter) expression(expr syntax.Expression, plural string) {
+	switch expr := expr.(type) {
+	case syntax.StringLiteral:
+		f.text(expr.Unescape(), plural != "")
+	case syntax.NumberLiteral:
+		f.write(expr.Value)
+	case syntax.VariableReference:
+		if name, ok := f.argumentName(expr); ok {
+			f.write("{" + name + "}")
+		}
+	case syntax.FunctionReference:
+		f.function(expr, plural)
+	case syntax.MessageReference:
+		f.errorf("message reference %s cannot be expressed in ICU MessageFormat", referenceName(expr.ID.Name, expr.Attribute))
+	case syntax.TermReference:
+		f.errorf("term reference -%s cannot be expressed in ICU MessageFormat", referenceName(expr.ID.Name, expr.Attribute))
+	case syntax.Placeable:
+		f.expression(expr.Expr, plural)
+	case syntax.SelectExpression:
+		f.selectExpression(expr, plural)
+	}
+}
+
+func referenceName(name string, attribute *syntax.Identifier) string {
+	if attribute != nil {
+		return name + "." + attribute.Name
+	}
+	return name
+}
+
+// argumentName returns the name of the argument of a variable, and whether
+// it is a valid ICU argument name, which cannot contain "-".
+func (f *formatter) argumentName(variable syntax.VariableReference) (string, bool) {
+	name := variable.ID.Name
+	if strings.Contains(name, "-") {
+		f.errorf("variable $%s cannot be expressed in ICU MessageFormat", name)
+		return "", false
+	}
+	return name, true
+}
+
+// function writes a call of NUMBER or DATETIME with the options written by
+// ToFluent.
+func (f *formatter) function(call syntax.FunctionReference, plural string) {
+	function := call.ID.Name
+	variable, ok := onlyVariable(call)
+	if !ok || function != "NUMBER" && function != "DATETIME" {
+		f.errorf("%s cannot be expressed in ICU MessageFormat", callString(call))
+		return
+	}
+	name, ok := f.argumentName(variable)
+	if !ok {
+		return
+	}
+
+	options := make(map[string]string)
+	for _, option := range call.Arguments.Named {
+		switch value := option.Value.(type) {
+		case syntax.StringLiteral:
+			options[option.Name.Name] = value.Unescape()
+		case syntax.NumberLiteral:
+			options[option.Name.Name] = value.Value
+		}
+	}
+
+	var style string
+	switch {
+	case function == "NUMBER" && len(options) == 0:
+		if name == plural {
+			f.write("#")
+			return
+		}
+		style = "number"
+	case function == "NUMBER" && len(options) == 1 && options["maximumFractionDigits"] == "0":
+		style = "number, integer"
+	case function == "NUMBER" && len(options) == 1 && options["style"] == "percent":
+		style = "number, percent"
+	case function == "DATETIME" && len(options) == 0:
+		// The default of DATETIME is a numeric date.
+		style = "date, short"
+	case function == "DATETIME" && len(options) == 1 && dateTimeStyles[options["dateStyle"]]:
+		style = "date, " + options["dateStyle"]
+	case function == "DATETIME" && len(options) == 1 && dateTimeStyles[options["timeStyle"]]:
+		style = "time, " + options["timeStyle"]
+	default:
+		f.errorf("%s cannot be expressed in ICU MessageFormat", callString(call))
+		return
+	}
+	f.write("{" + name + ", " + style + "}")
+}
+
+// onlyVariable returns the positional argument of a call, if it is its only
+// positional argument and a variable.
+func onlyVariable(call syntax.FunctionReference) (syntax.VariableReference, bool) {
+	if len(call.Arguments.Positional) != 1 {
+		return syntax.VariableReference{}, false
+	}
+	variable, ok := call.Arguments.Positional[0].(syntax.VariableReference)
+	return variable, ok
+}
+
+// callString returns the call in Fluent syntax, for errors.
+func callString(call syntax.FunctionReference) string {
+	s := syntax.FormatPattern(syntax.Pattern{Elements: []syntax.PatternElement{syntax.Placeable{Expr: call}}})
+	return strings.TrimSuffix(strings.TrimPrefix(s, "{ "), " }")
+}
+
+func isString(expr syntax.InlineExpression, value string) bool {
+	literal, ok := expr.(syntax.StringLiteral)
+	return ok && literal.Unescape() == value
+}
+
+// pluralCategories are the keys of variants which make a select expression a
+// plural argument.
+var pluralCategories = map[string]bool{"zero": true, "one": true, "two": true, "few": true, "many": true}
+
+func (f *formatter) selectExpression(expr syntax.SelectExpression, plural string) {
+	var (
+		variable syntax.VariableReference
+		kind     = "select"
+	)
+	switch selector := expr.Selector.(type) {
+	case syntax.VariableReference:
+		variable = selector
+		for _, variant := range expr.Variants {
+			switch key := variant.Key.(type) {
+			case syntax.NumberLiteral:
+				kind = "plural"
+			case syntax.Identifier:
+				if pluralCategories[key.Name] {
+					kind = "plural"
+				}
+			}
+		}
+	case syntax.FunctionReference:
+		v, ok := onlyVariable(selector)
+		named := selector.Arguments.Named
+		switch {
+		case !ok || selector.ID.Name != "NUMBER":
+		case len(named) == 0:
+			variable, kind = v, "plural"
+		case len(named) == 1 && named[0].Name.Name == "type" && isString(named[0].Value, "ordinal"):
+			variable, kind = v, "selectordinal"
+		}
+		if variable.ID.Name == "" {
+			f.errorf("selector %s cannot be expressed in ICU MessageFormat", callString(selector))
+			return
+		}
+	default:
+		f.errorf("select expressions can only select on variables in ICU MessageFormat")
+		return
+	}
+	name, ok := f.argumentName(variable)
+	if !ok {
+		return
+	}
+
+	// # is only written in the variants of plural arguments, not in those of
+	// select arguments nested in them.
+	inner := ""
+	if kind != "select" {
+		inner = name
+	}
+
+	var other, defaultVariant *syntax.Variant
+	for i, variant := range expr.Variants {
+		if key, ok := variant.Key.(syntax.Identifier); ok && key.Name == "other" {
+			other = &expr.Variants[i]
+		}
+		if variant.Default {
+			defaultVariant = &expr.Variants[i]
+		}
+	}
+	if other != nil && defaultVariant != nil && other != defaultVariant {
+		f.errorf("the default variant of $%s is not [other], which is the default in ICU MessageFormat", name)
+		return
+	}
+
+	f.write("{" + name + ", " + kind + ",")
+	for _, variant := range expr.Variants {
+		switch key := variant.Key.(type) {
+		case syntax.NumberLiteral:
+			f.write(" =" + key.Value)
+		case syntax.Identifier:
+			f.write(" " + key.Name)
+		}
+		f.write(" {")
+		f.pattern(variant.Value, inner)
+		f.write("}")
+	}
+	if other == nil && defaultVariant != nil {
+		f.write(" other {")
+		f.pattern(defaultVariant.Value, inner)
+		f.write("}")
+	}
+	f.write("}")
+}
@@ -0,0 +1,96 @@
+package icu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestFromFluent(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		expected string
+	}{
+		{
+			name:     "text",
+			pattern:  "It's {\"{\"}x{\"}\"}{ \"{}\" } # { 1.5 }",
+			expected: "It''s '{'x'}{}' # 1.5",
+		},
+		{
+			name:     "variables",
+			pattern:  "Hello, { $name }!",
+			expected: "Hello, {name}!",
+		},
+		{
+			name:     "plural",
+			pattern:  "{ $count ->\n    [0] No items\n    [one] { NUMBER($count) } item\n   *[other] { $count } items #\n}",
+			expected: "{count, plural, =0 {No items} one {# item} other {{count} items '#'}}",
+		},
+		{
+			name:     "selectordinal",
+			pattern:  "{ NUMBER($n, type: \"ordinal\") ->\n    [one] { NUMBER($n) }st\n   *[other] { NUMBER($n) }th\n}",
+			expected: "{n, selectordinal, one {#st} other {#th}}",
+		},
+		{
+			name:     "select",
+			pattern:  "{ $gender ->\n    [female] { NUMBER($n) } her\n   *[male] his\n}",
+			expected: "{gender, select, female {{n, number} her} male {his} other {his}}",
+		},
+		{
+			name:     "functions",
+			pattern:  "{ NUMBER($a, maximumFractionDigits: 0) } { NUMBER($b, style: \"percent\") } { DATETIME($c) } { DATETIME($d, dateStyle: \"full\") } { DATETIME($e, timeStyle: \"short\") }",
+			expected: "{a, number, integer} {b, number, percent} {c, date, short} {d, date, full} {e, time, short}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := syntax.ParsePattern(tt.pattern)
+			require.NoError(t, err)
+
+			message, err := FromFluent(pattern)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, message)
+
+			_, err = ToFluent(message)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFromFluentErrors(t *testing.T) {
+	pattern, err := syntax.ParsePattern("{ -brand } { msg.attr } { $user-name } { NUMBER($n, minimumFractionDigits: 2) } { PLATFORM() } { $n ->\n    [a] A\n   *[b] B\n    [other] Other\n}")
+	require.NoError(t, err)
+
+	message, err := FromFluent(pattern)
+	require.EqualError(t, err, "term reference -brand cannot be expressed in ICU MessageFormat\n"+
+		"message reference msg.attr cannot be expressed in ICU MessageFormat\n"+
+		"variable $user-name cannot be expressed in ICU MessageFormat\n"+
+		"NUMBER($n, minimumFractionDigits: 2) cannot be expressed in ICU MessageFormat\n"+
+		"PLATFORM() cannot be expressed in ICU MessageFormat\n"+
+		"the default variant of $n is not [other], which is the default in ICU MessageFormat")
+	require.Equal(t, "     ", message)
+}
+
+// TestRoundTrip checks that messages converted to Fluent and back are kept.
+func TestRoundTrip(t *testing.T) {
+	messages := []string{
+		"Hello, {name}!",
+		"{count, plural, =0 {No items} one {# item} other {# items}}",
+		"{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}",
+		"{gender, select, female {{n, plural, one {her # cat} other {her # cats}}} other {{n, number}}}",
+		"It''s '{'quoted'}' text",
+		"{a, number, percent} {b, date, long} {c, time, short}",
+	}
+
+	for _, message := range messages {
+		pattern, err := ToFluent(message)
+		require.NoError(t, err, message)
+		actual, err := FromFluent(pattern)
+		require.NoError(t, err, message)
+		require.Equal(t, message, actual)
+	}
+}
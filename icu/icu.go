@@ -0,0 +1,372 @@
+// Package icu converts ICU MessageFormat messages to and from Fluent patterns.
+package icu
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/michalnicp/fluent-go/internal/ident"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// ToFluent converts an ICU MessageFormat message, e.g. "{count, plural, one
+// {# item} other {# items}}", to a Fluent pattern.
+//
+// Arguments become variables, with numbered arguments such as {0} becoming
+// $arg0. Plural, selectordinal and select arguments become select
+// expressions, with the other variant as the default, and # becomes
+// NUMBER($count), where $count is the plural argument. Number, date and time
+// arguments become calls of NUMBER and DATETIME with the options of their
+// style, e.g. {n, number, percent} becomes NUMBER($n, style: "percent").
+//
+// Constructs without an equivalent in Fluent, such as plural offsets, choice
+// arguments and number patterns, are reported in the error.
+func ToFluent(message string) (syntax.Pattern, error) {
+	p := parser{input: message}
+	elements, err := p.message("")
+	if err != nil {
+		return syntax.Pattern{}, err
+	}
+	if p.pos < len(p.input) {
+		return syntax.Pattern{}, p.errorf("unexpected }")
+	}
+	return syntax.Pattern{Elements: elements}, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// peek returns the byte at the position, or 0 at the end of the input.
+func (p *parser) peek() byte {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+// message parses a message up to the "}" ending it or the end of the input.
+// In the message of a plural argument, plural is the name of its variable,
+// which # is replaced by.
+func (p *parser) message(plural string) ([]syntax.PatternElement, error) {
+	var (
+		elements []syntax.PatternElement
+		text     strings.Builder
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			elements = append(elements, syntax.TextElement{Value: text.String()})
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.input) {
+		switch c := p.input[p.pos]; {
+		case c == '\'':
+			text.WriteString(p.quoted(plural != ""))
+		case c == '{':
+			flush()
+			element, err := p.argument()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+		case c == '}':
+			flush()
+			return elements, nil
+		case c == '#' && plural != "":
+			flush()
+			p.pos++
+			elements = append(elements, syntax.Placeable{Expr: number(plural)})
+		default:
+			_, size := utf8.DecodeRuneInString(p.input[p.pos:])
+			text.WriteString(p.input[p.pos : p.pos+size])
+			p.pos += size
+		}
+	}
+	flush()
+	return elements, nil
+}
+
+// quoted parses an apostrophe, which is written as two apostrophes, or starts
+// quoted text if it is followed by a special character.
+func (p *parser) quoted(inPlural bool) string {
+	p.pos++ // skip '
+	switch c := p.peek(); {
+	case c == '\'':
+		p.pos++
+		return "'"
+	case c == '{' || c == '}' || c == '|' || c == '#' && inPlural:
+	default:
+		return "'"
+	}
+
+	// Quoted text ends at the next single apostrophe, or the end of the
+	// input.
+	var text strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		if c != '\'' {
+			text.WriteByte(c)
+			continue
+		}
+		if p.peek() != '\'' {
+			break
+		}
+		text.WriteByte('\'')
+		p.pos++
+	}
+	return text.String()
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+// word parses a name, keyword or number.
+func (p *parser) word() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		if unicode.IsSpace(r) || strings.ContainsRune(",{}'#", r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *parser) expect(c byte) error {
+	p.skipSpace()
+	if p.peek() != c {
+		if p.pos == len(p.input) {
+			return p.errorf("expected %q, got end of message", c)
+		}
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+var numberRegexp = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// argument parses an argument, e.g. "{name}" or "{n, plural, ...}".
+func (p *parser) argument() (syntax.PatternElement, error) {
+	p.pos++ // skip {
+	p.skipSpace()
+	start := p.pos
+	name := p.word()
+	switch {
+	case name == "":
+		return nil, p.errorf("expected an argument name")
+	case strings.Trim(name, "0123456789") == "":
+		name = "arg" + name
+	case !ident.Valid(name):
+		p.pos = start
+		return nil, p.errorf("argument name %q is not a valid Fluent identifier", name)
+	}
+
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return syntax.Placeable{Expr: variable(name)}, nil
+	}
+	if err := p.expect(','); err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	start = p.pos
+	kind := p.word()
+	switch kind {
+	case "plural", "selectordinal", "select":
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+		expr, err := p.selectExpression(name, kind)
+		if err != nil {
+			return nil, err
+		}
+		return syntax.Placeable{Expr: expr}, nil
+	case "number", "date", "time":
+	default:
+		p.pos = start
+		return nil, p.errorf("%s arguments cannot be expressed in Fluent", kind)
+	}
+
+	p.skipSpace()
+	var style string
+	if p.peek() == ',' {
+		p.pos++
+		start = p.pos
+		style = strings.TrimSpace(p.style())
+	}
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+
+	expr, ok := formatted(name, kind, style)
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("%s style %q cannot be expressed in Fluent", kind, style)
+	}
+	return syntax.Placeable{Expr: expr}, nil
+}
+
+// style parses the style of an argument, up to the "}" ending the argument.
+func (p *parser) style() string {
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case '\'':
+			if end := strings.IndexByte(p.input[p.pos+1:], '\''); end >= 0 {
+				p.pos += end + 1
+			}
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return p.input[start:p.pos]
+			}
+			depth--
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// selectExpression parses the variants of a plural, selectordinal or select
+// argument.
+func (p *parser) selectExpression(name, kind string) (syntax.SelectExpression, error) {
+	expr := syntax.SelectExpression{Selector: variable(name)}
+	if kind == "selectordinal" {
+		expr.Selector = number(name, option("type", syntax.StringLiteral{Value: "ordinal"}))
+	}
+
+	// # is only replaced in the messages of plural arguments, not in those
+	// of select arguments nested in them.
+	plural := ""
+	if kind != "select" {
+		plural = name
+	}
+
+	hasOther := false
+	for {
+		p.skipSpace()
+		switch p.peek() {
+		case '}':
+			p.pos++
+			if !hasOther {
+				return expr, p.errorf("missing other variant of %s", name)
+			}
+			return expr, nil
+		case 0:
+			return expr, p.errorf("expected \"}\", got end of message")
+		}
+
+		start := p.pos
+		var key syntax.VariantKey
+		switch word := p.word(); {
+		case strings.HasPrefix(word, "offset:"):
+			p.pos = start
+			return expr, p.errorf("plural offsets cannot be expressed in Fluent")
+		case strings.HasPrefix(word, "=") && numberRegexp.MatchString(word[1:]):
+			key = syntax.NumberLiteral{Value: word[1:]}
+		case ident.Valid(word):
+			key = syntax.Identifier{Name: word}
+		default:
+			p.pos = start
+			return expr, p.errorf("invalid variant key %q", word)
+		}
+
+		if err := p.expect('{'); err != nil {
+			return expr, err
+		}
+		elements, err := p.message(plural)
+		if err != nil {
+			return expr, err
+		}
+		if err := p.expect('}'); err != nil {
+			return expr, err
+		}
+		if len(elements) == 0 {
+			elements = syntax.TextPattern("").Elements
+		}
+
+		other := key == syntax.Identifier{Name: "other"}
+		hasOther = hasOther || other
+		expr.Variants = append(expr.Variants, syntax.Variant{
+			Key:     key,
+			Value:   syntax.Pattern{Elements: elements},
+			Default: other,
+		})
+	}
+}
+
+func variable(name string) syntax.VariableReference {
+	return syntax.VariableReference{ID: syntax.Identifier{Name: name}}
+}
+
+// number returns NUMBER($name).
+func number(name string, options ...syntax.NamedArgument) syntax.FunctionReference {
+	return call("NUMBER", name, options...)
+}
+
+func call(function, name string, options ...syntax.NamedArgument) syntax.FunctionReference {
+	return syntax.FunctionReference{
+		ID: syntax.Identifier{Name: function},
+		Arguments: syntax.CallArguments{
+			Positional: []syntax.InlineExpression{variable(name)},
+			Named:      options,
+		},
+	}
+}
+
+func option(name string, value syntax.InlineExpression) syntax.NamedArgument {
+	return syntax.NamedArgument{Name: syntax.Identifier{Name: name}, Value: value}
+}
+
+// dateTimeStyles are the styles of date and time arguments, which are also
+// the values of the dateStyle and timeStyle options of DATETIME.
+var dateTimeStyles = map[string]bool{"short": true, "medium": true, "long": true, "full": true}
+
+// formatted returns the function call formatting a number, date or time
+// argument with the style, and whether the style can be expressed.
+func formatted(name, kind, style string) (syntax.FunctionReference, bool) {
+	switch kind {
+	case "number":
+		switch style {
+		case "":
+			return number(name), true
+		case "integer":
+			return number(name, option("maximumFractionDigits", syntax.NumberLiteral{Value: "0"})), true
+		case "percent":
+			return number(name, option("style", syntax.StringLiteral{Value: "percent"})), true
+		}
+	case "date", "time":
+		if style == "" {
+			// The default style of ICU.
+			style = "medium"
+		}
+		if dateTimeStyles[style] {
+			return call("DATETIME", name, option(kind+"Style", syntax.StringLiteral{Value: style})), true
+		}
+	}
+	return syntax.FunctionReference{}, false
+}
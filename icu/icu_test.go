@@ -0,0 +1,90 @@
+package icu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestToFluent(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected string
+	}{
+		{
+			name:     "text",
+			message:  "Hello, world!",
+			expected: "Hello, world!",
+		},
+		{
+			name:     "arguments",
+			message:  "Hello, {name}! You are {0}.",
+			expected: "Hello, { $name }! You are { $arg0 }.",
+		},
+		{
+			name:     "apostrophes",
+			message:  "It''s '{literal}' and don't '#' '{'''",
+			expected: "It's { \"{\" }literal{ \"}\" } and don't '#' { \"{\" }'",
+		},
+		{
+			name:     "plural",
+			message:  "{count, plural, =0 {No items} one {# item} other {# items with '#'}}",
+			expected: "{ $count ->\n    [0] No items\n    [one] { NUMBER($count) } item\n   *[other] { NUMBER($count) } items with #\n}",
+		},
+		{
+			name:     "selectordinal",
+			message:  "{n, selectordinal, one {#st} two {#nd} other {#th}}",
+			expected: "{ NUMBER($n, type: \"ordinal\") ->\n    [one] { NUMBER($n) }st\n    [two] { NUMBER($n) }nd\n   *[other] { NUMBER($n) }th\n}",
+		},
+		{
+			name:     "nested select",
+			message:  "{gender, select, female {{n, plural, one {her # cat} other {her # cats}}} other {# {n}}}",
+			expected: "{ $gender ->\n    [female]\n        { $n ->\n            [one] her { NUMBER($n) } cat\n           *[other] her { NUMBER($n) } cats\n        }\n   *[other] # { $n }\n}",
+		},
+		{
+			name:     "empty variant",
+			message:  "{n, plural, one {} other {many}}",
+			expected: "{ $n ->\n    [one] { \"\" }\n   *[other] many\n}",
+		},
+		{
+			name:     "number and date",
+			message:  "{a, number} {b, number, integer} {c,number,percent} {d, date} {e, date, long} {f, time, short}",
+			expected: "{ NUMBER($a) } { NUMBER($b, maximumFractionDigits: 0) } { NUMBER($c, style: \"percent\") } { DATETIME($d, dateStyle: \"medium\") } { DATETIME($e, dateStyle: \"long\") } { DATETIME($f, timeStyle: \"short\") }",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := ToFluent(tt.message)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, syntax.FormatPattern(pattern))
+
+			_, err = syntax.ParsePattern(syntax.FormatPattern(pattern))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestToFluentErrors(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected string
+	}{
+		{"{count, plural, offset:1 one {#} other {#}}", "offset 16: plural offsets cannot be expressed in Fluent"},
+		{"{n, choice, 0#none|1#one}", "offset 4: choice arguments cannot be expressed in Fluent"},
+		{"{n, number, #,##0.00}", "offset 11: number style \"#,##0.00\" cannot be expressed in Fluent"},
+		{"{n, plural, one {#}}", "offset 20: missing other variant of n"},
+		{"{user.name}", "offset 1: argument name \"user.name\" is not a valid Fluent identifier"},
+		{"{n, plural, one {#} other {#}", "offset 29: expected \"}\", got end of message"},
+		{"a}", "offset 1: unexpected }"},
+		{"{}", "offset 1: expected an argument name"},
+	}
+
+	for _, tt := range tests {
+		_, err := ToFluent(tt.message)
+		require.EqualError(t, err, tt.expected, tt.message)
+	}
+}
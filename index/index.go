@@ -0,0 +1,307 @@
+// Package index indexes the definitions of messages, terms and attributes in
+// many resources, e.g. the files of several locales, and the references to
+// them, so that renames, unused message detection, dependency graphs and
+// editor features do not have to walk the syntax trees themselves.
+package index
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/ident"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Kind is the kind of a symbol.
+type Kind int
+
+// Kinds of symbols.
+const (
+	Message Kind = iota
+	Term
+)
+
+// A Symbol is a message or term, or one of their attributes.
+type Symbol struct {
+	Kind Kind
+
+	// ID is the ID of the message or term, without the "-" of terms.
+	ID string
+
+	// Attribute is the name of the attribute, or "" for the message or term
+	// itself.
+	Attribute string
+}
+
+// Parent returns the message or term of an attribute, or the symbol itself.
+func (s Symbol) Parent() Symbol {
+	return Symbol{Kind: s.Kind, ID: s.ID}
+}
+
+// String returns the symbol as written in references, e.g. "-brand" or
+// "login.title".
+func (s Symbol) String() string {
+	name := s.ID
+	if s.Kind == Term {
+		name = "-" + name
+	}
+	if s.Attribute != "" {
+		name += "." + s.Attribute
+	}
+	return name
+}
+
+// ParseSymbol parses a symbol as written in references, e.g. "-brand" or
+// "login.title".
+func ParseSymbol(s string) (Symbol, error) {
+	var symbol Symbol
+	name := s
+	if strings.HasPrefix(name, "-") {
+		symbol.Kind = Term
+		name = name[1:]
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		name, symbol.Attribute = name[:i], name[i+1:]
+		if !ident.Valid(symbol.Attribute) {
+			return Symbol{}, fmt.Errorf("invalid message or term %q", s)
+		}
+	}
+	if !ident.Valid(name) {
+		return Symbol{}, fmt.Errorf("invalid message or term %q", s)
+	}
+	symbol.ID = name
+	return symbol, nil
+}
+
+// A Definition is a message, term or attribute in a resource.
+type Definition struct {
+	Symbol
+
+	// Resource is the index of the resource in the resources of the index.
+	Resource int
+
+	// Span is the span of the message, term or attribute, and ID the span of
+	// its identifier, i.e. of the attribute's identifier for attributes.
+	Span syntax.Span
+	ID   syntax.Span
+
+	// Entry is the message or term, which is the parent of attributes.
+	Entry syntax.Entry
+}
+
+// A Reference is a message or term reference in a pattern, e.g. { -brand } or
+// { login.title }.
+type Reference struct {
+	// Symbol is the referenced message, term or attribute.
+	Symbol
+
+	// From is the message, term or attribute whose value contains the
+	// reference, which is its parent for references in attributes.
+	From Symbol
+
+	// Resource is the index of the resource in the resources of the index.
+	Resource int
+
+	// Span is the span of the reference, which includes the arguments of
+	// terms. ID is the span of the identifier of the message or term, without
+	// the "-" of terms, and Attribute that of the attribute, if any.
+	Span      syntax.Span
+	ID        syntax.Span
+	Attribute *syntax.Span
+}
+
+// An Index holds the definitions and references of resources. It is not
+// updated if the resources change.
+type Index struct {
+	definitions map[Symbol][]Definition
+	references  map[Symbol][]Reference
+	from        map[Symbol][]Reference
+}
+
+// New indexes the resources. Definitions and references refer to resources
+// by their index in resources.
+func New(resources ...syntax.Resource) *Index {
+	x := &Index{
+		definitions: make(map[Symbol][]Definition),
+		references:  make(map[Symbol][]Reference),
+		from:        make(map[Symbol][]Reference),
+	}
+	for i, resource := range resources {
+		for _, entry := range resource.Body {
+			switch entry := entry.(type) {
+			case syntax.Message:
+				x.addEntry(i, entry, Message, entry.ID, entry.Span, entry.Value, entry.Attributes)
+			case syntax.Term:
+				x.addEntry(i, entry, Term, entry.ID, entry.Span, &entry.Value, entry.Attributes)
+			}
+		}
+	}
+	return x
+}
+
+func (x *Index) addEntry(resource int, entry syntax.Entry, kind Kind, id syntax.Identifier, span *syntax.Span, value *syntax.Pattern, attributes []syntax.Attribute) {
+	symbol := Symbol{Kind: kind, ID: id.Name}
+	x.define(Definition{Symbol: symbol, Resource: resource, Span: spanOf(span), ID: spanOf(id.Span), Entry: entry})
+	if value != nil {
+		x.addPattern(resource, symbol, *value)
+	}
+
+	for _, attribute := range attributes {
+		symbol := Symbol{Kind: kind, ID: id.Name, Attribute: attribute.ID.Name}
+		x.define(Definition{Symbol: symbol, Resource: resource, Span: spanOf(attribute.Span), ID: spanOf(attribute.ID.Span), Entry: entry})
+		x.addPattern(resource, symbol, attribute.Value)
+	}
+}
+
+func (x *Index) define(def Definition) {
+	x.definitions[def.Symbol] = append(x.definitions[def.Symbol], def)
+}
+
+// addPattern adds the references in the pattern of from, including those
+// nested in call arguments, selectors and variants.
+func (x *Index) addPattern(resource int, from Symbol, pattern syntax.Pattern) {
+	syntax.InspectPattern(pattern, func(expr syntax.Expression) {
+		ref := Reference{From: from, Resource: resource}
+		var attribute *syntax.Identifier
+		switch expr := expr.(type) {
+		case syntax.MessageReference:
+			ref.Symbol = Symbol{Kind: Message, ID: expr.ID.Name}
+			ref.Span, ref.ID, attribute = spanOf(expr.Span), spanOf(expr.ID.Span), expr.Attribute
+		case syntax.TermReference:
+			ref.Symbol = Symbol{Kind: Term, ID: expr.ID.Name}
+			ref.Span, ref.ID, attribute = spanOf(expr.Span), spanOf(expr.ID.Span), expr.Attribute
+		default:
+			return
+		}
+		if attribute != nil {
+			ref.Symbol.Attribute = attribute.Name
+			span := spanOf(attribute.Span)
+			ref.Attribute = &span
+		}
+
+		x.references[ref.Symbol] = append(x.references[ref.Symbol], ref)
+		if ref.Symbol.Attribute != "" {
+			// References to attributes are also references to their parent.
+			parent := ref.Symbol.Parent()
+			x.references[parent] = append(x.references[parent], ref)
+		}
+		x.from[from] = append(x.from[from], ref)
+	})
+}
+
+func spanOf(span *syntax.Span) syntax.Span {
+	if span == nil {
+		return syntax.Span{}
+	}
+	return *span
+}
+
+// Symbols returns the defined messages, terms and attributes, sorted by kind,
+// ID and attribute, with attributes after their parent.
+func (x *Index) Symbols() []Symbol {
+	symbols := make([]Symbol, 0, len(x.definitions))
+	for symbol := range x.definitions {
+		symbols = append(symbols, symbol)
+	}
+	sortSymbols(symbols)
+	return symbols
+}
+
+func sortSymbols(symbols []Symbol) {
+	sort.Slice(symbols, func(i, j int) bool {
+		a, b := symbols[i], symbols[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Attribute < b.Attribute
+	})
+}
+
+// Definitions returns the definitions of a symbol, in the order of the
+// resources and their input. There is one for each locale whose resources
+// define it, and more if it is defined more than once.
+func (x *Index) Definitions(symbol Symbol) []Definition {
+	return x.definitions[symbol]
+}
+
+// Defined reports whether a symbol has a definition.
+func (x *Index) Defined(symbol Symbol) bool {
+	return len(x.definitions[symbol]) > 0
+}
+
+// References returns the references to a symbol, in the order of the
+// resources and their input. The references to a message or term include
+// those to its attributes, e.g. { login.title } for login.
+func (x *Index) References(symbol Symbol) []Reference {
+	return x.references[symbol]
+}
+
+// ReferencesFrom returns the references in the value of a message, term or
+// attribute. The references from a message or term do not include those in
+// its attributes, which are returned for the attributes.
+func (x *Index) ReferencesFrom(symbol Symbol) []Reference {
+	return x.from[symbol]
+}
+
+// Dependencies returns the messages and terms which the value or attributes
+// of a message or term reference, sorted as by Symbols and without
+// attributes, e.g. for a dependency graph.
+func (x *Index) Dependencies(symbol Symbol) []Symbol {
+	symbol = symbol.Parent()
+	seen := make(map[Symbol]bool)
+	var symbols []Symbol
+	add := func(refs []Reference) {
+		for _, ref := range refs {
+			if parent := ref.Symbol.Parent(); !seen[parent] {
+				seen[parent] = true
+				symbols = append(symbols, parent)
+			}
+		}
+	}
+	add(x.from[symbol])
+	for _, def := range x.definitions[symbol] {
+		for _, attribute := range attributes(def.Entry) {
+			add(x.from[Symbol{Kind: symbol.Kind, ID: symbol.ID, Attribute: attribute.ID.Name}])
+		}
+	}
+	sortSymbols(symbols)
+	return symbols
+}
+
+// Unreferenced returns the definitions of the messages and terms of kind
+// which are not referenced by other messages and terms, sorted as by Symbols.
+// References from a message or term to itself, or from its attributes, do
+// not count.
+func (x *Index) Unreferenced(kind Kind) []Definition {
+	var defs []Definition
+	for _, symbol := range x.Symbols() {
+		if symbol.Kind != kind || symbol.Attribute != "" {
+			continue
+		}
+		referenced := false
+		for _, ref := range x.references[symbol] {
+			if ref.From.Parent() != symbol {
+				referenced = true
+				break
+			}
+		}
+		if !referenced {
+			defs = append(defs, x.definitions[symbol]...)
+		}
+	}
+	return defs
+}
+
+func attributes(entry syntax.Entry) []syntax.Attribute {
+	switch entry := entry.(type) {
+	case syntax.Message:
+		return entry.Attributes
+	case syntax.Term:
+		return entry.Attributes
+	}
+	return nil
+}
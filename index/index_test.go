@@ -0,0 +1,147 @@
+package index
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/michalnicp/fluent-go/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, input string) syntax.Resource {
+	t.Helper()
+	resource, err := syntax.Parse([]byte(input))
+	require.NoError(t, err)
+	return resource
+}
+
+func TestIndex(t *testing.T) {
+	en := parse(t, `-brand = { $case ->
+   *[nominative] Firefox
+    [genitive] Firefoxes
+}
+-vendor = Mozilla
+    .short = Moz
+welcome = Welcome to { -brand(case: "genitive") }!
+login = Log in
+    .title = Log in to { -brand }
+login-button = { login.title }
+count = { -vendor.short ->
+   *[other] { NUMBER($n, { welcome }) } { login }
+}
+unused = Unused
+`)
+	deInput := `-brand = Firefox
+welcome = Willkommen bei { -brand }!
+`
+	de := parse(t, deInput)
+	x := New(en, de)
+
+	brand := Symbol{Kind: Term, ID: "brand"}
+	login := Symbol{Kind: Message, ID: "login"}
+	title := Symbol{Kind: Message, ID: "login", Attribute: "title"}
+
+	t.Run("Symbols", func(t *testing.T) {
+		require.Equal(t, []Symbol{
+			{Kind: Message, ID: "count"},
+			{Kind: Message, ID: "login"},
+			{Kind: Message, ID: "login", Attribute: "title"},
+			{Kind: Message, ID: "login-button"},
+			{Kind: Message, ID: "unused"},
+			{Kind: Message, ID: "welcome"},
+			{Kind: Term, ID: "brand"},
+			{Kind: Term, ID: "vendor"},
+			{Kind: Term, ID: "vendor", Attribute: "short"},
+		}, x.Symbols())
+	})
+
+	t.Run("Definitions", func(t *testing.T) {
+		defs := x.Definitions(brand)
+		require.Len(t, defs, 2)
+		require.Equal(t, 0, defs[0].Resource)
+		require.Equal(t, syntax.Span{Start: 1, End: 6}, defs[0].ID)
+		require.Equal(t, 1, defs[1].Resource)
+		require.Equal(t, syntax.Span{Start: 0, End: 16}, defs[1].Span)
+
+		defs = x.Definitions(title)
+		require.Len(t, defs, 1)
+		require.Equal(t, "title", en.Body[3].(syntax.Message).Attributes[0].ID.Name)
+		require.Equal(t, en.Body[3], defs[0].Entry)
+		require.Equal(t, *en.Body[3].(syntax.Message).Attributes[0].ID.Span, defs[0].ID)
+
+		require.True(t, x.Defined(login))
+		require.False(t, x.Defined(Symbol{Kind: Message, ID: "brand"}))
+		require.Empty(t, x.Definitions(Symbol{Kind: Message, ID: "missing"}))
+	})
+
+	t.Run("References", func(t *testing.T) {
+		refs := x.References(brand)
+		require.Len(t, refs, 3)
+		require.Equal(t, Symbol{Kind: Message, ID: "welcome"}, refs[0].From)
+		require.Equal(t, title, refs[1].From)
+		require.Equal(t, 1, refs[2].Resource)
+		require.Equal(t, "-brand", deInput[refs[2].Span.Start:refs[2].Span.End])
+		require.Equal(t, "brand", deInput[refs[2].ID.Start:refs[2].ID.End])
+		require.Nil(t, refs[2].Attribute)
+
+		// References to attributes are also references to the message.
+		refs = x.References(login)
+		require.Len(t, refs, 2)
+		require.Equal(t, title, refs[0].Symbol)
+		require.NotNil(t, refs[0].Attribute)
+		require.Equal(t, login, refs[1].Symbol)
+		require.Equal(t, refs[:1], x.References(title))
+
+		// References in call arguments, selectors and variants.
+		refs = x.ReferencesFrom(Symbol{Kind: Message, ID: "count"})
+		var symbols []string
+		for _, ref := range refs {
+			symbols = append(symbols, ref.Symbol.String())
+		}
+		require.Equal(t, []string{"-vendor.short", "welcome", "login"}, symbols)
+
+		require.Empty(t, x.ReferencesFrom(login))
+		require.Len(t, x.ReferencesFrom(title), 1)
+	})
+
+	t.Run("Dependencies", func(t *testing.T) {
+		require.Equal(t, []Symbol{brand}, x.Dependencies(login))
+		require.Equal(t, []Symbol{login, {Kind: Message, ID: "welcome"}, {Kind: Term, ID: "vendor"}}, x.Dependencies(Symbol{Kind: Message, ID: "count"}))
+		require.Empty(t, x.Dependencies(brand))
+	})
+
+	t.Run("Unreferenced", func(t *testing.T) {
+		var symbols []string
+		for _, def := range x.Unreferenced(Message) {
+			symbols = append(symbols, def.Symbol.String())
+		}
+		require.Equal(t, []string{"count", "login-button", "unused"}, symbols)
+		require.Empty(t, x.Unreferenced(Term))
+	})
+}
+
+func TestSymbolString(t *testing.T) {
+	tests := []struct {
+		symbol Symbol
+		want   string
+	}{
+		{Symbol{Kind: Message, ID: "hello"}, "hello"},
+		{Symbol{Kind: Message, ID: "login", Attribute: "title"}, "login.title"},
+		{Symbol{Kind: Term, ID: "brand"}, "-brand"},
+		{Symbol{Kind: Term, ID: "brand", Attribute: "gender"}, "-brand.gender"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, tt.symbol.String())
+
+		symbol, err := ParseSymbol(tt.want)
+		require.NoError(t, err)
+		require.Equal(t, tt.symbol, symbol)
+	}
+}
+
+func TestParseSymbolInvalid(t *testing.T) {
+	for _, s := range []string{"", "-", "1st", "hello world", "login.", ".title", "login.title.x", "--brand", "$name", "login.-x"} {
+		_, err := ParseSymbol(s)
+		require.EqualError(t, err, fmt.Sprintf("invalid message or term %q", s), s)
+	}
+}
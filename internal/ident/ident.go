@@ -0,0 +1,79 @@
+// Package ident checks Fluent identifiers and derives message IDs from the keys
+// and source texts of other localization formats.
+package ident
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Valid reports whether s is a valid identifier, which starts with a letter
+// followed by letters, digits, "_" and "-".
+func Valid(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range []byte(s) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '_' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// FromKey returns the ID of a key, with the characters which are not allowed
+// in IDs replaced by "-", and "msg-" prepended if it does not start with a
+// letter.
+func FromKey(key string) string {
+	id := strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '-'
+	}, key)
+	if id == "" || !('a' <= id[0] && id[0] <= 'z' || 'A' <= id[0] && id[0] <= 'Z') {
+		return "msg-" + id
+	}
+	return id
+}
+
+// Slug returns an ID for text, made of its lowercase ASCII letters and digits
+// with dashes for the other characters, shortened to about 40 characters.
+func Slug(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if 'a' <= r && r <= 'z' || '0' <= r && r <= '9' {
+			if dash && b.Len() > 0 {
+				if b.Len() >= 40 {
+					break
+				}
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	id := b.String()
+	if id == "" || id[0] < 'a' {
+		id = "msg-" + id
+	}
+	return strings.TrimSuffix(id, "-")
+}
+
+// Unique returns id, or id with the lowest number appended which makes it
+// unique, and adds it to used.
+func Unique(used map[string]bool, id string) string {
+	unique := id
+	for n := 2; used[unique]; n++ {
+		unique = id + "-" + strconv.Itoa(n)
+	}
+	used[unique] = true
+	return unique
+}
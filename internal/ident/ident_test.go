@@ -0,0 +1,37 @@
+package ident
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromKey(t *testing.T) {
+	require.Equal(t, "home_title-x", FromKey("home_title.x"))
+	require.Equal(t, "msg-1st", FromKey("1st"))
+	require.Equal(t, "msg-", FromKey(""))
+}
+
+func TestSlug(t *testing.T) {
+	require.Equal(t, "open-file", Slug("Open %@ file…"))
+	require.Equal(t, "msg-404-not-found", Slug("404: Not found!"))
+	require.Equal(t, "msg", Slug("!!!"))
+	require.Equal(t, "a-very-long-text-which-is-shortened-to-about", Slug("A very long text, which is shortened to about forty characters"))
+}
+
+func TestUnique(t *testing.T) {
+	used := make(map[string]bool)
+	require.Equal(t, "a", Unique(used, "a"))
+	require.Equal(t, "a-2", Unique(used, "a"))
+	require.Equal(t, "a-3", Unique(used, "a"))
+	require.Equal(t, map[string]bool{"a": true, "a-2": true, "a-3": true}, used)
+}
+
+func TestValid(t *testing.T) {
+	for _, s := range []string{"a", "home_title", "Brand-name2"} {
+		require.True(t, Valid(s), s)
+	}
+	for _, s := range []string{"", "1st", "_hidden", "-brand", "a.b", "café"} {
+		require.False(t, Valid(s), s)
+	}
+}
@@ -0,0 +1,247 @@
+// Package printf converts between Fluent patterns and the printf style
+// strings with positional placeholders, e.g. %1$s, of Android and Apple
+// string resources.
+package printf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Number returns a call of NUMBER on a variable.
+func Number(variable syntax.VariableReference, options ...syntax.NamedArgument) syntax.FunctionReference {
+	return syntax.FunctionReference{
+		ID: syntax.Identifier{Name: "NUMBER"},
+		Arguments: syntax.CallArguments{
+			Positional: []syntax.InlineExpression{variable},
+			Named:      options,
+		},
+	}
+}
+
+// References holds the messages and terms of a resource, whose values replace
+// references to them.
+type References struct {
+	Messages map[string]syntax.Message
+	Terms    map[string]syntax.Term
+}
+
+// NewReferences returns the messages and terms of a resource.
+func NewReferences(resource syntax.Resource) *References {
+	refs := &References{
+		Messages: make(map[string]syntax.Message),
+		Terms:    make(map[string]syntax.Term),
+	}
+	for _, entry := range resource.Body {
+		switch entry := entry.(type) {
+		case syntax.Message:
+			refs.Messages[entry.ID.Name] = entry
+		case syntax.Term:
+			refs.Terms[entry.ID.Name] = entry
+		}
+	}
+	return refs
+}
+
+// A Formatter formats patterns whose placeholders share their positions, e.g.
+// the patterns of a resource.
+type Formatter struct {
+	*References
+
+	// Conversion returns the conversion of the placeholder of a variable,
+	// e.g. "s" for %1$s.
+	Conversion func(variable string) string
+
+	// Integer is the conversion of calls of NUMBER without options, e.g. "d"
+	// for %1$d.
+	Integer string
+
+	// SelectError is the error for select expressions, which the formatter
+	// does not convert.
+	SelectError string
+
+	positions map[string]int
+	next      int
+	err       error
+}
+
+// NewFormatter returns a formatter numbering the variables in the order of
+// variables, and the other variables in the order in which they first appear.
+func NewFormatter(refs *References, variables []string) *Formatter {
+	f := &Formatter{References: refs, positions: make(map[string]int)}
+	for i, variable := range variables {
+		f.positions[variable] = i + 1
+	}
+	f.next = len(variables) + 1
+	return f
+}
+
+// Err returns the first error of the patterns formatted, e.g. because they
+// call functions other than NUMBER.
+func (f *Formatter) Err() error {
+	return f.err
+}
+
+// Part is text or, if Placeholder is set, a placeholder.
+type Part struct {
+	Text        string
+	Placeholder bool
+}
+
+// Join joins parts, with % escaped if there are placeholders.
+func Join(parts []Part) string {
+	formatted := false
+	for _, p := range parts {
+		formatted = formatted || p.Placeholder
+	}
+
+	var b strings.Builder
+	for _, p := range parts {
+		if formatted && !p.Placeholder {
+			b.WriteString(strings.Replace(p.Text, "%", "%%", -1))
+		} else {
+			b.WriteString(p.Text)
+		}
+	}
+	return b.String()
+}
+
+// maxDepth limits the depth of references replaced by their values, which
+// may be cyclic.
+const maxDepth = 10
+
+// Parts returns the parts of a pattern. depth is the depth of the references
+// whose value the pattern is.
+func (f *Formatter) Parts(pattern syntax.Pattern, depth int) []Part {
+	var parts []Part
+	for _, element := range pattern.Elements {
+		switch element := element.(type) {
+		case syntax.TextElement:
+			parts = append(parts, Part{Text: element.Value})
+		case syntax.Placeable:
+			parts = append(parts, f.Expression(element.Expr, depth)...)
+		}
+	}
+	return parts
+}
+
+// Expression returns the parts of an expression.
+func (f *Formatter) Expression(expr syntax.Expression, depth int) []Part {
+	switch expr := expr.(type) {
+	case syntax.StringLiteral:
+		return []Part{{Text: expr.Unescape()}}
+	case syntax.NumberLiteral:
+		return []Part{{Text: expr.Value}}
+	case syntax.VariableReference:
+		return []Part{{Text: "%" + f.Position(expr.ID.Name) + "$" + f.Conversion(expr.ID.Name), Placeholder: true}}
+	case syntax.FunctionReference:
+		return f.function(expr)
+	case syntax.Placeable:
+		return f.Expression(expr.Expr, depth)
+	case syntax.MessageReference:
+		message, ok := f.Messages[expr.ID.Name]
+		var value *syntax.Pattern
+		if ok && expr.Attribute == nil {
+			value = message.Value
+		}
+		if ok && expr.Attribute != nil {
+			for i, attribute := range message.Attributes {
+				if attribute.ID.Name == expr.Attribute.Name {
+					value = &message.Attributes[i].Value
+				}
+			}
+		}
+		return f.reference(expr.ID.Name, value, depth)
+	case syntax.TermReference:
+		if expr.Arguments != nil {
+			f.Fail("reference to -%s with arguments cannot be converted", expr.ID.Name)
+			return nil
+		}
+		term, ok := f.Terms[expr.ID.Name]
+		var value *syntax.Pattern
+		if ok && expr.Attribute == nil {
+			value = &term.Value
+		}
+		return f.reference("-"+expr.ID.Name, value, depth)
+	default:
+		f.Fail("%s", f.SelectError)
+		return nil
+	}
+}
+
+// reference returns the parts of the value of a reference, which cannot have
+// placeholders.
+func (f *Formatter) reference(name string, value *syntax.Pattern, depth int) []Part {
+	if value == nil {
+		f.Fail("unknown reference %s", name)
+		return nil
+	}
+	if depth == maxDepth {
+		f.Fail("reference %s is cyclic", name)
+		return nil
+	}
+	parts := f.Parts(*value, depth+1)
+	for _, p := range parts {
+		if p.Placeholder {
+			f.Fail("reference %s has variables", name)
+			return nil
+		}
+	}
+	return parts
+}
+
+func (f *Formatter) function(call syntax.FunctionReference) []Part {
+	variable, ok := OnlyVariable(call)
+	if !ok || call.ID.Name != "NUMBER" {
+		f.Fail("%s cannot be converted", call.ID.Name)
+		return nil
+	}
+
+	options := make(map[string]string)
+	for _, option := range call.Arguments.Named {
+		if value, ok := option.Value.(syntax.NumberLiteral); ok {
+			options[option.Name.Name] = value.Value
+		}
+	}
+	conversion := f.Integer
+	switch {
+	case len(call.Arguments.Named) == 0:
+	case len(call.Arguments.Named) == 2 && options["minimumFractionDigits"] != "" && options["minimumFractionDigits"] == options["maximumFractionDigits"]:
+		conversion = "." + options["minimumFractionDigits"] + "f"
+	default:
+		f.Fail("options of NUMBER other than equal minimumFractionDigits and maximumFractionDigits cannot be converted")
+		return nil
+	}
+	return []Part{{Text: "%" + f.Position(variable.ID.Name) + "$" + conversion, Placeholder: true}}
+}
+
+// OnlyVariable returns the argument of a call with a variable as the only
+// positional argument, and whether it has one.
+func OnlyVariable(call syntax.FunctionReference) (syntax.VariableReference, bool) {
+	if len(call.Arguments.Positional) != 1 {
+		return syntax.VariableReference{}, false
+	}
+	variable, ok := call.Arguments.Positional[0].(syntax.VariableReference)
+	return variable, ok
+}
+
+// Position returns the position of the placeholder of a variable.
+func (f *Formatter) Position(name string) string {
+	position, ok := f.positions[name]
+	if !ok {
+		position = f.next
+		f.positions[name] = position
+		f.next++
+	}
+	return strconv.Itoa(position)
+}
+
+// Fail sets the error of the formatter, unless it already has one.
+func (f *Formatter) Fail(format string, args ...interface{}) {
+	if f.err == nil {
+		f.err = fmt.Errorf(format, args...)
+	}
+}
@@ -0,0 +1,93 @@
+package printf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestFormatter(t *testing.T) {
+	resource, err := syntax.Parse([]byte(`-brand = Firefox
+about = About { -brand }
+cycle = { cycle }
+greeting = Hello, { $name }
+`))
+	require.NoError(t, err)
+	refs := NewReferences(resource)
+
+	tests := []struct {
+		name      string
+		variables []string
+		pattern   string
+		expected  string
+		err       string
+	}{
+		{
+			name:     "text",
+			pattern:  `100% { "{" }`,
+			expected: "100% {",
+		},
+		{
+			name:      "variables",
+			variables: []string{"count"},
+			pattern:   `{ $name } has { NUMBER($count) } files, { NUMBER($size, minimumFractionDigits: 2, maximumFractionDigits: 2) } MB, 100%`,
+			expected:  "%2$s has %1$d files, %3$.2f MB, 100%%",
+		},
+		{
+			name:     "references",
+			pattern:  `{ about } { 1 }`,
+			expected: "About Firefox 1",
+		},
+		{
+			name:    "cyclic reference",
+			pattern: `{ cycle }`,
+			err:     "reference cycle is cyclic",
+		},
+		{
+			name:    "reference with variables",
+			pattern: `{ greeting }`,
+			err:     "reference greeting has variables",
+		},
+		{
+			name:    "unknown reference",
+			pattern: `{ -vendor }`,
+			err:     "unknown reference -vendor",
+		},
+		{
+			name:    "function",
+			pattern: `{ DATETIME($date) }`,
+			err:     "DATETIME cannot be converted",
+		},
+		{
+			name:    "options",
+			pattern: `{ NUMBER($n, style: "percent") }`,
+			err:     "options of NUMBER other than equal minimumFractionDigits and maximumFractionDigits cannot be converted",
+		},
+		{
+			name:    "select",
+			pattern: "{ $n ->\n   *[other] Other\n}",
+			err:     "select expressions cannot be converted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := syntax.ParsePattern(tt.pattern)
+			require.NoError(t, err)
+
+			f := NewFormatter(refs, tt.variables)
+			f.Conversion = func(string) string { return "s" }
+			f.Integer = "d"
+			f.SelectError = "select expressions cannot be converted"
+			formatted := Join(f.Parts(pattern, 0))
+			if tt.err != "" {
+				require.EqualError(t, f.Err(), tt.err)
+				return
+			}
+			require.NoError(t, f.Err())
+			require.Equal(t, tt.expected, formatted)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package segment
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// A Message is a message or term rebuilt by a Builder.
+type Message struct {
+	// ID is the message ID, e.g. "hello", or the term ID, e.g. "-brand".
+	ID string
+
+	Value      *syntax.Pattern
+	Attributes []syntax.Attribute
+	Comment    *syntax.Comment
+
+	// Skip leaves the message or term out of the resource, e.g. because
+	// one of its patterns is not translated.
+	Skip bool
+}
+
+// SetPattern sets the value of the message, or the attribute if it is not
+// empty.
+func (m *Message) SetPattern(attribute string, pattern syntax.Pattern) {
+	if attribute == "" {
+		m.Value = &pattern
+		return
+	}
+	m.Attributes = append(m.Attributes, syntax.Attribute{
+		ID:    syntax.Identifier{Name: attribute},
+		Value: pattern,
+	})
+}
+
+// A Builder rebuilds the messages and terms of a resource from their
+// patterns, e.g. when importing translations. The zero value is ready to use.
+type Builder struct {
+	messages []*Message
+	byID     map[string]*Message
+}
+
+// Message returns the message or term with id, which is added after the
+// others if it does not exist yet.
+func (b *Builder) Message(id string) *Message {
+	if b.byID == nil {
+		b.byID = make(map[string]*Message)
+	}
+	m := b.byID[id]
+	if m == nil {
+		m = &Message{ID: id}
+		b.messages = append(b.messages, m)
+		b.byID[id] = m
+	}
+	return m
+}
+
+// Resource returns a resource of the messages and terms which are not
+// skipped, in the order in which they were added, with prefix prepended to
+// their IDs. Terms without a value are reported in the error, which joins an
+// error for each of them, and left out.
+func (b *Builder) Resource(prefix string) (syntax.Resource, error) {
+	resource := syntax.Resource{Body: make([]syntax.Entry, 0)}
+	var errs []error
+	for _, m := range b.messages {
+		if m.Skip {
+			continue
+		}
+		attributes := m.Attributes
+		if attributes == nil {
+			attributes = make([]syntax.Attribute, 0)
+		}
+
+		if strings.HasPrefix(m.ID, "-") {
+			if m.Value == nil {
+				errs = append(errs, fmt.Errorf("%s: missing value of term", m.ID))
+				continue
+			}
+			resource.Body = append(resource.Body, syntax.Term{
+				ID:         syntax.Identifier{Name: prefix + m.ID[1:]},
+				Value:      *m.Value,
+				Attributes: attributes,
+				Comment:    m.Comment,
+			})
+			continue
+		}
+		resource.Body = append(resource.Body, syntax.Message{
+			ID:         syntax.Identifier{Name: prefix + m.ID},
+			Value:      m.Value,
+			Attributes: attributes,
+			Comment:    m.Comment,
+		})
+	}
+	return resource, errors.Join(errs...)
+}
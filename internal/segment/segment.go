@@ -0,0 +1,150 @@
+// Package segment splits the messages and terms of Fluent resources into the
+// patterns and segments which translation formats such as XLIFF, TMX and
+// spreadsheets align between locales.
+package segment
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/ident"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// A Pattern is the value or an attribute of a message or term.
+type Pattern struct {
+	// ID is the message ID, e.g. "hello" or "-brand", or the ID and the
+	// attribute name, e.g. "hello.title".
+	ID string
+
+	Pattern syntax.Pattern
+
+	// Comment is the comment of the message or term.
+	Comment *syntax.Comment
+}
+
+// SplitID returns the message or term ID and the attribute name of the ID of
+// a pattern, e.g. "hello" and "title" for "hello.title", and whether it is
+// one.
+func SplitID(id string) (entry, attribute string, ok bool) {
+	entry = id
+	if i := strings.IndexByte(id, '.'); i >= 0 {
+		entry, attribute = id[:i], id[i+1:]
+		if !ident.Valid(attribute) {
+			return "", "", false
+		}
+	}
+	if !ident.Valid(strings.TrimPrefix(entry, "-")) {
+		return "", "", false
+	}
+	return entry, attribute, true
+}
+
+// Patterns returns the value and attributes of a message or term.
+func Patterns(entry syntax.Entry) []Pattern {
+	var (
+		id         string
+		value      *syntax.Pattern
+		attributes []syntax.Attribute
+		comment    *syntax.Comment
+	)
+	switch entry := entry.(type) {
+	case syntax.Message:
+		id, value, attributes, comment = entry.ID.Name, entry.Value, entry.Attributes, entry.Comment
+	case syntax.Term:
+		id, value, attributes, comment = "-"+entry.ID.Name, &entry.Value, entry.Attributes, entry.Comment
+	default:
+		return nil
+	}
+
+	var result []Pattern
+	if value != nil {
+		result = append(result, Pattern{ID: id, Pattern: *value, Comment: comment})
+	}
+	for _, attribute := range attributes {
+		result = append(result, Pattern{ID: id + "." + attribute.ID.Name, Pattern: attribute.Value, Comment: comment})
+	}
+	return result
+}
+
+// ByID returns the patterns of the messages and terms of a resource by their
+// ID, which is empty if the resource is nil.
+func ByID(resource *syntax.Resource) map[string]syntax.Pattern {
+	patterns := make(map[string]syntax.Pattern)
+	if resource == nil {
+		return patterns
+	}
+	for _, entry := range resource.Body {
+		for _, p := range Patterns(entry) {
+			patterns[p.ID] = p.Pattern
+		}
+	}
+	return patterns
+}
+
+// MapRuns returns pattern with each run of text and placeables other than
+// select expressions replaced by the elements returned by f. Runs are
+// identified by prefix and the index of their first element, after the
+// indexes of the select expressions and the keys of the variants they are in,
+// e.g. "0" or "2:one:0".
+func MapRuns(pattern syntax.Pattern, prefix string, f func(id string, elements []syntax.PatternElement) []syntax.PatternElement) syntax.Pattern {
+	var (
+		elements []syntax.PatternElement
+		run      []syntax.PatternElement
+		start    int
+	)
+	flush := func() {
+		if len(run) > 0 {
+			elements = append(elements, f(prefix+strconv.Itoa(start), run)...)
+			run = nil
+		}
+	}
+
+	for i, element := range pattern.Elements {
+		if placeable, ok := element.(syntax.Placeable); ok {
+			if expr, ok := placeable.Expr.(syntax.SelectExpression); ok {
+				flush()
+				variants := make([]syntax.Variant, len(expr.Variants))
+				for j, variant := range expr.Variants {
+					variant.Value = MapRuns(variant.Value, prefix+strconv.Itoa(i)+":"+VariantKey(variant.Key)+":", f)
+					variants[j] = variant
+				}
+				expr.Variants = variants
+				placeable.Expr = expr
+				elements = append(elements, placeable)
+				continue
+			}
+		}
+		if len(run) == 0 {
+			start = i
+		}
+		run = append(run, element)
+	}
+	flush()
+
+	pattern.Elements = elements
+	return pattern
+}
+
+// Runs returns the runs of a pattern by their ID, as passed to the function
+// of MapRuns, e.g. to align the runs of a translation with those of the
+// source.
+func Runs(pattern syntax.Pattern) map[string][]syntax.PatternElement {
+	runs := make(map[string][]syntax.PatternElement)
+	MapRuns(pattern, "", func(id string, elements []syntax.PatternElement) []syntax.PatternElement {
+		runs[id] = elements
+		return elements
+	})
+	return runs
+}
+
+// VariantKey returns the name or number of a variant key.
+func VariantKey(key syntax.VariantKey) string {
+	switch key := key.(type) {
+	case syntax.Identifier:
+		return key.Name
+	case syntax.NumberLiteral:
+		return key.Value
+	}
+	return ""
+}
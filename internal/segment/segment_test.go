@@ -0,0 +1,115 @@
+package segment
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestPatterns(t *testing.T) {
+	resource, err := syntax.Parse([]byte(`# Greeting.
+hello = Hello
+    .title = Title
+-brand = Firefox
+# Standalone
+
+empty =
+    .label = Label
+`))
+	require.NoError(t, err)
+
+	var ids []string
+	for _, entry := range resource.Body {
+		for _, p := range Patterns(entry) {
+			ids = append(ids, p.ID)
+			if p.ID == "hello.title" {
+				require.Equal(t, "Greeting.", p.Comment.Content)
+			}
+		}
+	}
+	require.Equal(t, []string{"hello", "hello.title", "-brand", "empty.label"}, ids)
+
+	byID := ByID(&resource)
+	require.Len(t, byID, 4)
+	require.Equal(t, "Firefox", syntax.FormatPattern(byID["-brand"]))
+	require.Empty(t, ByID(nil))
+}
+
+func TestRuns(t *testing.T) {
+	pattern, err := syntax.ParsePattern("You have { $n ->\n    [0] no { -brand } emails\n   *[other] { $n } emails\n}.")
+	require.NoError(t, err)
+
+	runs := make(map[string]string)
+	for id, elements := range Runs(pattern) {
+		runs[id] = syntax.FormatPattern(syntax.Pattern{Elements: elements})
+	}
+	require.Equal(t, map[string]string{
+		"0":         `You have{ " " }`,
+		"1:0:0":     "no { -brand } emails",
+		"1:other:0": "{ $n } emails",
+		"2":         ".",
+	}, runs)
+
+	upper := MapRuns(pattern, "", func(id string, elements []syntax.PatternElement) []syntax.PatternElement {
+		return []syntax.PatternElement{syntax.TextElement{Value: "<" + id + ">"}}
+	})
+	require.Equal(t, "<0>{ $n ->\n    [0] <1:0:0>\n   *[other] <1:other:0>\n}<2>", syntax.FormatPattern(upper))
+}
+
+func TestSplitID(t *testing.T) {
+	tests := []struct {
+		id, entry, attribute string
+		ok                   bool
+	}{
+		{"hello", "hello", "", true},
+		{"hello.title", "hello", "title", true},
+		{"-brand.gender", "-brand", "gender", true},
+		{"", "", "", false},
+		{"hello.", "", "", false},
+		{"--brand", "", "", false},
+		{"hello.title.x", "", "", false},
+		{"1st", "", "", false},
+	}
+	for _, tt := range tests {
+		entry, attribute, ok := SplitID(tt.id)
+		require.Equal(t, tt.ok, ok, tt.id)
+		require.Equal(t, tt.entry, entry, tt.id)
+		require.Equal(t, tt.attribute, attribute, tt.id)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	pattern := func(text string) syntax.Pattern {
+		return syntax.TextPattern(text)
+	}
+
+	var b Builder
+	b.Message("hello").SetPattern("title", pattern("Title"))
+	b.Message("-brand").SetPattern("gender", pattern("masculine"))
+	b.Message("skipped").Skip = true
+	b.Message("hello").SetPattern("", pattern("Hello"))
+	b.Message("hello").Comment = &syntax.Comment{Content: "Greeting."}
+
+	resource, err := b.Resource("app-")
+	require.EqualError(t, err, "-brand: missing value of term")
+	var buf bytes.Buffer
+	require.NoError(t, syntax.Fprint(&buf, resource))
+	require.Equal(t, `# Greeting.
+app-hello = Hello
+    .title = Title
+`, buf.String())
+}
+
+func TestXMLEscaper(t *testing.T) {
+	var esc XMLEscaper
+	require.Equal(t, "&lt;b&gt; &amp; \"tab\"\t\n&#xD;", esc.Text("<b> & \"tab\"\t\n\r"))
+	require.Equal(t, "&lt;b&gt; &amp; &quot;tab&quot;&#x9;&#xA;&#xD;", esc.Attr("<b> & \"tab\"\t\n\r"))
+	require.Equal(t, "😀�", esc.Text("😀�"))
+	require.NoError(t, esc.Err())
+
+	require.Equal(t, "ab", esc.Text("a\x01b￾"))
+	require.EqualError(t, esc.Err(), "character U+0001 is not allowed in XML")
+}
@@ -0,0 +1,67 @@
+package segment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An XMLEscaper escapes the text and attribute values of XML documents. XML
+// 1.0 does not allow most C0 control characters, e.g. U+0001, not even as
+// character references, so the escaper leaves them out and Err reports the
+// first one.
+type XMLEscaper struct {
+	err error
+}
+
+// Text escapes the text of an element.
+func (e *XMLEscaper) Text(s string) string {
+	return e.escape(s, false)
+}
+
+// Attr escapes an attribute value in double quotes.
+func (e *XMLEscaper) Attr(s string) string {
+	return e.escape(s, true)
+}
+
+// Err returns an error for the first character escaped which is not allowed
+// in XML.
+func (e *XMLEscaper) Err() error {
+	return e.err
+}
+
+func (e *XMLEscaper) escape(s string, attr bool) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '&':
+			b.WriteString("&amp;")
+		case r == '<':
+			b.WriteString("&lt;")
+		case r == '>':
+			b.WriteString("&gt;")
+		case r == '\r':
+			b.WriteString("&#xD;")
+		case attr && r == '"':
+			b.WriteString("&quot;")
+		case attr && r == '\n':
+			b.WriteString("&#xA;")
+		case attr && r == '\t':
+			b.WriteString("&#x9;")
+		case !isXMLChar(r):
+			if e.err == nil {
+				e.err = fmt.Errorf("character %U is not allowed in XML", r)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// isXMLChar reports whether r is in the Char production of XML 1.0.
+func isXMLChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
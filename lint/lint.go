@@ -0,0 +1,419 @@
+// Package lint checks Fluent resources for style problems, which are not
+// syntax errors but make translations harder to maintain.
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Rule is a lint rule. Rules are enabled unless disabled in the Config.
+type Rule struct {
+	Name        string
+	Code        string
+	Description string
+}
+
+// Rules lists all rules.
+var Rules = []Rule{
+	{"id-case", "L0001", "Message and term IDs match the ID pattern, kebab-case by default."},
+	{"id-prefix", "L0002", "Message IDs start with one of the prefixes configured for the file."},
+	{"trailing-whitespace", "L0003", "Text does not end lines with whitespace."},
+	{"leading-whitespace", "L0004", `Values do not start with indentation, which should be written as {" "}.`},
+	{"max-length", "L0005", "Values are not longer than the maximum length."},
+	{"missing-comment", "L0006", "Messages with variables have a comment explaining them."},
+	{"term-in-file", "L0007", "Referenced terms are defined in the same file."},
+}
+
+// Defaults of the Config.
+const (
+	DefaultIDPattern = `^[a-z][a-z0-9]*(-[a-z0-9]+)*$`
+	DefaultMaxLength = 200
+)
+
+// ConfigFile is the name of the project configuration file.
+const ConfigFile = ".fluentlint.json"
+
+// Config configures the rules for a project.
+type Config struct {
+	// Rules enables or disables rules by name.
+	Rules map[string]bool `json:"rules"`
+
+	// IDPattern is the regular expression message and term IDs must match,
+	// without the "-" of terms. Defaults to DefaultIDPattern.
+	IDPattern string `json:"idPattern"`
+
+	// Prefixes maps file patterns, as used by path.Match, to the prefixes
+	// message IDs in matching files must start with. Patterns are matched
+	// against the slash separated path and its base name.
+	Prefixes map[string][]string `json:"prefixes"`
+
+	// MaxLength is the maximum number of characters of text in a value.
+	// Defaults to DefaultMaxLength.
+	MaxLength int `json:"maxLength"`
+}
+
+// ReadConfig reads a Config from a JSON file.
+func ReadConfig(filename string) (Config, error) {
+	var config Config
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return config, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&config); err != nil {
+		return config, fmt.Errorf("read config %s: %v", filename, err)
+	}
+	return config, nil
+}
+
+// Linter checks resources with the rules of a Config.
+type Linter struct {
+	config    Config
+	idPattern *regexp.Regexp
+}
+
+// New returns a Linter for config, or an error if config is invalid.
+func New(config Config) (*Linter, error) {
+	for name := range config.Rules {
+		if findRule(name) == nil {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+	}
+	for pattern := range config.Prefixes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %v", pattern, err)
+		}
+	}
+
+	if config.IDPattern == "" {
+		config.IDPattern = DefaultIDPattern
+	}
+	idPattern, err := regexp.Compile(config.IDPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID pattern: %v", err)
+	}
+
+	if config.MaxLength <= 0 {
+		config.MaxLength = DefaultMaxLength
+	}
+
+	return &Linter{config: config, idPattern: idPattern}, nil
+}
+
+func findRule(name string) *Rule {
+	for i := range Rules {
+		if Rules[i].Name == name {
+			return &Rules[i]
+		}
+	}
+	return nil
+}
+
+// Lint checks resource, which was parsed from input read from file, and
+// returns the problems found as warnings.
+//
+// Rules can be disabled with "fluent-lint-disable" followed by an optional
+// list of rule names in a comment. A comment attached to a message or term
+// disables the rules for that entry, any other comment for the rest of the
+// file:
+//
+//	# fluent-lint-disable max-length, missing-comment
+func (l *Linter) Lint(file string, input []byte, resource syntax.Resource) []syntax.Diagnostic {
+	c := check{
+		Linter:   l,
+		input:    input,
+		terms:    make(map[string]bool),
+		disabled: make(map[string]bool),
+	}
+
+	for _, entry := range resource.Body {
+		if term, ok := entry.(syntax.Term); ok {
+			c.terms[term.ID.Name] = true
+		}
+	}
+	prefixes := l.prefixes(file)
+
+	for _, entry := range resource.Body {
+		switch entry := entry.(type) {
+		case syntax.Comment:
+			c.disable(c.disabled, entry.Content)
+		case syntax.GroupComment:
+			c.disable(c.disabled, entry.Content)
+		case syntax.ResourceComment:
+			c.disable(c.disabled, entry.Content)
+		case syntax.Message:
+			c.entryDisabled = make(map[string]bool)
+			if entry.Comment != nil {
+				c.disable(c.entryDisabled, entry.Comment.Content)
+			}
+			c.checkID(entry.ID, entry.ID.Name, prefixes)
+			if entry.Value != nil {
+				c.checkPattern(entry.ID, *entry.Value)
+			}
+			for _, attribute := range entry.Attributes {
+				c.checkPattern(attribute.ID, attribute.Value)
+			}
+			c.checkComment(entry)
+		case syntax.Term:
+			c.entryDisabled = make(map[string]bool)
+			if entry.Comment != nil {
+				c.disable(c.entryDisabled, entry.Comment.Content)
+			}
+			c.checkID(entry.ID, "-"+entry.ID.Name, nil)
+			c.checkPattern(entry.ID, entry.Value)
+			for _, attribute := range entry.Attributes {
+				c.checkPattern(attribute.ID, attribute.Value)
+			}
+		}
+	}
+
+	return c.diagnostics
+}
+
+// prefixes returns the ID prefixes configured for file.
+func (l *Linter) prefixes(file string) []string {
+	file = filepath.ToSlash(file)
+
+	patterns := make([]string, 0, len(l.config.Prefixes))
+	for pattern := range l.config.Prefixes {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var prefixes []string
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, file); ok {
+			prefixes = append(prefixes, l.config.Prefixes[pattern]...)
+		} else if ok, _ := path.Match(pattern, path.Base(file)); ok {
+			prefixes = append(prefixes, l.config.Prefixes[pattern]...)
+		}
+	}
+	return prefixes
+}
+
+// check holds the state of linting one resource.
+type check struct {
+	*Linter
+	input []byte
+
+	// terms holds the IDs of the terms defined in the resource.
+	terms map[string]bool
+
+	// disabled holds the rules disabled for the rest of the file, and
+	// entryDisabled the rules disabled for the current entry. The empty
+	// name disables all rules.
+	disabled      map[string]bool
+	entryDisabled map[string]bool
+
+	diagnostics []syntax.Diagnostic
+}
+
+const disableDirective = "fluent-lint-disable"
+
+// disable adds the rules disabled by the directive in a comment to disabled.
+func (c *check) disable(disabled map[string]bool, comment string) {
+	for _, line := range strings.Split(comment, "\n") {
+		rules, ok := parseDirective(line)
+		if !ok {
+			continue
+		}
+		if len(rules) == 0 {
+			disabled[""] = true
+		}
+		for _, rule := range rules {
+			disabled[rule] = true
+		}
+	}
+}
+
+// parseDirective returns the rules listed by a disable directive, and whether
+// the line is one.
+func parseDirective(line string) ([]string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, disableDirective) {
+		return nil, false
+	}
+	rest := line[len(disableDirective):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return nil, false
+	}
+	rules := strings.FieldsFunc(rest, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	return rules, true
+}
+
+func (c *check) enabled(name string) bool {
+	if enabled, ok := c.config.Rules[name]; ok && !enabled {
+		return false
+	}
+	return !c.disabled[""] && !c.disabled[name] && !c.entryDisabled[""] && !c.entryDisabled[name]
+}
+
+func (c *check) report(name string, span *syntax.Span, format string, args ...interface{}) {
+	if !c.enabled(name) {
+		return
+	}
+	d := syntax.Diagnostic{
+		Severity: syntax.SeverityWarning,
+		Code:     findRule(name).Code,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if span != nil {
+		d.Span = *span
+	}
+	c.diagnostics = append(c.diagnostics, d)
+}
+
+// checkID checks the ID of a message or term. name is the ID as written in
+// references.
+func (c *check) checkID(id syntax.Identifier, name string, prefixes []string) {
+	if !c.idPattern.MatchString(id.Name) {
+		c.report("id-case", id.Span, "ID \"%s\" does not match the pattern %s", name, c.idPattern)
+	}
+
+	if len(prefixes) == 0 {
+		return
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(id.Name, prefix) {
+			return
+		}
+	}
+	c.report("id-prefix", id.Span, "ID \"%s\" does not start with %s", name, quoteList(prefixes))
+}
+
+// checkPattern checks the value of a message, term or attribute with the ID id.
+func (c *check) checkPattern(id syntax.Identifier, pattern syntax.Pattern) {
+	c.checkTrailingWhitespace(pattern)
+
+	length := 0
+	for i, element := range pattern.Elements {
+		switch element := element.(type) {
+		case syntax.TextElement:
+			length += utf8.RuneCountInString(element.Value)
+			if i == 0 && strings.HasPrefix(element.Value, " ") {
+				c.checkLeadingWhitespace(element)
+			}
+		case syntax.Placeable:
+			length++
+		}
+	}
+
+	if length > c.config.MaxLength {
+		c.report("max-length", id.Span, "Value of \"%s\" is %d characters long, more than %d", id.Name, length, c.config.MaxLength)
+	}
+
+	syntax.InspectPattern(pattern, func(expr syntax.Expression) {
+		if ref, ok := expr.(syntax.TermReference); ok && !c.terms[ref.ID.Name] {
+			c.report("term-in-file", ref.Span, "Term \"-%s\" is not defined in this file", ref.ID.Name)
+		}
+	})
+}
+
+// checkTrailingWhitespace reports whitespace at the end of lines in the source
+// of a pattern, including after its last line, e.g. after a final placeable,
+// which is not part of the pattern.
+func (c *check) checkTrailingWhitespace(pattern syntax.Pattern) {
+	if pattern.Span == nil || pattern.Span.End > len(c.input) {
+		return
+	}
+	start, end := pattern.Span.Start, pattern.Span.End
+	for !isLineEnd(c.input, end) {
+		end++
+	}
+
+	for i := start; i < end; i++ {
+		if c.input[i] != ' ' && c.input[i] != '\t' {
+			continue
+		}
+		j := i
+		for j < end && (c.input[j] == ' ' || c.input[j] == '\t') {
+			j++
+		}
+		if isLineEnd(c.input, j) {
+			c.report("trailing-whitespace", &syntax.Span{Start: i, End: j}, "Trailing whitespace")
+		}
+		i = j
+	}
+}
+
+// checkLeadingWhitespace reports the indentation of a value which starts with
+// whitespace, which happens when its first line is indented more than the
+// following ones.
+func (c *check) checkLeadingWhitespace(element syntax.TextElement) {
+	if element.Span == nil || element.Span.End > len(c.input) {
+		return
+	}
+	// The source is indented by the common indent of all lines, plus the
+	// leading whitespace of the value.
+	end := element.Span.Start
+	for end < element.Span.End && c.input[end] == ' ' {
+		end++
+	}
+	start := end - (len(element.Value) - len(strings.TrimLeft(element.Value, " ")))
+	if start < element.Span.Start {
+		start = element.Span.Start
+	}
+	c.report("leading-whitespace", &syntax.Span{Start: start, End: end}, `Value starts with whitespace, use {" "} to make it explicit`)
+}
+
+// checkComment reports messages using variables without a comment.
+func (c *check) checkComment(message syntax.Message) {
+	if message.Comment != nil && !onlyDirectives(message.Comment.Content) {
+		return
+	}
+
+	var variable *syntax.VariableReference
+	find := func(expr syntax.Expression) {
+		if ref, ok := expr.(syntax.VariableReference); ok && variable == nil {
+			variable = &ref
+		}
+	}
+	if message.Value != nil {
+		syntax.InspectPattern(*message.Value, find)
+	}
+	for _, attribute := range message.Attributes {
+		syntax.InspectPattern(attribute.Value, find)
+	}
+
+	if variable != nil {
+		c.report("missing-comment", message.ID.Span, "Message \"%s\" uses the variable $%s but has no comment", message.ID.Name, variable.ID.Name)
+	}
+}
+
+// onlyDirectives reports whether a comment consists of directives only.
+func onlyDirectives(comment string) bool {
+	for _, line := range strings.Split(comment, "\n") {
+		if _, ok := parseDirective(line); !ok && strings.TrimSpace(line) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func isLineEnd(input []byte, i int) bool {
+	return i == len(input) || input[i] == '\n' || (input[i] == '\r' && i+1 < len(input) && input[i+1] == '\n')
+}
+
+func quoteList(a []string) string {
+	quoted := make([]string, len(a))
+	for i, s := range a {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	return strings.Join(quoted, " or ")
+}
@@ -0,0 +1,161 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		file     string
+		input    string
+		expected []syntax.Diagnostic
+	}{
+		{
+			name:  "clean",
+			input: "-brand = Firefox\n# $name is the user name.\nhello = Hello { $name } from { -brand }\n",
+		},
+		{
+			name:  "id case",
+			input: "helloWorld = Hello\n-Brand = Firefox\nsnake_case = x\n",
+			expected: []syntax.Diagnostic{
+				warning("L0001", `ID "helloWorld" does not match the pattern `+DefaultIDPattern, 0, 10),
+				warning("L0001", `ID "-Brand" does not match the pattern `+DefaultIDPattern, 20, 25),
+				warning("L0001", `ID "snake_case" does not match the pattern `+DefaultIDPattern, 36, 46),
+			},
+		},
+		{
+			name:   "id pattern",
+			config: Config{IDPattern: `^[a-z]+(_[a-z]+)*$`},
+			input:  "snake_case = x\nkebab-case = y\n",
+			expected: []syntax.Diagnostic{
+				warning("L0001", `ID "kebab-case" does not match the pattern ^[a-z]+(_[a-z]+)*$`, 15, 25),
+			},
+		},
+		{
+			name: "id prefix",
+			config: Config{Prefixes: map[string][]string{
+				"browser/*.ftl": {"browser-", "menu-"},
+				"*.txt":         {"txt-"},
+			}},
+			file:  "browser/menu.ftl",
+			input: "browser-title = Title\nmenu-file = File\nfile = File\n-brand = Firefox\n",
+			expected: []syntax.Diagnostic{
+				warning("L0002", `ID "file" does not start with "browser-" or "menu-"`, 39, 43),
+			},
+		},
+		{
+			name:  "trailing whitespace",
+			input: "key =\n    foo  \n    bar \t\nother = a  { $x }\n",
+			expected: []syntax.Diagnostic{
+				warning("L0003", "Trailing whitespace", 13, 15),
+				warning("L0003", "Trailing whitespace", 23, 25),
+				warning("L0006", `Message "other" uses the variable $x but has no comment`, 26, 31),
+			},
+		},
+		{
+			name:  "trailing whitespace after placeable",
+			input: "# $x is a number.\nkey = { $x }   \n    .title = { $x ->\n       *[other] Other \n    }\t\n",
+			expected: []syntax.Diagnostic{
+				warning("L0003", "Trailing whitespace", 30, 33),
+				warning("L0003", "Trailing whitespace", 76, 77),
+				warning("L0003", "Trailing whitespace", 83, 84),
+			},
+		},
+		{
+			name:  "leading whitespace",
+			input: "key =\n        indented\n    text\nok = {\" \"}text\n",
+			expected: []syntax.Diagnostic{
+				warning("L0004", `Value starts with whitespace, use {" "} to make it explicit`, 10, 14),
+			},
+		},
+		{
+			name:   "max length",
+			config: Config{MaxLength: 10},
+			input:  "short = Hello\nlong = Hello, World\n    .title = { $x } and { $y } and more\n",
+			expected: []syntax.Diagnostic{
+				warning("L0005", `Value of "long" is 12 characters long, more than 10`, 14, 18),
+				warning("L0005", `Value of "title" is 16 characters long, more than 10`, 39, 44),
+				warning("L0006", `Message "long" uses the variable $x but has no comment`, 14, 18),
+			},
+		},
+		{
+			name:  "missing comment",
+			input: "# fluent-lint-disable id-case\nhello = { $name }\n\n## Group\n\nbye = { NUMBER($n) }\n",
+			expected: []syntax.Diagnostic{
+				warning("L0006", `Message "hello" uses the variable $name but has no comment`, 30, 35),
+				warning("L0006", `Message "bye" uses the variable $n but has no comment`, 59, 62),
+			},
+		},
+		{
+			name:  "term in file",
+			input: "hello = { -brand } { -local.gender ->\n   *[other] x\n}\n-local = y\n",
+			expected: []syntax.Diagnostic{
+				warning("L0007", `Term "-brand" is not defined in this file`, 10, 16),
+			},
+		},
+		{
+			name:   "disabled in config",
+			config: Config{Rules: map[string]bool{"id-case": false, "missing-comment": true}},
+			input:  "helloWorld = { $x }\n",
+			expected: []syntax.Diagnostic{
+				warning("L0006", `Message "helloWorld" uses the variable $x but has no comment`, 0, 10),
+			},
+		},
+		{
+			name:  "disabled for entry",
+			input: "# fluent-lint-disable\nhelloWorld = { $x }\nbyeWorld = Bye\n",
+			expected: []syntax.Diagnostic{
+				warning("L0001", `ID "byeWorld" does not match the pattern `+DefaultIDPattern, 42, 50),
+			},
+		},
+		{
+			name:  "disabled for file",
+			input: "### fluent-lint-disable id-case, term-in-file\n\nhelloWorld = { -brand }\n\n# fluent-lint-disable-next\n\nbye = { $x }\n",
+			expected: []syntax.Diagnostic{
+				warning("L0006", `Message "bye" uses the variable $x but has no comment`, 100, 103),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			linter, err := New(tt.config)
+			require.NoError(t, err)
+
+			resource, err := syntax.Parse([]byte(tt.input))
+			require.NoError(t, err)
+
+			file := tt.file
+			if file == "" {
+				file = "test.ftl"
+			}
+			require.Equal(t, tt.expected, linter.Lint(file, []byte(tt.input), resource))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Rules: map[string]bool{"no-such-rule": true}})
+	require.EqualError(t, err, `unknown rule "no-such-rule"`)
+
+	_, err = New(Config{IDPattern: "("})
+	require.Error(t, err)
+
+	_, err = New(Config{Prefixes: map[string][]string{"[": {"x-"}}})
+	require.Error(t, err)
+}
+
+func warning(code, message string, start, end int) syntax.Diagnostic {
+	return syntax.Diagnostic{
+		Severity: syntax.SeverityWarning,
+		Code:     code,
+		Message:  message,
+		Span:     syntax.Span{Start: start, End: end},
+	}
+}
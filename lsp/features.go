@@ -0,0 +1,536 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/index"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Kinds of targets.
+const (
+	targetMessage = iota
+	targetTerm
+	targetVariable
+)
+
+// A target is a message, term or variable at a position in a document, where
+// it is defined or referenced.
+type target struct {
+	kind int
+
+	// id is the ID of the message or term, without "-", or the name of the
+	// variable.
+	id string
+
+	// attribute is the name of the attribute of a reference, e.g. "title" in
+	// { login.title }, if the position is on it.
+	attribute string
+
+	// span is the span of the identifier at the position.
+	span syntax.Span
+
+	// entry is the message or term the position is in.
+	entry syntax.Entry
+}
+
+// contains reports whether the span contains the offset, which may also be
+// at its end, where the cursor is after typing a name.
+func contains(span *syntax.Span, offset int) bool {
+	return span != nil && span.Start <= offset && offset <= span.End
+}
+
+// entryParts returns the ID, value and attributes of a message or term, with
+// ok false for other entries.
+func entryParts(entry syntax.Entry) (id syntax.Identifier, kind int, value *syntax.Pattern, attributes []syntax.Attribute, comment *syntax.Comment, ok bool) {
+	switch entry := entry.(type) {
+	case syntax.Message:
+		return entry.ID, targetMessage, entry.Value, entry.Attributes, entry.Comment, true
+	case syntax.Term:
+		return entry.ID, targetTerm, &entry.Value, entry.Attributes, entry.Comment, true
+	}
+	return id, 0, nil, nil, nil, false
+}
+
+// inspectEntry calls f for every expression in the value and attributes of
+// a message or term.
+func inspectEntry(entry syntax.Entry, f func(syntax.Expression)) {
+	_, _, value, attributes, _, ok := entryParts(entry)
+	if !ok {
+		return
+	}
+	if value != nil {
+		syntax.InspectPattern(*value, f)
+	}
+	for _, attribute := range attributes {
+		syntax.InspectPattern(attribute.Value, f)
+	}
+}
+
+// targetAt returns the target at offset in f, or false if there is none.
+func targetAt(f *file, offset int) (target, bool) {
+	for _, entry := range f.resource.Body {
+		id, kind, _, attributes, _, ok := entryParts(entry)
+		if !ok {
+			continue
+		}
+		if contains(id.Span, offset) {
+			return target{kind: kind, id: id.Name, span: *id.Span, entry: entry}, true
+		}
+		for _, attribute := range attributes {
+			if contains(attribute.ID.Span, offset) {
+				return target{kind: kind, id: id.Name, attribute: attribute.ID.Name, span: *attribute.ID.Span, entry: entry}, true
+			}
+		}
+
+		var (
+			result target
+			found  bool
+		)
+		inspectEntry(entry, func(expr syntax.Expression) {
+			if found {
+				return
+			}
+			var (
+				kind      int
+				id        syntax.Identifier
+				attribute *syntax.Identifier
+			)
+			switch expr := expr.(type) {
+			case syntax.MessageReference:
+				kind, id, attribute = targetMessage, expr.ID, expr.Attribute
+			case syntax.TermReference:
+				kind, id, attribute = targetTerm, expr.ID, expr.Attribute
+			case syntax.VariableReference:
+				kind, id = targetVariable, expr.ID
+			default:
+				return
+			}
+			switch {
+			case contains(id.Span, offset):
+				result, found = target{kind: kind, id: id.Name, span: *id.Span, entry: entry}, true
+			case attribute != nil && contains(attribute.Span, offset):
+				result, found = target{kind: kind, id: id.Name, attribute: attribute.Name, span: *attribute.Span, entry: entry}, true
+			}
+		})
+		if found {
+			return result, true
+		}
+	}
+	return target{}, false
+}
+
+// positionParams decodes the params of a request at a position, and returns
+// the file and the offset of the position in it.
+func (s *Server) positionParams(data json.RawMessage, params *textDocumentPositionParams) (*file, int, error) {
+	if err := unmarshalParams(data, params); err != nil {
+		return nil, 0, err
+	}
+	f := s.workspace.file(params.TextDocument.URI)
+	if f == nil {
+		return nil, 0, nil
+	}
+	return f, offset(f.text, params.Position), nil
+}
+
+// symbol returns the message, term or attribute of a target, which must not
+// be a variable.
+func (t target) symbol() index.Symbol {
+	kind := index.Message
+	if t.kind == targetTerm {
+		kind = index.Term
+	}
+	return index.Symbol{Kind: kind, ID: t.id, Attribute: t.attribute}
+}
+
+// index indexes the files in the scope of f. The resources of the index are
+// the returned files.
+func (s *Server) index(f *file) (*index.Index, []*file) {
+	files := s.workspace.scope(f)
+	resources := make([]syntax.Resource, len(files))
+	for i, g := range files {
+		resources[i] = g.resource
+	}
+	return index.New(resources...), files
+}
+
+// definitionLocations returns the locations of the identifiers of the
+// definitions of a symbol.
+func definitionLocations(x *index.Index, files []*file, symbol index.Symbol) []Location {
+	locations := make([]Location, 0)
+	for _, def := range x.Definitions(symbol) {
+		g := files[def.Resource]
+		locations = append(locations, Location{URI: g.uri, Range: span(g.text, def.ID.Start, def.ID.End)})
+	}
+	return locations
+}
+
+func (s *Server) definition(data json.RawMessage) (interface{}, error) {
+	var params textDocumentPositionParams
+	f, offset, err := s.positionParams(data, &params)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	t, ok := targetAt(f, offset)
+	if !ok || t.kind == targetVariable {
+		return nil, nil
+	}
+
+	x, files := s.index(f)
+	return definitionLocations(x, files, t.symbol()), nil
+}
+
+func (s *Server) references(data json.RawMessage) (interface{}, error) {
+	var params referenceParams
+	if err := unmarshalParams(data, &params); err != nil {
+		return nil, err
+	}
+	f, offset, err := s.positionParams(data, &params.textDocumentPositionParams)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	t, ok := targetAt(f, offset)
+	if !ok {
+		return nil, nil
+	}
+
+	if t.kind != targetVariable {
+		x, files := s.index(f)
+		locations := make([]Location, 0)
+		if params.Context.IncludeDeclaration {
+			locations = definitionLocations(x, files, t.symbol())
+		}
+		for _, ref := range x.References(t.symbol()) {
+			g := files[ref.Resource]
+			locations = append(locations, Location{URI: g.uri, Range: span(g.text, ref.Span.Start, ref.Span.End)})
+		}
+		return locations, nil
+	}
+
+	// Variables are local to the message or term they are used in.
+	locations := make([]Location, 0)
+	for _, entry := range f.resource.Body {
+		if !sameEntry(entry, t.entry) {
+			continue
+		}
+		inspectEntry(entry, func(expr syntax.Expression) {
+			if v, ok := expr.(syntax.VariableReference); ok && v.ID.Name == t.id && v.Span != nil {
+				locations = append(locations, Location{URI: f.uri, Range: span(f.text, v.Span.Start, v.Span.End)})
+			}
+		})
+	}
+	return locations, nil
+}
+
+// sameEntry reports whether a and b are the same message or term of a
+// resource.
+func sameEntry(a, b syntax.Entry) bool {
+	idA, kindA, _, _, _, okA := entryParts(a)
+	idB, kindB, _, _, _, okB := entryParts(b)
+	return okA && okB && kindA == kindB && idA.Name == idB.Name && idA.Span != nil && idB.Span != nil && *idA.Span == *idB.Span
+}
+
+func (s *Server) hover(data json.RawMessage) (interface{}, error) {
+	var params textDocumentPositionParams
+	f, offset, err := s.positionParams(data, &params)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	t, ok := targetAt(f, offset)
+	if !ok || t.kind == targetVariable {
+		return nil, nil
+	}
+
+	// The definition in the document itself is preferred.
+	var (
+		entry syntax.Entry
+		def   *file
+	)
+	x, files := s.index(f)
+	for _, d := range x.Definitions(t.symbol().Parent()) {
+		if entry == nil || files[d.Resource] == f && def != f {
+			entry, def = d.Entry, files[d.Resource]
+		}
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	writeEntry(&b, entry)
+	if _, _, _, _, comment, _ := entryParts(entry); comment != nil {
+		b.WriteString("\n")
+		b.WriteString(comment.Content)
+		b.WriteString("\n")
+	}
+
+	translations, _ := s.workspace.translations(def)
+	for _, translation := range translations {
+		for _, other := range translation.file.resource.Body {
+			if id, kind, _, _, _, ok := entryParts(other); ok && kind == t.kind && id.Name == t.id {
+				b.WriteString("\n**")
+				b.WriteString(translation.locale)
+				b.WriteString("**\n")
+				writeEntry(&b, other)
+				break
+			}
+		}
+	}
+
+	r := span(f.text, t.span.Start, t.span.End)
+	return hover{
+		Contents: markupContent{Kind: "markdown", Value: strings.TrimSuffix(b.String(), "\n")},
+		Range:    &r,
+	}, nil
+}
+
+// writeEntry writes a message or term without its comment as a Fluent code
+// block.
+func writeEntry(b *strings.Builder, entry syntax.Entry) {
+	switch e := entry.(type) {
+	case syntax.Message:
+		e.Comment = nil
+		entry = e
+	case syntax.Term:
+		e.Comment = nil
+		entry = e
+	}
+	var buf bytes.Buffer
+	if err := syntax.Fprint(&buf, entry); err != nil {
+		return
+	}
+	b.WriteString("```fluent\n")
+	b.Write(buf.Bytes())
+	b.WriteString("```\n")
+}
+
+// functions are the built-in functions of Fluent.
+var functions = []string{"DATETIME", "NUMBER"}
+
+func (s *Server) completion(data json.RawMessage) (interface{}, error) {
+	var params textDocumentPositionParams
+	f, offset, err := s.positionParams(data, &params)
+	if err != nil || f == nil {
+		return nil, err
+	}
+
+	// The prefix is the part of the name before the cursor, with "$" or "-".
+	start := offset
+	for start > 0 && isNameByte(f.text[start-1]) {
+		start--
+	}
+	if start > 0 && f.text[start-1] == '$' {
+		start--
+	}
+	prefix := string(f.text[start:offset])
+
+	items := make([]completionItem, 0)
+	if !inPlaceable(f.text, start) {
+		return items, nil
+	}
+	edit := func(label string) *textEdit {
+		return &textEdit{Range: span(f.text, start, offset), NewText: label}
+	}
+
+	if strings.HasPrefix(prefix, "$") {
+		for _, name := range s.variables(f, offset) {
+			items = append(items, completionItem{Label: "$" + name, Kind: completionVariable, TextEdit: edit("$" + name)})
+		}
+		return items, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, g := range s.workspace.scope(f) {
+		for _, entry := range g.resource.Body {
+			id, kind, value, _, _, ok := entryParts(entry)
+			if !ok {
+				continue
+			}
+			label, itemKind := id.Name, completionReference
+			if kind == targetTerm {
+				label, itemKind = "-"+id.Name, completionConstant
+			}
+			// Clients filter the items by the prefix, except that only
+			// terms start with "-".
+			if seen[label] || strings.HasPrefix(prefix, "-") && kind != targetTerm {
+				continue
+			}
+			seen[label] = true
+			item := completionItem{Label: label, Kind: itemKind, TextEdit: edit(label)}
+			if value != nil {
+				item.Detail = firstLine(syntax.FormatPattern(*value))
+			}
+			items = append(items, item)
+		}
+	}
+	if !strings.HasPrefix(prefix, "-") {
+		for _, name := range functions {
+			items = append(items, completionItem{Label: name, Kind: completionFunction, TextEdit: edit(name + "()")})
+		}
+	}
+	return items, nil
+}
+
+// variables returns the names of the variables used in the message or term
+// at offset in f and its translations, followed by the other variables of f,
+// each sorted.
+func (s *Server) variables(f *file, offset int) []string {
+	var entry syntax.Entry
+	for _, e := range f.resource.Body {
+		if _, _, _, _, _, ok := entryParts(e); ok && contains(entrySpan(e), offset) {
+			entry = e
+		}
+	}
+
+	seen := make(map[string]bool)
+	var own, others []string
+	collect := func(entry syntax.Entry, names *[]string) {
+		inspectEntry(entry, func(expr syntax.Expression) {
+			if v, ok := expr.(syntax.VariableReference); ok && !seen[v.ID.Name] {
+				seen[v.ID.Name] = true
+				*names = append(*names, v.ID.Name)
+			}
+		})
+	}
+
+	if id, kind, _, _, _, ok := entryParts(entry); ok {
+		collect(entry, &own)
+		translations, _ := s.workspace.translations(f)
+		for _, translation := range translations {
+			for _, other := range translation.file.resource.Body {
+				if otherID, otherKind, _, _, _, ok := entryParts(other); ok && otherKind == kind && otherID.Name == id.Name {
+					collect(other, &own)
+				}
+			}
+		}
+	}
+	for _, e := range f.resource.Body {
+		collect(e, &others)
+	}
+
+	sort.Strings(own)
+	sort.Strings(others)
+	return append(own, others...)
+}
+
+func entrySpan(entry syntax.Entry) *syntax.Span {
+	switch entry := entry.(type) {
+	case syntax.Message:
+		return entry.Span
+	case syntax.Term:
+		return entry.Span
+	}
+	return nil
+}
+
+func isNameByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+// inPlaceable reports whether offset in text is inside a placeable, outside
+// of string literals. Lines starting at the first column, which start
+// entries, reset the state, so that errors do not affect later entries.
+func inPlaceable(text []byte, offset int) bool {
+	depth := 0
+	inString := false
+	for i := 0; i < offset && i < len(text); i++ {
+		c := text[i]
+		if i == 0 || text[i-1] == '\n' {
+			if c != ' ' && c != '\n' && c != '\r' && c != '}' && c != '[' && c != '*' && c != '.' {
+				depth, inString = 0, false
+			}
+		}
+		switch {
+		case inString && c == '\\':
+			i++
+		case inString && (c == '"' || c == '\n'):
+			inString = false
+		case inString:
+		case depth > 0 && c == '"':
+			inString = true
+		case c == '{':
+			depth++
+		case c == '}' && depth > 0:
+			depth--
+		}
+	}
+	return depth > 0 && !inString
+}
+
+// firstLine returns the first line of s, with "…" if there are more.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + "…"
+	}
+	return s
+}
+
+func (s *Server) documentSymbol(data json.RawMessage) (interface{}, error) {
+	var params documentSymbolParams
+	if err := unmarshalParams(data, &params); err != nil {
+		return nil, err
+	}
+	f := s.workspace.file(params.TextDocument.URI)
+	if f == nil {
+		return nil, nil
+	}
+
+	symbols := make([]documentSymbol, 0)
+	for _, entry := range f.resource.Body {
+		id, kind, value, attributes, _, ok := entryParts(entry)
+		entrySpan := entrySpan(entry)
+		if !ok || id.Span == nil || entrySpan == nil {
+			continue
+		}
+		symbol := documentSymbol{
+			Name:           id.Name,
+			Kind:           symbolString,
+			Range:          span(f.text, entrySpan.Start, entrySpan.End),
+			SelectionRange: span(f.text, id.Span.Start, id.Span.End),
+		}
+		if kind == targetTerm {
+			symbol.Name, symbol.Kind = "-"+id.Name, symbolConstant
+		}
+		if value != nil {
+			symbol.Detail = firstLine(syntax.FormatPattern(*value))
+		}
+		for _, attribute := range attributes {
+			if attribute.Span == nil || attribute.ID.Span == nil {
+				continue
+			}
+			symbol.Children = append(symbol.Children, documentSymbol{
+				Name:           "." + attribute.ID.Name,
+				Detail:         firstLine(syntax.FormatPattern(attribute.Value)),
+				Kind:           symbolProperty,
+				Range:          span(f.text, attribute.Span.Start, attribute.Span.End),
+				SelectionRange: span(f.text, attribute.ID.Span.Start, attribute.ID.Span.End),
+			})
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, nil
+}
+
+func (s *Server) formatting(data json.RawMessage) (interface{}, error) {
+	var params documentFormattingParams
+	if err := unmarshalParams(data, &params); err != nil {
+		return nil, err
+	}
+	f := s.workspace.file(params.TextDocument.URI)
+	if f == nil {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	if err := syntax.Fprint(&buf, f.resource); err != nil {
+		return nil, err
+	}
+	edits := make([]textEdit, 0)
+	if !bytes.Equal(buf.Bytes(), f.text) {
+		edits = append(edits, textEdit{Range: span(f.text, 0, len(f.text)), NewText: buf.String()})
+	}
+	return edits, nil
+}
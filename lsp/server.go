@@ -0,0 +1,279 @@
+// Package lsp implements a Language Server Protocol server for Fluent files,
+// for editors such as VS Code and Neovim.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// A Server is a language server for .ftl files. It provides:
+//
+//   - diagnostics of parse errors, updated on every change,
+//   - hover with the comment of a message or term and its value in the other
+//     locales,
+//   - go to definition and find references of messages and terms,
+//   - completion of message and term IDs, variables and functions,
+//   - document symbols for messages, terms and attributes, and
+//   - formatting as by syntax.Fprint.
+//
+// See workspace for how files are grouped into locales.
+type Server struct {
+	// Version is reported to the client in the server info.
+	Version string
+
+	conn      *conn
+	workspace *workspace
+
+	initialized bool
+	shutdown    bool
+}
+
+// ErrNoShutdown is returned by Serve if the client sent the exit notification
+// without a shutdown request before, in which case the server should exit
+// with code 1.
+var ErrNoShutdown = errors.New("exit without shutdown")
+
+// Serve reads requests and notifications from r and writes responses and
+// notifications to w, until it receives the exit notification or r ends.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	s.workspace = newWorkspace()
+
+	for {
+		msg, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		var rerr *responseError
+		if errors.As(err, &rerr) {
+			// The ID of an invalid message is unknown.
+			if err := s.conn.write(&message{ID: nullID(), Error: rerr}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrNoShutdown
+			}
+			return nil
+		}
+		if msg.ID == nil {
+			if err := s.handleNotification(msg); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err := s.handleRequest(msg)
+		response := &message{ID: msg.ID}
+		if err != nil {
+			if !errors.As(err, &rerr) {
+				rerr = &responseError{Code: codeInternalError, Message: err.Error()}
+			}
+			response.Error = rerr
+		} else if response.Result, err = json.Marshal(result); err != nil {
+			return err
+		}
+		if err := s.conn.write(response); err != nil {
+			return err
+		}
+	}
+}
+
+func nullID() *json.RawMessage {
+	id := json.RawMessage("null")
+	return &id
+}
+
+// handlers map the methods of requests to the functions handling them, which
+// are given the server and the params of the request.
+var handlers = map[string]func(s *Server, params json.RawMessage) (interface{}, error){
+	"textDocument/hover":          (*Server).hover,
+	"textDocument/definition":     (*Server).definition,
+	"textDocument/references":     (*Server).references,
+	"textDocument/completion":     (*Server).completion,
+	"textDocument/documentSymbol": (*Server).documentSymbol,
+	"textDocument/formatting":     (*Server).formatting,
+}
+
+func (s *Server) handleRequest(msg *message) (interface{}, error) {
+	switch {
+	case msg.Method == "initialize":
+		return s.initialize(msg.Params)
+	case !s.initialized:
+		return nil, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	case s.shutdown:
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	case msg.Method == "shutdown":
+		s.shutdown = true
+		return nil, nil
+	}
+
+	handler, ok := handlers[msg.Method]
+	if !ok {
+		return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", msg.Method)}
+	}
+	return handler(s, msg.Params)
+}
+
+func (s *Server) initialize(data json.RawMessage) (interface{}, error) {
+	var params initializeParams
+	if err := unmarshalParams(data, &params); err != nil {
+		return nil, err
+	}
+
+	root := uriToPath(params.RootURI)
+	if root == "" && len(params.WorkspaceFolders) > 0 {
+		root = uriToPath(params.WorkspaceFolders[0].URI)
+	}
+	if root == "" {
+		root = params.RootPath
+	}
+	if root != "" {
+		if err := s.workspace.load(root); err != nil {
+			return nil, err
+		}
+	}
+	s.initialized = true
+
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			TextDocumentSync:           textDocumentSyncOptions{OpenClose: true, Change: syncIncremental},
+			HoverProvider:              true,
+			DefinitionProvider:         true,
+			ReferencesProvider:         true,
+			CompletionProvider:         completionOptions{TriggerCharacters: []string{"{", "$", "-"}},
+			DocumentSymbolProvider:     true,
+			DocumentFormattingProvider: true,
+		},
+		ServerInfo: serverInfo{Name: "fluent", Version: s.Version},
+	}, nil
+}
+
+func (s *Server) handleNotification(msg *message) error {
+	if !s.initialized {
+		return nil
+	}
+
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params didOpenTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		doc := params.TextDocument
+		return s.publishDiagnostics(s.workspace.open(doc.URI, doc.Version, []byte(doc.Text)))
+	case "textDocument/didChange":
+		var params didChangeTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		f := s.workspace.file(params.TextDocument.URI)
+		if f == nil || !f.open {
+			return nil
+		}
+		for _, change := range params.ContentChanges {
+			applyChange(f, change)
+		}
+		f.version = params.TextDocument.Version
+		return s.publishDiagnostics(f)
+	case "textDocument/didClose":
+		var params didCloseTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		s.workspace.close(params.TextDocument.URI)
+		// Diagnostics are only reported for open documents.
+		return s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: make([]Diagnostic, 0),
+		})
+	}
+	// Other notifications, e.g. $/cancelRequest, are ignored.
+	return nil
+}
+
+// applyChange applies a change of the client to the text of f and reparses
+// the entries around it.
+func applyChange(f *file, change textDocumentContentChangeEvent) {
+	if change.Range == nil {
+		f.parse([]byte(change.Text))
+		return
+	}
+
+	start, end := offset(f.text, change.Range.Start), offset(f.text, change.Range.End)
+	if end < start {
+		start, end = end, start
+	}
+	text := make([]byte, 0, len(f.text)-(end-start)+len(change.Text))
+	text = append(text, f.text[:start]...)
+	text = append(text, change.Text...)
+	text = append(text, f.text[end:]...)
+
+	f.resource, f.err = syntax.Reparse(f.resource, text, syntax.Edit{Start: start, End: end, Text: []byte(change.Text)})
+	f.text = text
+}
+
+// publishDiagnostics sends the parse errors of f to the client.
+func (s *Server) publishDiagnostics(f *file) error {
+	diagnostics := make([]Diagnostic, 0)
+	var perrs *syntax.ParseErrors
+	if errors.As(f.err, &perrs) {
+		for _, perr := range perrs.Errors() {
+			d := perr.Diagnostic()
+			message := d.Message
+			if d.Hint != "" {
+				message += "\n" + d.Hint
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    errorRange(f.text, d.Span.Start),
+				Severity: severityError,
+				Code:     d.Code,
+				Source:   "fluent",
+				Message:  message,
+			})
+		}
+	}
+
+	version := f.version
+	return s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         f.uri,
+		Version:     &version,
+		Diagnostics: diagnostics,
+	})
+}
+
+// errorRange returns the range of a parse error at offset, which covers the
+// character at offset, unless it is the end of a line.
+func errorRange(text []byte, offset int) Range {
+	end := offset
+	if end < len(text) && text[end] != '\n' && text[end] != '\r' {
+		end++
+		for end < len(text) && text[end]&0xC0 == 0x80 {
+			end++
+		}
+	}
+	return span(text, offset, end)
+}
+
+// unmarshalParams decodes the params of a request, and returns an error with
+// the code for invalid params if they cannot be decoded.
+func unmarshalParams(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
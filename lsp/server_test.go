@@ -0,0 +1,278 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// client is the client side of a connection to a server.
+type client struct {
+	t    *testing.T
+	conn *conn
+	id   int
+	done chan error
+}
+
+func newClient(t *testing.T, root string) *client {
+	serverR, clientW := io.Pipe()
+	clientR, serverW := io.Pipe()
+
+	c := &client{t: t, conn: newConn(clientR, clientW), done: make(chan error, 1)}
+	go func() {
+		err := (&Server{Version: "test"}).Serve(serverR, serverW)
+		serverW.Close()
+		c.done <- err
+	}()
+
+	var result initializeResult
+	c.call("initialize", map[string]interface{}{"rootUri": pathToURI(root)}, &result)
+	require.Equal(t, "fluent", result.ServerInfo.Name)
+	require.Equal(t, syncIncremental, result.Capabilities.TextDocumentSync.Change)
+	c.notify("initialized", struct{}{})
+	return c
+}
+
+// call sends a request and decodes the result into result, skipping
+// notifications of the server.
+func (c *client) call(method string, params, result interface{}) {
+	c.t.Helper()
+	c.id++
+	id := json.RawMessage(mustMarshal(c.t, c.id))
+	require.NoError(c.t, c.conn.write(&message{ID: &id, Method: method, Params: mustMarshal(c.t, params)}))
+	for {
+		msg, err := c.conn.read()
+		require.NoError(c.t, err)
+		if msg.ID == nil {
+			continue
+		}
+		require.Nil(c.t, msg.Error)
+		require.NoError(c.t, json.Unmarshal(msg.Result, result))
+		return
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.t.Helper()
+	require.NoError(c.t, c.conn.notify(method, params))
+}
+
+// diagnostics reads the next notification, which must publish diagnostics.
+func (c *client) diagnostics() publishDiagnosticsParams {
+	c.t.Helper()
+	msg, err := c.conn.read()
+	require.NoError(c.t, err)
+	require.Equal(c.t, "textDocument/publishDiagnostics", msg.Method)
+	var params publishDiagnosticsParams
+	require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+	return params
+}
+
+func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for path, text := range files {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(text), 0o644))
+	}
+	return root
+}
+
+const enMain = `-brand = Firefox
+
+# Shown on the start page.
+welcome = Welcome to { -brand }, { $name }!
+login = Log in
+    .title = Log in to { -brand }
+login-button = { login.title }
+`
+
+const deMain = `-brand = Firefox
+welcome = Willkommen bei { -brand }, { $user }!
+`
+
+func TestServer(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"en-US/main.ftl": enMain,
+		"de/main.ftl":    deMain,
+	})
+	c := newClient(t, root)
+	uri := pathToURI(filepath.Join(root, "en-US", "main.ftl"))
+	deURI := pathToURI(filepath.Join(root, "de", "main.ftl"))
+	doc := map[string]interface{}{"uri": uri}
+	at := func(line, character int) map[string]interface{} {
+		return map[string]interface{}{"textDocument": doc, "position": Position{Line: line, Character: character}}
+	}
+
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": textDocumentItem{URI: uri, LanguageID: "fluent", Version: 1, Text: enMain},
+	})
+	require.Equal(t, publishDiagnosticsParams{URI: uri, Version: intPtr(1), Diagnostics: []Diagnostic{}}, c.diagnostics())
+
+	t.Run("hover", func(t *testing.T) {
+		var result hover
+		c.call("textDocument/hover", at(3, 2), &result)
+		require.Equal(t, "markdown", result.Contents.Kind)
+		require.Equal(t, "```fluent\nwelcome = Welcome to { -brand }, { $name }!\n```\n\nShown on the start page.\n\n**de**\n```fluent\nwelcome = Willkommen bei { -brand }, { $user }!\n```", result.Contents.Value)
+		require.Equal(t, &Range{Start: Position{3, 0}, End: Position{3, 7}}, result.Range)
+	})
+
+	t.Run("definition", func(t *testing.T) {
+		var result []Location
+		c.call("textDocument/definition", at(3, 25), &result)
+		require.Equal(t, []Location{{URI: uri, Range: Range{Start: Position{0, 1}, End: Position{0, 6}}}}, result)
+
+		c.call("textDocument/definition", at(6, 25), &result)
+		require.Equal(t, []Location{{URI: uri, Range: Range{Start: Position{5, 5}, End: Position{5, 10}}}}, result)
+	})
+
+	t.Run("references", func(t *testing.T) {
+		var result []Location
+		c.call("textDocument/references", map[string]interface{}{
+			"textDocument": doc,
+			"position":     Position{Line: 0, Character: 2},
+			"context":      map[string]bool{"includeDeclaration": true},
+		}, &result)
+		require.Equal(t, []Location{
+			{URI: uri, Range: Range{Start: Position{0, 1}, End: Position{0, 6}}},
+			{URI: uri, Range: Range{Start: Position{3, 23}, End: Position{3, 29}}},
+			{URI: uri, Range: Range{Start: Position{5, 25}, End: Position{5, 31}}},
+		}, result)
+	})
+
+	t.Run("change", func(t *testing.T) {
+		c.notify("textDocument/didChange", map[string]interface{}{
+			"textDocument": versionedTextDocumentIdentifier{URI: uri, Version: 2},
+			"contentChanges": []textDocumentContentChangeEvent{
+				{Range: &Range{Start: Position{7, 0}, End: Position{7, 0}}, Text: "broken = { $"},
+			},
+		})
+		diagnostics := c.diagnostics()
+		require.Equal(t, intPtr(2), diagnostics.Version)
+		require.Len(t, diagnostics.Diagnostics, 1)
+		require.Equal(t, 7, diagnostics.Diagnostics[0].Range.Start.Line)
+
+		c.notify("textDocument/didChange", map[string]interface{}{
+			"textDocument": versionedTextDocumentIdentifier{URI: uri, Version: 3},
+			"contentChanges": []textDocumentContentChangeEvent{
+				{Range: &Range{Start: Position{7, 0}, End: Position{7, 12}}, Text: ""},
+			},
+		})
+		require.Empty(t, c.diagnostics().Diagnostics)
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		var result []completionItem
+		c.call("textDocument/completion", at(3, 38), &result)
+		require.Equal(t, []completionItem{
+			{Label: "$name", Kind: completionVariable, TextEdit: &textEdit{Range: Range{Start: Position{3, 35}, End: Position{3, 38}}, NewText: "$name"}},
+			{Label: "$user", Kind: completionVariable, TextEdit: &textEdit{Range: Range{Start: Position{3, 35}, End: Position{3, 38}}, NewText: "$user"}},
+		}, result)
+
+		c.call("textDocument/completion", at(3, 25), &result)
+		require.Equal(t, []completionItem{
+			{Label: "-brand", Kind: completionConstant, Detail: "Firefox", TextEdit: &textEdit{Range: Range{Start: Position{3, 23}, End: Position{3, 25}}, NewText: "-brand"}},
+		}, result)
+
+		c.call("textDocument/completion", at(6, 17), &result)
+		var labels []string
+		for _, item := range result {
+			labels = append(labels, item.Label)
+		}
+		require.Equal(t, []string{"-brand", "welcome", "login", "login-button", "DATETIME", "NUMBER"}, labels)
+
+		c.call("textDocument/completion", at(3, 12), &result)
+		require.Empty(t, result)
+	})
+
+	t.Run("documentSymbol", func(t *testing.T) {
+		var result []documentSymbol
+		c.call("textDocument/documentSymbol", map[string]interface{}{"textDocument": doc}, &result)
+		require.Len(t, result, 4)
+		require.Equal(t, documentSymbol{
+			Name:           "-brand",
+			Detail:         "Firefox",
+			Kind:           symbolConstant,
+			Range:          Range{Start: Position{0, 0}, End: Position{0, 16}},
+			SelectionRange: Range{Start: Position{0, 1}, End: Position{0, 6}},
+		}, result[0])
+		require.Equal(t, "login", result[2].Name)
+		require.Equal(t, symbolString, result[2].Kind)
+		require.Equal(t, []documentSymbol{{
+			Name:           ".title",
+			Detail:         "Log in to { -brand }",
+			Kind:           symbolProperty,
+			Range:          Range{Start: Position{5, 4}, End: Position{5, 33}},
+			SelectionRange: Range{Start: Position{5, 5}, End: Position{5, 10}},
+		}}, result[2].Children)
+	})
+
+	t.Run("formatting", func(t *testing.T) {
+		var result []textEdit
+		c.call("textDocument/formatting", map[string]interface{}{"textDocument": doc}, &result)
+		require.Len(t, result, 1)
+		require.Equal(t, Range{Start: Position{0, 0}, End: Position{7, 0}}, result[0].Range)
+
+		// Formatted files are left unchanged.
+		var deResult []textEdit
+		c.call("textDocument/formatting", map[string]interface{}{"textDocument": map[string]string{"uri": deURI}}, &deResult)
+		require.Empty(t, deResult)
+	})
+
+	c.notify("textDocument/didClose", map[string]interface{}{"textDocument": doc})
+	require.Equal(t, publishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}}, c.diagnostics())
+
+	var result interface{}
+	c.call("shutdown", nil, &result)
+	c.notify("exit", nil)
+	require.NoError(t, <-c.done)
+}
+
+func TestServerFormatting(t *testing.T) {
+	c := newClient(t, t.TempDir())
+	uri := "untitled:Untitled-1"
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": textDocumentItem{URI: uri, LanguageID: "fluent", Version: 1, Text: "hello   =   Hello\n\n\n\nbye = Bye"},
+	})
+	c.diagnostics()
+
+	var result []textEdit
+	c.call("textDocument/formatting", map[string]interface{}{"textDocument": map[string]string{"uri": uri}}, &result)
+	require.Equal(t, []textEdit{{
+		Range:   Range{Start: Position{0, 0}, End: Position{4, 9}},
+		NewText: "hello = Hello\nbye = Bye\n",
+	}}, result)
+}
+
+func TestServerExitWithoutShutdown(t *testing.T) {
+	c := newClient(t, t.TempDir())
+	c.notify("exit", nil)
+	require.Equal(t, ErrNoShutdown, <-c.done)
+}
+
+func TestServerNotInitialized(t *testing.T) {
+	serverR, clientW := io.Pipe()
+	clientR, serverW := io.Pipe()
+	go (&Server{}).Serve(serverR, serverW)
+	c := newConn(clientR, clientW)
+
+	id := json.RawMessage("1")
+	require.NoError(t, c.write(&message{ID: &id, Method: "textDocument/hover", Params: json.RawMessage("{}")}))
+	msg, err := c.read()
+	require.NoError(t, err)
+	require.Equal(t, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}, msg.Error)
+	clientW.Close()
+}
+
+func intPtr(i int) *int { return &i }
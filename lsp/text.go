@@ -0,0 +1,92 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// offset returns the byte offset in text of pos, whose character counts
+// UTF-16 code units. Positions past the end of a line are at its end, and
+// positions past the end of text at the end of text.
+func offset(text []byte, pos Position) int {
+	i := 0
+	for line := 0; line < pos.Line; line++ {
+		for i < len(text) && text[i] != '\n' {
+			i++
+		}
+		if i == len(text) {
+			return i
+		}
+		i++
+	}
+
+	for units := 0; units < pos.Character && i < len(text) && text[i] != '\n'; {
+		r, size := utf8.DecodeRune(text[i:])
+		n := utf16.RuneLen(r)
+		if n < 0 {
+			n = 1
+		}
+		if units+n > pos.Character {
+			break
+		}
+		units += n
+		i += size
+	}
+	return i
+}
+
+// position returns the position of the byte offset in text.
+func position(text []byte, offset int) Position {
+	if offset > len(text) {
+		offset = len(text)
+	}
+	var pos Position
+	for i := 0; i < offset; {
+		if text[i] == '\n' {
+			pos.Line++
+			pos.Character = 0
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(text[i:])
+		n := utf16.RuneLen(r)
+		if n < 0 {
+			n = 1
+		}
+		pos.Character += n
+		i += size
+	}
+	return pos
+}
+
+// span returns the range of the byte offsets [start, end) in text.
+func span(text []byte, start, end int) Range {
+	return Range{Start: position(text, start), End: position(text, end)}
+}
+
+// uriToPath returns the path of a file URI, or "" if uri is not a file URI.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	path := u.Path
+	// Windows paths are written as file:///C:/dir.
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// pathToURI returns the file URI of an absolute path.
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u := url.URL{Scheme: "file", Path: path}
+	return u.String()
+}
@@ -0,0 +1,60 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffset(t *testing.T) {
+	text := []byte("a = b\nkey = 😀é x\n\nlast")
+
+	tests := []struct {
+		pos    Position
+		offset int
+	}{
+		{Position{0, 0}, 0},
+		{Position{0, 3}, 3},
+		{Position{0, 99}, 5},
+		{Position{1, 0}, 6},
+		{Position{1, 6}, 12},
+		// The emoji is two UTF-16 code units and four bytes.
+		{Position{1, 8}, 16},
+		{Position{1, 7}, 12},
+		{Position{1, 9}, 18},
+		{Position{2, 0}, 21},
+		{Position{3, 4}, 26},
+		{Position{9, 0}, 26},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.offset, offset(text, tt.pos), "%v", tt.pos)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	text := []byte("a = b\nkey = 😀é x\n\nlast")
+
+	tests := []struct {
+		offset int
+		pos    Position
+	}{
+		{0, Position{0, 0}},
+		{5, Position{0, 5}},
+		{6, Position{1, 0}},
+		{16, Position{1, 8}},
+		{18, Position{1, 9}},
+		{21, Position{2, 0}},
+		{26, Position{3, 4}},
+		{99, Position{3, 4}},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.pos, position(text, tt.offset), "%d", tt.offset)
+	}
+}
+
+func TestURI(t *testing.T) {
+	require.Equal(t, "file:///home/user/l10n%20files/en-US/main.ftl", pathToURI("/home/user/l10n files/en-US/main.ftl"))
+	require.Equal(t, "/home/user/l10n files/en-US/main.ftl", uriToPath("file:///home/user/l10n%20files/en-US/main.ftl"))
+	require.Equal(t, "", uriToPath("untitled:Untitled-1"))
+	require.Equal(t, key("file:///home/user/en-US/main.ftl"), key("file:///home/user/en-US/../en-US/main.ftl"))
+}
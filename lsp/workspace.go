@@ -0,0 +1,228 @@
+package lsp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// A file is an .ftl file of the workspace, or a document opened by the
+// client.
+type file struct {
+	uri  string
+	path string
+
+	text     []byte
+	resource syntax.Resource
+	// err holds the parse errors of the text.
+	err error
+
+	// open is whether the client manages the text of the file, in which case
+	// it is not read from disk.
+	open    bool
+	version int
+}
+
+// parse sets the text of the file and parses it.
+func (f *file) parse(text []byte) {
+	f.text = text
+	f.resource, f.err = syntax.Parse(text)
+}
+
+// A workspace holds the .ftl files in the root directory and the open
+// documents.
+//
+// Files are grouped into locales by their paths: two files are translations
+// of each other if their paths relative to the root differ in exactly one
+// directory, which is the locale, e.g. en-US/main.ftl and de/main.ftl, or
+// l10n/en-US/browser/menu.ftl and l10n/de/browser/menu.ftl. References are
+// resolved in the files under the same locale directory, or in all files if
+// there are no translations.
+type workspace struct {
+	root  string
+	files map[string]*file
+}
+
+func newWorkspace() *workspace {
+	return &workspace{files: make(map[string]*file)}
+}
+
+// key returns the key of a URI in files, which is the same for all encodings
+// of the URI of a file.
+func key(uri string) string {
+	if path := uriToPath(uri); path != "" {
+		return pathToURI(filepath.Clean(path))
+	}
+	return uri
+}
+
+// load reads and parses the .ftl files in root and its subdirectories,
+// except hidden directories and node_modules.
+func (w *workspace) load(root string) error {
+	w.root = root
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped.
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".ftl" {
+			return nil
+		}
+		w.read(path)
+		return nil
+	})
+}
+
+// read reads and parses a file from disk, unless it is open, and returns it.
+// Files which cannot be read are removed.
+func (w *workspace) read(path string) *file {
+	uri := pathToURI(filepath.Clean(path))
+	f := w.files[uri]
+	if f != nil && f.open {
+		return f
+	}
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		delete(w.files, uri)
+		return nil
+	}
+	if f == nil {
+		f = &file{uri: uri, path: filepath.Clean(path)}
+		w.files[uri] = f
+	}
+	f.parse(text)
+	return f
+}
+
+// file returns the file of a URI, or nil.
+func (w *workspace) file(uri string) *file {
+	return w.files[key(uri)]
+}
+
+// open sets the text of a document opened by the client.
+func (w *workspace) open(uri string, version int, text []byte) *file {
+	f := w.files[key(uri)]
+	if f == nil {
+		f = &file{uri: uri, path: uriToPath(uri)}
+		if f.path != "" {
+			f.path = filepath.Clean(f.path)
+		}
+		w.files[key(uri)] = f
+	}
+	f.uri = uri
+	f.open = true
+	f.version = version
+	f.parse(text)
+	return f
+}
+
+// close returns a document closed by the client to the file on disk, if it
+// is in the root directory.
+func (w *workspace) close(uri string) {
+	f := w.files[key(uri)]
+	if f == nil {
+		return
+	}
+	f.open = false
+	if w.components(f) == nil || w.read(f.path) == nil {
+		delete(w.files, key(uri))
+	}
+}
+
+// components returns the path of f relative to the root, split into its
+// components, or nil if f is not in the root directory.
+func (w *workspace) components(f *file) []string {
+	if w.root == "" || f.path == "" {
+		return nil
+	}
+	rel, err := filepath.Rel(w.root, f.path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	return strings.Split(filepath.ToSlash(rel), "/")
+}
+
+// A translation is a file which is a translation of another.
+type translation struct {
+	locale string
+	file   *file
+}
+
+// translations returns the translations of f, sorted by locale, and the
+// index of the locale directory in the components of its path, or -1 if
+// there are none.
+func (w *workspace) translations(f *file) ([]translation, int) {
+	components := w.components(f)
+	if components == nil {
+		return nil, -1
+	}
+
+	var result []translation
+	index := -1
+	for _, g := range w.sorted() {
+		other := w.components(g)
+		if g == f || len(other) != len(components) {
+			continue
+		}
+		diff := -1
+		for i := range components {
+			if components[i] != other[i] {
+				if diff >= 0 {
+					diff = -1
+					break
+				}
+				diff = i
+			}
+		}
+		if diff < 0 || diff == len(components)-1 {
+			continue
+		}
+		result = append(result, translation{locale: other[diff], file: g})
+		index = diff
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].locale < result[j].locale })
+	return result, index
+}
+
+// scope returns the files in which references in f are resolved.
+func (w *workspace) scope(f *file) []*file {
+	components := w.components(f)
+	if components == nil {
+		return []*file{f}
+	}
+	_, index := w.translations(f)
+
+	var result []*file
+	for _, g := range w.sorted() {
+		other := w.components(g)
+		if other == nil || index >= len(other) {
+			continue
+		}
+		if index >= 0 && strings.Join(other[:index+1], "/") != strings.Join(components[:index+1], "/") {
+			continue
+		}
+		result = append(result, g)
+	}
+	return result
+}
+
+// sorted returns the files sorted by URI.
+func (w *workspace) sorted() []*file {
+	files := make([]*file, 0, len(w.files))
+	for _, f := range w.files {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].uri < files[j].uri })
+	return files
+}
@@ -0,0 +1,22 @@
+// Command msgcheck checks Go code which formats Fluent messages against the
+// reference .ftl files. It is in its own module, so that the fluent-go module
+// does not depend on golang.org/x/tools. It is installed and run by go vet,
+// e.g.
+//
+//	go install github.com/michalnicp/fluent-go/msgcheck/cmd/msgcheck@latest
+//	go vet -vettool=$(which msgcheck) \
+//		-msgcheck.ftl=$PWD/l10n/en-US \
+//		-msgcheck.funcs='(*example.com/app/l10n.Localizer).Format' ./...
+//
+// The paths of the .ftl files should be absolute, because go vet runs the
+// command in the directory of each package. See package msgcheck for the
+// checks.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/michalnicp/fluent-go/msgcheck"
+)
+
+func main() { unitchecker.Main(msgcheck.Analyzer) }
@@ -0,0 +1,17 @@
+module github.com/michalnicp/fluent-go/msgcheck
+
+go 1.22.0
+
+require (
+	github.com/michalnicp/fluent-go v0.0.0-20261018220559-99053692e3b1
+	github.com/stretchr/testify v1.4.0
+	golang.org/x/tools v0.26.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.21.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
@@ -0,0 +1,360 @@
+// Package msgcheck defines an analyzer which checks Go code that formats
+// Fluent messages against the reference .ftl files, so that mistyped message
+// IDs and variables are found by go vet instead of in production.
+//
+// The analyzer checks calls to the functions given with the -funcs flag,
+// whose first string argument is the message ID, unless the index of the
+// argument is given, and whose following argument, if any, holds the
+// variables. Calls with a constant ID are reported if
+//
+//   - the message is not defined in the files given with the -ftl flag,
+//   - the attribute of an ID of the form "message.attribute" is not defined,
+//   - the variables are a map literal, or nil, without a variable which the
+//     message uses, including in the messages it references, or
+//   - the map literal has a constant key which the message never uses.
+//
+// Variables in other expressions than map literals are not checked.
+package msgcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+
+	"github.com/michalnicp/fluent-go/index"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+const doc = `check message IDs and variables of Fluent formatting calls
+
+The analyzer checks calls to the functions given with -funcs, e.g.
+-funcs='(*example.com/l10n.Localizer).Format,example.com/l10n.T:1', whose
+first string argument, or the argument with the index after ":", is a message
+ID and whose following argument holds the variables, against the reference
+.ftl files given with -ftl. It reports unknown messages and
+attributes, variables which the message uses but the call does not pass, and
+variables which the call passes but the message never uses.`
+
+// Analyzer checks calls formatting Fluent messages.
+var Analyzer = &analysis.Analyzer{
+	Name:     "msgcheck",
+	Doc:      doc,
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+var (
+	// ftl is the comma separated .ftl files and directories of the reference
+	// locale.
+	ftl string
+
+	// funcs is the comma separated full names of the functions to check, as
+	// returned by types.Func.FullName, each optionally followed by ":" and
+	// the index of the ID argument.
+	funcs string
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&ftl, "ftl", "", "comma separated .ftl files and directories of the reference locale")
+	Analyzer.Flags.StringVar(&funcs, "funcs", "", "comma separated functions formatting messages, e.g. (*example.com/l10n.Localizer).Format or example.com/l10n.T:1 with the index of the ID argument")
+}
+
+// A reference holds the parsed .ftl files of the reference locale.
+type reference struct {
+	index *index.Index
+	err   error
+}
+
+var (
+	referencesMu sync.Mutex
+	references   = make(map[string]*reference)
+)
+
+// loadReference parses the .ftl files of the paths, once for each value of
+// the flag.
+func loadReference(paths string) *reference {
+	referencesMu.Lock()
+	defer referencesMu.Unlock()
+	if ref, ok := references[paths]; ok {
+		return ref
+	}
+
+	ref := &reference{}
+	references[paths] = ref
+	var resources []syntax.Resource
+	for _, root := range strings.Split(paths, ",") {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !strings.HasSuffix(path, ".ftl") {
+				return err
+			}
+			input, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			resource, err := syntax.Parse(input)
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			resources = append(resources, resource)
+			return nil
+		})
+		if err != nil {
+			ref.err = err
+			return ref
+		}
+	}
+	ref.index = index.New(resources...)
+	return ref
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if ftl == "" || funcs == "" {
+		return nil, nil
+	}
+	ref := loadReference(ftl)
+	if ref.err != nil {
+		return nil, ref.err
+	}
+
+	// checked maps the names of the functions to the index of the ID
+	// argument, or -1 for the first string argument.
+	checked := make(map[string]int)
+	for _, name := range strings.Split(funcs, ",") {
+		name, arg := strings.TrimSpace(name), -1
+		if i := strings.LastIndexByte(name, ':'); i >= 0 {
+			n, err := strconv.Atoi(name[i+1:])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid argument index in -funcs: %q", name)
+			}
+			name, arg = name[:i], n
+		}
+		checked[name] = arg
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok {
+			return
+		}
+		if arg, ok := checked[fn.FullName()]; ok {
+			checkCall(pass, ref.index, call, arg)
+		}
+	})
+	return nil, nil
+}
+
+// checkCall checks a call of a function formatting a message, whose ID is
+// the argument with the index arg, or the first string argument if arg is
+// -1.
+func checkCall(pass *analysis.Pass, x *index.Index, call *ast.CallExpr, arg int) {
+	for i, expr := range call.Args {
+		if arg >= 0 {
+			break
+		}
+		if t, ok := pass.TypesInfo.TypeOf(expr).Underlying().(*types.Basic); ok && t.Info()&types.IsString != 0 {
+			arg = i
+		}
+	}
+	if arg < 0 || arg >= len(call.Args) {
+		return
+	}
+	value := pass.TypesInfo.Types[call.Args[arg]].Value
+	if value == nil || value.Kind() != constant.String {
+		return
+	}
+	id := constant.StringVal(value)
+
+	symbol, err := index.ParseSymbol(id)
+	if err != nil || symbol.Kind != index.Message {
+		pass.Reportf(call.Args[arg].Pos(), "invalid message ID %q", id)
+		return
+	}
+	if !x.Defined(symbol.Parent()) {
+		pass.Reportf(call.Args[arg].Pos(), "unknown message %q%s", symbol.Parent(), suggest(x, symbol.Parent()))
+		return
+	}
+	if !x.Defined(symbol) {
+		pass.Reportf(call.Args[arg].Pos(), "unknown attribute %q%s", symbol, suggest(x, symbol))
+		return
+	}
+
+	if arg+1 >= len(call.Args) {
+		return
+	}
+	used := variables(x, symbol)
+	switch args := unparen(call.Args[arg+1]).(type) {
+	case *ast.Ident:
+		if pass.TypesInfo.Types[args].IsNil() {
+			for _, name := range used {
+				pass.Reportf(args.Pos(), "message %q uses variable $%s, which is not passed", id, name)
+			}
+		}
+	case *ast.CompositeLit:
+		if _, ok := pass.TypesInfo.TypeOf(args).Underlying().(*types.Map); !ok {
+			return
+		}
+		passed := make(map[string]bool)
+		for _, elt := range args.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				return
+			}
+			key := pass.TypesInfo.Types[kv.Key].Value
+			if key == nil || key.Kind() != constant.String {
+				// The variables are unknown.
+				return
+			}
+			name := constant.StringVal(key)
+			passed[name] = true
+			if !contains(used, name) {
+				pass.Reportf(kv.Key.Pos(), "message %q does not use variable $%s", id, name)
+			}
+		}
+		for _, name := range used {
+			if !passed[name] {
+				pass.Reportf(args.Pos(), "message %q uses variable $%s, which is not passed", id, name)
+			}
+		}
+	}
+}
+
+// unparen returns e with any enclosing parentheses removed.
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
+
+// variables returns the sorted names of the variables which a message or
+// attribute uses, including in the messages it references. Terms get their
+// own variables from their arguments, so variables in terms are not used.
+func variables(x *index.Index, symbol index.Symbol) []string {
+	seen := make(map[index.Symbol]bool)
+	names := make(map[string]bool)
+	var visit func(symbol index.Symbol)
+	visit = func(symbol index.Symbol) {
+		if seen[symbol] {
+			return
+		}
+		seen[symbol] = true
+		for _, def := range x.Definitions(symbol) {
+			pattern := value(def)
+			if pattern == nil {
+				continue
+			}
+			syntax.InspectPattern(*pattern, func(expr syntax.Expression) {
+				if v, ok := expr.(syntax.VariableReference); ok {
+					names[v.ID.Name] = true
+				}
+			})
+		}
+		for _, ref := range x.ReferencesFrom(symbol) {
+			if ref.Kind == index.Message {
+				visit(ref.Symbol)
+			}
+		}
+	}
+	visit(symbol)
+
+	var result []string
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// value returns the value of the message or attribute of a definition.
+func value(def index.Definition) *syntax.Pattern {
+	message, ok := def.Entry.(syntax.Message)
+	if !ok {
+		return nil
+	}
+	if def.Attribute == "" {
+		return message.Value
+	}
+	for i, attribute := range message.Attributes {
+		if attribute.ID.Name == def.Attribute {
+			return &message.Attributes[i].Value
+		}
+	}
+	return nil
+}
+
+// suggest returns a hint naming the defined message or attribute which is
+// closest to symbol, if it is likely a typo.
+func suggest(x *index.Index, symbol index.Symbol) string {
+	best, bestDistance := "", len(symbol.String())/3+1
+	for _, s := range x.Symbols() {
+		// Attributes are only suggested for their message.
+		if s.Kind != symbol.Kind || (s.Attribute == "") != (symbol.Attribute == "") || symbol.Attribute != "" && s.ID != symbol.ID {
+			continue
+		}
+		if d := distance(s.String(), symbol.String()); d < bestDistance {
+			best, bestDistance = s.String(), d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
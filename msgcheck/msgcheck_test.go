@@ -0,0 +1,37 @@
+package msgcheck
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	testdata := analysistest.TestData()
+	require.NoError(t, Analyzer.Flags.Set("ftl", filepath.Join(testdata, "en-US")))
+	require.NoError(t, Analyzer.Flags.Set("funcs", "(*l10n.Localizer).Format,l10n.Format:1"))
+	defer Analyzer.Flags.Set("ftl", "")
+	defer Analyzer.Flags.Set("funcs", "")
+
+	analysistest.Run(t, testdata, Analyzer, "app")
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"hello", "hello", 0},
+		{"hello", "helo", 1},
+		{"login.title", "login.titel", 2},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, distance(tt.a, tt.b), "%s %s", tt.a, tt.b)
+		require.Equal(t, tt.want, distance(tt.b, tt.a), "%s %s", tt.b, tt.a)
+	}
+}
@@ -0,0 +1,12 @@
+-brand = { $case ->
+   *[nominative] Firefox
+    [genitive] Firefoxes
+}
+hello = Hello, { $name }!
+welcome = Welcome to { -brand(case: "genitive") }, { hello }
+emails = { $count ->
+    [one] One email
+   *[other] { $count } emails from { $sender }
+}
+login = Log in
+    .title = Log in as { $user }
@@ -0,0 +1,32 @@
+package app
+
+import "l10n"
+
+const loginID = "login"
+
+func f(l *l10n.Localizer, id string, args map[string]interface{}) {
+	l.Format("hello", map[string]interface{}{"name": "Anna"})
+	l.Format("helo", nil)    // want `unknown message "helo", did you mean "hello"\?`
+	l.Format("goodbye", nil) // want `unknown message "goodbye"$`
+	l.Format("-brand", nil)  // want `invalid message ID "-brand"`
+
+	l.Format(loginID, nil)
+	l.Format(loginID+".title", map[string]interface{}{"user": "anna"})
+	l.Format("login.titel", nil) // want `unknown attribute "login.titel", did you mean "login.title"\?`
+
+	l.Format("hello", nil)                              // want `message "hello" uses variable \$name, which is not passed`
+	l.Format("hello", map[string]interface{}{})         // want `message "hello" uses variable \$name, which is not passed`
+	l.Format("hello", map[string]interface{}{"nam": 1}) // want `message "hello" does not use variable \$nam` `message "hello" uses variable \$name, which is not passed`
+
+	// Variables of referenced messages are used, but not those of terms.
+	l.Format("welcome", map[string]interface{}{"name": "Anna", "case": "x"}) // want `message "welcome" does not use variable \$case`
+	l.Format("emails", map[string]interface{}{"count": 2, "sender": "Anna"})
+
+	// Unknown IDs and variables are not checked.
+	l.Format(id, nil)
+	l.Format("hello", args)
+	l.Format("hello", map[string]interface{}{id: 1})
+
+	l10n.Format("en", "goodbye") // want `unknown message "goodbye"`
+	l10n.Other("goodbye", nil)
+}
@@ -0,0 +1,9 @@
+package l10n
+
+type Localizer struct{}
+
+func (l *Localizer) Format(id string, args map[string]interface{}) string { return id }
+
+func Format(lang, id string) string { return id }
+
+func Other(id string, args map[string]interface{}) string { return id }
@@ -0,0 +1,115 @@
+package po
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/segment"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// SyntaxHeader is the header field marking files written by Export, whose
+// strings are Fluent patterns. Its value is the version of the Fluent syntax.
+const SyntaxHeader = "X-Fluent-Syntax"
+
+// Export converts a Fluent resource to a .po file, for translation with
+// gettext tools, and Import converts the translated file back.
+//
+// Each value and attribute of the messages and terms of reference becomes an
+// entry, with the message ID, e.g. "hello" or "-brand", or the ID and the
+// attribute name, e.g. "hello.title", as msgctxt. The msgid is the pattern
+// in Fluent syntax, as returned by syntax.FormatPattern, and the comment of
+// the message becomes the extracted comments of its first entry. The msgstr
+// is the pattern of translation, if given and it has the same message and
+// attribute, or empty.
+func Export(reference syntax.Resource, translation *syntax.Resource) *File {
+	file := &File{
+		Header: []Field{
+			{Name: "MIME-Version", Value: "1.0"},
+			{Name: "Content-Type", Value: "text/plain; charset=UTF-8"},
+			{Name: "Content-Transfer-Encoding", Value: "8bit"},
+			{Name: SyntaxHeader, Value: "1.0"},
+		},
+		Entries: make([]Entry, 0),
+	}
+
+	translated := segment.ByID(translation)
+
+	for _, entry := range reference.Body {
+		for i, p := range segment.Patterns(entry) {
+			str := ""
+			if pattern, ok := translated[p.ID]; ok {
+				str = syntax.FormatPattern(pattern)
+			}
+			e := Entry{
+				Context: p.ID,
+				ID:      syntax.FormatPattern(p.Pattern),
+				Str:     []string{str},
+			}
+			if i == 0 && p.Comment != nil {
+				e.ExtractedComments = strings.Split(p.Comment.Content, "\n")
+			}
+			file.Entries = append(file.Entries, e)
+		}
+	}
+
+	return file
+}
+
+// Import converts a .po file written by Export back to a Fluent resource.
+//
+// The msgstr of each entry is parsed as a Fluent pattern. Messages and terms
+// are only imported if all their entries are translated and, unless
+// Options.Fuzzy is set, not fuzzy. With Options.Source, the msgid is imported
+// instead, and Options.Prefix is prepended to the message IDs. The other
+// options are ignored.
+//
+// Entries whose msgctxt is not an ID or whose msgstr is not a valid pattern
+// are reported in the error, which joins an error for each of them, and their
+// messages are left out. The resource holds the other messages even if the
+// error is not nil.
+func Import(file *File, options Options) (syntax.Resource, error) {
+	var (
+		b    segment.Builder
+		errs []error
+	)
+
+	for _, entry := range file.Entries {
+		id, attribute, ok := segment.SplitID(entry.Context)
+		if !ok {
+			errs = append(errs, fmt.Errorf("msgctxt %q is not a message ID", entry.Context))
+			continue
+		}
+
+		msg := b.Message(id)
+		if msg.Comment == nil && len(entry.ExtractedComments) > 0 {
+			msg.Comment = &syntax.Comment{Content: strings.Join(entry.ExtractedComments, "\n")}
+		}
+
+		if entry.IDPlural != "" {
+			errs = append(errs, fmt.Errorf("%s: unexpected plural entry", entry.Context))
+			msg.Skip = true
+			continue
+		}
+		text := entry.ID
+		if !options.Source {
+			if !entry.Translated() || entry.HasFlag("fuzzy") && !options.Fuzzy {
+				msg.Skip = true
+				continue
+			}
+			text = entry.Str[0]
+		}
+
+		pattern, err := syntax.ParsePattern(text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", entry.Context, err))
+			msg.Skip = true
+			continue
+		}
+		msg.SetPattern(attribute, pattern)
+	}
+
+	resource, err := b.Resource(options.Prefix)
+	return resource, errors.Join(append(errs, err)...)
+}
@@ -0,0 +1,190 @@
+package po
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestExport(t *testing.T) {
+	reference, err := syntax.Parse([]byte(`# Greets the user.
+hello = Hello, { $name }!
+    .title = Greeting
+-brand = Firefox
+emails =
+    { $count ->
+        [one] One email
+       *[other] { $count } emails
+    }
+`))
+	require.NoError(t, err)
+	translation, err := syntax.Parse([]byte("hello = Hallo, { $name }!\n-brand = Firefox\n"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Export(reference, &translation)))
+	require.Equal(t, `msgid ""
+msgstr ""
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"X-Fluent-Syntax: 1.0\n"
+
+#. Greets the user.
+msgctxt "hello"
+msgid "Hello, { $name }!"
+msgstr "Hallo, { $name }!"
+
+msgctxt "hello.title"
+msgid "Greeting"
+msgstr ""
+
+msgctxt "-brand"
+msgid "Firefox"
+msgstr "Firefox"
+
+msgctxt "emails"
+msgid ""
+"{ $count ->\n"
+"    [one] One email\n"
+"   *[other] { $count } emails\n"
+"}"
+msgstr ""
+`, buf.String())
+}
+
+func TestImport(t *testing.T) {
+	input := `msgid ""
+msgstr "X-Fluent-Syntax: 1.0\n"
+
+#. Greets the user.
+msgctxt "hello"
+msgid "Hello, { $name }!"
+msgstr "Hallo, { $name }!"
+
+msgctxt "hello.title"
+msgid "Greeting"
+msgstr "Begrüßung"
+
+msgctxt "untranslated"
+msgid "Untranslated"
+msgstr ""
+
+msgctxt "partial"
+msgid "Value"
+msgstr "Wert"
+
+msgctxt "partial.title"
+msgid "Title"
+msgstr ""
+
+#, fuzzy
+msgctxt "-brand"
+msgid "Firefox"
+msgstr "Firefox"
+
+msgctxt "emails"
+msgid ""
+"{ $count ->\n"
+"    [one] One email\n"
+"   *[other] { $count } emails\n"
+"}"
+msgstr ""
+"{ $count ->\n"
+"   *[other] { $count } E-Mails\n"
+"}"
+
+msgctxt "invalid"
+msgid "Hello, { $name }!"
+msgstr "Hallo, { $name !"
+
+msgctxt "not an id"
+msgid "x"
+msgstr "y"
+`
+	file, err := Parse([]byte(input))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		options  Options
+		expected string
+	}{
+		{
+			name:    "translations",
+			options: Options{},
+			expected: `# Greets the user.
+hello = Hallo, { $name }!
+    .title = Begrüßung
+emails =
+    { $count ->
+       *[other] { $count } E-Mails
+    }
+`,
+		},
+		{
+			name:    "fuzzy",
+			options: Options{Fuzzy: true, Prefix: "app-"},
+			expected: `# Greets the user.
+app-hello = Hallo, { $name }!
+    .title = Begrüßung
+-app-brand = Firefox
+app-emails =
+    { $count ->
+       *[other] { $count } E-Mails
+    }
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, err := Import(file, tt.options)
+			require.EqualError(t, err, "invalid: 1:16: Expected token: \"}\"\nmsgctxt \"not an id\" is not a message ID")
+
+			var buf bytes.Buffer
+			require.NoError(t, syntax.Fprint(&buf, resource))
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+// TestExportImport checks that exported fixtures import to the same
+// resource, without comments other than those of messages and terms.
+func TestExportImport(t *testing.T) {
+	input := []byte(`## Group
+
+# Comment
+key = Value
+    .attr = { "[" }Attribute
+-term = { $case ->
+   *[nominative] Term
+    [genitive] Term's
+}
+    .gender = masculine
+attributes-only =
+    .a = A
+multiline =
+    Line 1
+      Line 2
+`)
+	reference, err := syntax.Parse(input)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Export(reference, nil)))
+	file, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, "1.0", file.HeaderField(SyntaxHeader))
+
+	resource, err := Import(file, Options{Source: true})
+	require.NoError(t, err)
+
+	var expected, actual bytes.Buffer
+	require.NoError(t, syntax.Fprint(&expected, syntax.Resource{Body: reference.Body[1:]}))
+	require.NoError(t, syntax.Fprint(&actual, resource))
+	require.Equal(t, expected.String(), actual.String())
+}
@@ -0,0 +1,252 @@
+package po
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/ident"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Options configure the conversion of a .po file to Fluent.
+type Options struct {
+	// Language is the language of the translations, e.g. "pt_BR", which
+	// selects the plural categories. Defaults to the Language header.
+	Language string
+
+	// Source converts the msgid and msgid_plural strings instead of the
+	// translations, e.g. to create the reference locale from a .pot file.
+	Source bool
+
+	// Fuzzy includes translations flagged as fuzzy, which are skipped by
+	// default like gettext does.
+	Fuzzy bool
+
+	// Context derives the IDs of messages with a msgctxt from the context
+	// instead of the msgid.
+	Context bool
+
+	// Prefix is prepended to all IDs.
+	Prefix string
+}
+
+// CountVariable is the variable plural messages select on.
+const CountVariable = "count"
+
+// ToFluent converts a .po file to a Fluent resource.
+//
+// Each entry becomes a message, with an ID derived from its msgid, or its
+// msgctxt if Options.Context is set, e.g. "Open file" becomes "open-file".
+// IDs are made unique by appending a number. The extracted and translator
+// comments become the comment of the message. Entries which are not
+// translated, or not in all plural forms, are skipped, unless Options.Source
+// is set.
+//
+// Plural entries become a select expression on $count, with a variant for
+// each plural form, keyed by its CLDR plural category, or [0] for a form which
+// only 0 selects, e.g. the first form of Latvian. The form of the category
+// other is the default or, if there is none, the last form, which the else
+// branch of the Plural-Forms formula selects.
+//
+// In entries flagged as c-format, python-format or another printf format,
+// placeholders become variables: %(name)s becomes $name and the other
+// placeholders become $arg1, $arg2 and so on by their position, except the
+// first integer of a plural entry, which becomes $count.
+func ToFluent(file *File, options Options) (syntax.Resource, error) {
+	resource := syntax.Resource{Body: make([]syntax.Entry, 0)}
+
+	language := options.Language
+	if language == "" {
+		language = file.HeaderField("Language")
+	}
+	forms := nplurals(file.HeaderField("Plural-Forms"))
+
+	ids := make(map[string]bool)
+	for _, entry := range file.Entries {
+		if !options.Source && (!entry.Translated() || entry.HasFlag("fuzzy") && !options.Fuzzy) {
+			continue
+		}
+
+		format := isPrintfFormat(entry.Flags)
+
+		text := entry.ID
+		if format {
+			text = withoutPlaceholders(text)
+		}
+		id := options.Prefix + ident.Slug(text)
+		if options.Context && entry.Context != "" {
+			id = options.Prefix + ident.Slug(entry.Context)
+		}
+		id = ident.Unique(ids, id)
+
+		var value syntax.Pattern
+		switch {
+		case entry.IDPlural == "":
+			str := entry.ID
+			if !options.Source {
+				str = entry.Str[0]
+			}
+			value = pattern(str, format, false)
+		case options.Source:
+			value = selectPattern([]string{entry.ID, entry.IDPlural}, []string{"one", "other"}, format)
+		default:
+			n := forms
+			if n == 0 {
+				n = len(entry.Str)
+			}
+			categories, err := PluralCategories(language, n)
+			if err != nil {
+				return resource, err
+			}
+			if len(entry.Str) != len(categories) {
+				return resource, fmt.Errorf("msgid %q has %d plural forms, expected %d", entry.ID, len(entry.Str), len(categories))
+			}
+			value = selectPattern(entry.Str, categories, format)
+		}
+
+		message := syntax.Message{
+			ID:         syntax.Identifier{Name: id},
+			Value:      &value,
+			Attributes: make([]syntax.Attribute, 0),
+		}
+		var comments []string
+		comments = append(comments, entry.ExtractedComments...)
+		comments = append(comments, entry.Comments...)
+		if len(comments) > 0 {
+			message.Comment = &syntax.Comment{Content: strings.Join(comments, "\n")}
+		}
+		resource.Body = append(resource.Body, message)
+	}
+
+	return resource, nil
+}
+
+// printfFormats are the formats of the flags of entries with printf
+// placeholders.
+var printfFormats = []string{"c-format", "objc-format", "python-format", "php-format", "awk-format", "gcc-internal-format"}
+
+func isPrintfFormat(flags []string) bool {
+	for _, flag := range flags {
+		for _, format := range printfFormats {
+			if flag == format {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// placeholderPattern matches a printf placeholder, with an optional position
+// as in %1$s or name as in %(name)s, flags, width, precision, length and
+// conversion.
+var placeholderPattern = regexp.MustCompile(`%(?:(\d+)\$|\(([A-Za-z_][A-Za-z0-9_]*)\))?[-+ #0']*(?:\d+|\*)?(?:\.(\d+|\*))?(?:hh|h|ll|l|L|q|j|z|t)?([diouxXeEfFgGaAcsp%])`)
+
+// withoutPlaceholders returns text with printf placeholders replaced by their
+// names, or removed if they have none.
+func withoutPlaceholders(text string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		m := placeholderPattern.FindStringSubmatch(placeholder)
+		return " " + m[2] + " "
+	})
+}
+
+// pattern converts text to a pattern. With format, printf placeholders become
+// variables. With plural, the first integer placeholder becomes $count.
+func pattern(text string, format, plural bool) syntax.Pattern {
+	elements := make([]syntax.PatternElement, 0)
+	appendText := func(s string) {
+		if s == "" {
+			return
+		}
+		if n := len(elements); n > 0 {
+			if text, ok := elements[n-1].(syntax.TextElement); ok {
+				elements[n-1] = syntax.TextElement{Value: text.Value + s}
+				return
+			}
+		}
+		elements = append(elements, syntax.TextElement{Value: s})
+	}
+
+	if !format {
+		appendText(text)
+		return syntax.Pattern{Elements: elements}
+	}
+
+	arg := 0
+	count := false
+	last := 0
+	for _, m := range placeholderPattern.FindAllStringSubmatchIndex(text, -1) {
+		appendText(text[last:m[0]])
+		last = m[1]
+
+		conversion := text[m[8]:m[9]]
+		if conversion == "%" {
+			appendText("%")
+			continue
+		}
+
+		var name string
+		switch {
+		case m[4] >= 0:
+			name = text[m[4]:m[5]]
+			if name[0] == '_' {
+				name = "var" + name
+			}
+		case m[2] >= 0:
+			name = "arg" + text[m[2]:m[3]]
+		default:
+			arg++
+			name = "arg" + strconv.Itoa(arg)
+		}
+		if plural && !count && name == "arg1" && strings.ContainsAny(conversion, "diu") {
+			name, count = CountVariable, true
+		}
+
+		var expr syntax.Expression = syntax.VariableReference{ID: syntax.Identifier{Name: name}}
+		if m[6] >= 0 && strings.ContainsAny(conversion, "fFeEgG") && text[m[6]:m[7]] != "*" {
+			// Keep the precision of floats, as in %.2f.
+			digits := syntax.NumberLiteral{Value: text[m[6]:m[7]]}
+			expr = syntax.FunctionReference{
+				ID: syntax.Identifier{Name: "NUMBER"},
+				Arguments: syntax.CallArguments{
+					Positional: []syntax.InlineExpression{syntax.VariableReference{ID: syntax.Identifier{Name: name}}},
+					Named: []syntax.NamedArgument{
+						{Name: syntax.Identifier{Name: "minimumFractionDigits"}, Value: digits},
+						{Name: syntax.Identifier{Name: "maximumFractionDigits"}, Value: digits},
+					},
+				},
+			}
+		}
+		elements = append(elements, syntax.Placeable{Expr: expr})
+	}
+	appendText(text[last:])
+
+	return syntax.Pattern{Elements: elements}
+}
+
+// selectPattern returns a pattern selecting the plural form of $count, whose
+// keys are the categories, as returned by PluralCategories.
+func selectPattern(forms, categories []string, format bool) syntax.Pattern {
+	variants := make([]syntax.Variant, len(forms))
+	def := defaultForm(categories)
+	for i, form := range forms {
+		var key syntax.VariantKey = syntax.Identifier{Name: categories[i]}
+		if categories[i] == "0" {
+			key = syntax.NumberLiteral{Value: categories[i]}
+		}
+		variants[i] = syntax.Variant{
+			Key:     key,
+			Value:   pattern(form, format, true),
+			Default: i == def,
+		}
+	}
+
+	return syntax.Pattern{Elements: []syntax.PatternElement{
+		syntax.Placeable{Expr: syntax.SelectExpression{
+			Selector: syntax.VariableReference{ID: syntax.Identifier{Name: CountVariable}},
+			Variants: variants,
+		}},
+	}}
+}
@@ -0,0 +1,227 @@
+package po
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestToFluent(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		input    string
+		expected string
+	}{
+		{
+			name: "messages",
+			input: `#. Shown in the menu.
+# Keep it short.
+msgid "Open file…"
+msgstr "Datei öffnen…"
+
+msgid "open file"
+msgstr "Öffne Datei"
+
+msgid "Untranslated"
+msgstr ""
+
+#, fuzzy
+msgid "Fuzzy"
+msgstr "Unscharf"
+
+msgid "123"
+msgstr "  {123}  "
+`,
+			expected: `# Shown in the menu.
+# Keep it short.
+open-file = Datei öffnen…
+open-file-2 = Öffne Datei
+msg-123 = { "  " }{ "{" }123{ "}" }{ "  " }
+`,
+		},
+		{
+			name:    "context",
+			options: Options{Context: true, Prefix: "app-"},
+			input: `msgctxt "File|Open"
+msgid "Open"
+msgstr "Öffnen"
+
+msgid "Open"
+msgstr "Öffnen"
+`,
+			expected: "app-file-open = Öffnen\napp-open = Öffnen\n",
+		},
+		{
+			name: "placeholders",
+			input: `#, c-format
+msgid "%s of %s, 100%%"
+msgstr "%2$s von %1$s, 100%%"
+
+#, python-format
+msgid "Hello %(user_name)s, %(_x)d"
+msgstr "Hallo %(user_name)s, %(_x)d"
+
+#, c-format
+msgid "%.2f MB"
+msgstr "%.2f MB"
+
+msgid "Not a format: 100% done"
+msgstr "Kein Format: 100% fertig"
+`,
+			expected: `of-100 = { $arg2 } von { $arg1 }, 100%
+hello-user-name-x = Hallo { $user_name }, { $var_x }
+mb = { NUMBER($arg1, minimumFractionDigits: 2, maximumFractionDigits: 2) } MB
+not-a-format-100-done = Kein Format: 100% fertig
+`,
+		},
+		{
+			name: "plurals",
+			input: `msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#, c-format
+msgid "%d file in %s"
+msgid_plural "%d files in %s"
+msgstr[0] "%d файл в %s"
+msgstr[1] "%d файла в %s"
+msgstr[2] "%d файлов в %s"
+
+msgid "Incomplete"
+msgid_plural "Incompletes"
+msgstr[0] "a"
+msgstr[1] ""
+msgstr[2] "c"
+`,
+			expected: `file-in =
+    { $count ->
+        [one] { $count } файл в { $arg2 }
+        [few] { $count } файла в { $arg2 }
+       *[many] { $count } файлов в { $arg2 }
+    }
+`,
+		},
+		{
+			name:    "source",
+			options: Options{Source: true},
+			input: `msgid ""
+msgstr ""
+"Language: ja\n"
+
+msgid "Untranslated"
+msgstr ""
+
+#, c-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+`,
+			expected: `untranslated = Untranslated
+file =
+    { $count ->
+        [one] { $count } file
+       *[other] { $count } files
+    }
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := Parse([]byte(tt.input))
+			require.NoError(t, err)
+
+			resource, err := ToFluent(file, tt.options)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, syntax.Fprint(&buf, resource))
+			require.Equal(t, tt.expected, buf.String())
+
+			_, err = syntax.Parse(buf.Bytes())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestToFluentUnknownPlurals(t *testing.T) {
+	file, err := Parse([]byte("msgid \"a\"\nmsgid_plural \"b\"\nmsgstr[0] \"x\"\nmsgstr[1] \"y\"\nmsgstr[2] \"z\"\n"))
+	require.NoError(t, err)
+
+	_, err = ToFluent(file, Options{Language: "xx"})
+	require.EqualError(t, err, `unknown plural categories of language "xx" with 3 forms`)
+}
+
+func TestPluralCategories(t *testing.T) {
+	categories, err := PluralCategories("pt_BR", 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"one", "other"}, categories)
+
+	categories, err = PluralCategories("ar", 6)
+	require.NoError(t, err)
+	require.Equal(t, []string{"0", "one", "two", "few", "many", "other"}, categories)
+
+	categories, err = PluralCategories("xx", 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"other"}, categories)
+}
+
+func TestToFluentPluralZero(t *testing.T) {
+	file, err := Parse([]byte(`msgid ""
+msgstr ""
+"Language: lv\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2);\n"
+
+#, c-format
+msgid "%d item"
+msgid_plural "%d items"
+msgstr[0] "%d vienums"
+msgstr[1] "%d vienumi"
+msgstr[2] "nav vienumu"
+`))
+	require.NoError(t, err)
+
+	resource, err := ToFluent(file, Options{})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, syntax.Fprint(&buf, resource))
+	require.Equal(t, `item =
+    { $count ->
+        [one] { $count } vienums
+       *[other] { $count } vienumi
+        [0] nav vienumu
+    }
+`, buf.String())
+
+	// Fluent selects a variant by the exact number first, then by the CLDR
+	// category, which is zero for 10 to 20 in Latvian, then by the default.
+	selectExpr := resource.Body[0].(syntax.Message).Value.Elements[0].(syntax.Placeable).Expr.(syntax.SelectExpression)
+	selectVariant := func(n, category string) string {
+		for _, variant := range selectExpr.Variants {
+			if key, ok := variant.Key.(syntax.NumberLiteral); ok && key.Value == n {
+				return n
+			}
+		}
+		for _, variant := range selectExpr.Variants {
+			if key, ok := variant.Key.(syntax.Identifier); ok && key.Name == category {
+				return category
+			}
+		}
+		for _, variant := range selectExpr.Variants {
+			if variant.Default {
+				return variant.Key.(syntax.Identifier).Name
+			}
+		}
+		return ""
+	}
+	require.Equal(t, "0", selectVariant("0", "zero"))
+	require.Equal(t, "one", selectVariant("1", "one"))
+	require.Equal(t, "other", selectVariant("2", "other"))
+	require.Equal(t, "other", selectVariant("10", "zero"))
+}
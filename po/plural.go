@@ -0,0 +1,112 @@
+package po
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pluralCategories maps languages to the variant keys of the plural forms of
+// gettext, in the order of their index, as given by the Plural-Forms formulas
+// commonly used for the languages. The keys are the CLDR plural categories of
+// the integers which select the forms, as gettext does not handle fractions,
+// or "0" for forms which only 0 selects: CLDR's zero category also covers
+// other numbers in some languages, e.g. 10 to 20 in Latvian.
+var pluralCategories = map[string][]string{
+	// One form.
+	"id": {"other"}, "ja": {"other"}, "km": {"other"}, "ko": {"other"},
+	"lo": {"other"}, "ms": {"other"}, "my": {"other"}, "th": {"other"},
+	"vi": {"other"}, "zh": {"other"},
+
+	// n != 1, or n > 1 for French and Portuguese.
+	"af": {"one", "other"}, "bg": {"one", "other"}, "ca": {"one", "other"},
+	"da": {"one", "other"}, "de": {"one", "other"}, "el": {"one", "other"},
+	"en": {"one", "other"}, "eo": {"one", "other"}, "es": {"one", "other"},
+	"et": {"one", "other"}, "eu": {"one", "other"}, "fa": {"one", "other"},
+	"fi": {"one", "other"}, "fr": {"one", "other"}, "fy": {"one", "other"},
+	"gl": {"one", "other"}, "he": {"one", "other"}, "hi": {"one", "other"},
+	"hu": {"one", "other"}, "hy": {"one", "other"}, "is": {"one", "other"},
+	"it": {"one", "other"}, "ka": {"one", "other"}, "kk": {"one", "other"},
+	"mk": {"one", "other"}, "nb": {"one", "other"}, "nl": {"one", "other"},
+	"nn": {"one", "other"}, "no": {"one", "other"}, "pt": {"one", "other"},
+	"sq": {"one", "other"}, "sv": {"one", "other"}, "sw": {"one", "other"},
+	"tr": {"one", "other"}, "ur": {"one", "other"}, "uz": {"one", "other"},
+
+	// n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2
+	"be": {"one", "few", "many"}, "bs": {"one", "few", "other"},
+	"hr": {"one", "few", "other"}, "ru": {"one", "few", "many"},
+	"sr": {"one", "few", "other"}, "uk": {"one", "few", "many"},
+
+	// n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2
+	"pl": {"one", "few", "many"},
+
+	// n==1 ? 0 : n>=2 && n<=4 ? 1 : 2
+	"cs": {"one", "few", "other"}, "sk": {"one", "few", "other"},
+
+	// n%10==1 && n%100!=11 ? 0 : n%10>=2 && (n%100<10 || n%100>=20) ? 1 : 2
+	"lt": {"one", "few", "other"},
+
+	// n%10==1 && n%100!=11 ? 0 : n != 0 ? 1 : 2
+	"lv": {"one", "other", "0"},
+
+	// n==1 ? 0 : (n==0 || (n%100>0 && n%100<20)) ? 1 : 2
+	"ro": {"one", "few", "other"},
+
+	// n%100==1 ? 0 : n%100==2 ? 1 : n%100==3 || n%100==4 ? 2 : 3
+	"sl": {"one", "two", "few", "other"},
+
+	// n==1 ? 0 : n==2 ? 1 : n<7 ? 2 : n<11 ? 3 : 4
+	"ga": {"one", "two", "few", "many", "other"},
+
+	// n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5
+	"ar": {"0", "one", "two", "few", "many", "other"},
+}
+
+// PluralCategories returns the variant keys of the plural forms of gettext for
+// a language, e.g. "pt_BR", with nplurals forms: CLDR plural categories, or
+// "0" for a form which only 0 selects. Languages which are not known are
+// supported if they have one or two forms.
+func PluralCategories(language string, nplurals int) ([]string, error) {
+	base := strings.ToLower(language)
+	if i := strings.IndexAny(base, "_-@."); i >= 0 {
+		base = base[:i]
+	}
+
+	if categories, ok := pluralCategories[base]; ok && len(categories) == nplurals {
+		return categories, nil
+	}
+	switch nplurals {
+	case 1:
+		return []string{"other"}, nil
+	case 2:
+		return []string{"one", "other"}, nil
+	default:
+		return nil, fmt.Errorf("unknown plural categories of language %q with %d forms", language, nplurals)
+	}
+}
+
+// defaultForm returns the index of the plural form which is the default
+// variant: the form of the category other or, if there is none, the last form,
+// which the else branch of the Plural-Forms formulas selects.
+func defaultForm(categories []string) int {
+	for i, category := range categories {
+		if category == "other" {
+			return i
+		}
+	}
+	return len(categories) - 1
+}
+
+var npluralsPattern = regexp.MustCompile(`nplurals\s*=\s*(\d+)`)
+
+// nplurals returns the number of plural forms in the Plural-Forms header, or 0
+// if there is none.
+func nplurals(pluralForms string) int {
+	m := npluralsPattern.FindStringSubmatch(pluralForms)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
@@ -0,0 +1,439 @@
+// Package po converts gettext .po and .pot files to and from Fluent.
+package po
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A File is a parsed .po or .pot file.
+type File struct {
+	// Comments are the translator comments of the header.
+	Comments []string
+
+	// Header holds the fields of the header entry, in order.
+	Header []Field
+
+	Entries []Entry
+}
+
+// A Field is a field of the header, e.g. "Language: de".
+type Field struct {
+	Name  string
+	Value string
+}
+
+// An Entry is a message of a .po file.
+type Entry struct {
+	// Comments are the translator comments, written as "# comment".
+	Comments []string
+
+	// ExtractedComments are the comments extracted from the source code,
+	// written as "#. comment".
+	ExtractedComments []string
+
+	// References are the source locations, written as "#: file:line".
+	References []string
+
+	// Flags are e.g. "fuzzy" and "c-format", written as "#, flag".
+	Flags []string
+
+	Context  string
+	ID       string
+	IDPlural string
+
+	// Str holds the translation, or the translations of the plural forms
+	// if IDPlural is set.
+	Str []string
+}
+
+// HeaderField returns the value of the header field with the name, or "".
+func (f *File) HeaderField(name string) string {
+	for _, field := range f.Header {
+		if strings.EqualFold(field.Name, name) {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// HasFlag reports whether the entry has the flag.
+func (e *Entry) HasFlag(flag string) bool {
+	for _, f := range e.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// Translated reports whether the entry has a translation, of all plural forms
+// if it is a plural entry.
+func (e *Entry) Translated() bool {
+	for _, s := range e.Str {
+		if s == "" {
+			return false
+		}
+	}
+	return len(e.Str) > 0
+}
+
+// Parse parses a .po or .pot file. Obsolete entries, written as "#~", and
+// previous strings, written as "#|", are skipped.
+func Parse(data []byte) (*File, error) {
+	p := parser{file: &File{}}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		p.line++
+		if err := p.parseLine(strings.TrimSpace(scanner.Text())); err != nil {
+			return nil, fmt.Errorf("line %d: %v", p.line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := p.flush(); err != nil {
+		return nil, fmt.Errorf("line %d: %v", p.line, err)
+	}
+	return p.file, nil
+}
+
+type parser struct {
+	file *File
+	line int
+
+	entry Entry
+
+	// keyword is the keyword of the last string, which continuation lines
+	// append to, e.g. "msgid" or "msgstr[1]".
+	keyword string
+
+	// hasID is whether the entry has a msgid, so that a comment starts the
+	// next entry.
+	hasID bool
+}
+
+func (p *parser) parseLine(line string) error {
+	switch {
+	case line == "":
+		return p.flush()
+	case strings.HasPrefix(line, "#~"), strings.HasPrefix(line, "#|"):
+		return nil
+	case strings.HasPrefix(line, "#"):
+		if p.hasID {
+			if err := p.flush(); err != nil {
+				return err
+			}
+		}
+		p.parseComment(line)
+		return nil
+	case strings.HasPrefix(line, `"`):
+		if p.keyword == "" {
+			return fmt.Errorf("string without keyword")
+		}
+		s, err := unquote(line)
+		if err != nil {
+			return err
+		}
+		p.appendString(s)
+		return nil
+	}
+
+	i := strings.IndexAny(line, " \t")
+	if i < 0 {
+		return fmt.Errorf("expected a keyword and a string, got %q", line)
+	}
+	keyword, rest := line[:i], strings.TrimSpace(line[i:])
+	s, err := unquote(rest)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case keyword == "msgctxt" || keyword == "msgid" && !p.hasContext():
+		if p.hasID {
+			if err := p.flush(); err != nil {
+				return err
+			}
+		}
+	case keyword == "msgid", keyword == "msgid_plural", keyword == "msgstr":
+	case strings.HasPrefix(keyword, "msgstr[") && strings.HasSuffix(keyword, "]"):
+		n, err := strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1])
+		if err != nil || n != len(p.entry.Str) {
+			return fmt.Errorf("unexpected %s", keyword)
+		}
+		p.entry.Str = append(p.entry.Str, "")
+	default:
+		return fmt.Errorf("unknown keyword %s", keyword)
+	}
+
+	p.keyword = keyword
+	switch keyword {
+	case "msgid":
+		p.hasID = true
+	case "msgstr":
+		p.entry.Str = []string{""}
+	}
+	p.appendString(s)
+	return nil
+}
+
+// hasContext reports whether the entry has a msgctxt but no msgid yet.
+func (p *parser) hasContext() bool {
+	return p.keyword == "msgctxt"
+}
+
+func (p *parser) parseComment(line string) {
+	kind, text := line[:1], line[1:]
+	if len(line) > 1 {
+		switch line[1] {
+		case '.', ':', ',':
+			kind, text = line[:2], line[2:]
+		}
+	}
+	text = strings.TrimPrefix(text, " ")
+
+	switch kind {
+	case "#":
+		p.entry.Comments = append(p.entry.Comments, text)
+	case "#.":
+		p.entry.ExtractedComments = append(p.entry.ExtractedComments, text)
+	case "#:":
+		p.entry.References = append(p.entry.References, strings.Fields(text)...)
+	case "#,":
+		for _, flag := range strings.Split(text, ",") {
+			if flag = strings.TrimSpace(flag); flag != "" {
+				p.entry.Flags = append(p.entry.Flags, flag)
+			}
+		}
+	}
+}
+
+func (p *parser) appendString(s string) {
+	switch {
+	case p.keyword == "msgctxt":
+		p.entry.Context += s
+	case p.keyword == "msgid":
+		p.entry.ID += s
+	case p.keyword == "msgid_plural":
+		p.entry.IDPlural += s
+	default: // msgstr or msgstr[n]
+		p.entry.Str[len(p.entry.Str)-1] += s
+	}
+}
+
+// flush ends the current entry. The entry with an empty msgid and no context
+// is the header.
+func (p *parser) flush() error {
+	entry := p.entry
+	p.entry, p.keyword, p.hasID = Entry{}, "", false
+
+	if entry.ID == "" && entry.Context == "" && entry.Str == nil {
+		return nil // comments without an entry
+	}
+	if entry.Str == nil {
+		return fmt.Errorf("missing msgstr for %q", entry.ID)
+	}
+
+	if entry.ID == "" && entry.Context == "" {
+		if p.file.Header != nil || len(p.file.Entries) > 0 {
+			return fmt.Errorf("empty msgid")
+		}
+		p.file.Comments = entry.Comments
+		p.file.Header = parseHeader(entry.Str[0])
+		return nil
+	}
+
+	p.file.Entries = append(p.file.Entries, entry)
+	return nil
+}
+
+func parseHeader(s string) []Field {
+	fields := make([]Field, 0)
+	for _, line := range strings.Split(s, "\n") {
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		fields = append(fields, Field{
+			Name:  strings.TrimSpace(line[:i]),
+			Value: strings.TrimSpace(line[i+1:]),
+		})
+	}
+	return fields
+}
+
+// unquote unquotes a C string literal, as written by xgettext.
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("expected a quoted string, got %s", s)
+	}
+	s = s[1 : len(s)-1]
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' {
+			return "", fmt.Errorf("unescaped quote in string")
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", fmt.Errorf("unterminated escape sequence")
+		}
+		switch c := s[i]; c {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '\\', '"', '\'', '?':
+			b.WriteByte(c)
+		case 'x':
+			j := i + 1
+			for j < len(s) && j < i+3 && isHex(s[j]) {
+				j++
+			}
+			n, err := strconv.ParseUint(s[i+1:j], 16, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence \\x%s", s[i+1:j])
+			}
+			b.WriteByte(byte(n))
+			i = j - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(s) && j < i+3 && '0' <= s[j] && s[j] <= '7' {
+				j++
+			}
+			n, err := strconv.ParseUint(s[i:j], 8, 8)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence \\%s", s[i:j])
+			}
+			b.WriteByte(byte(n))
+			i = j - 1
+		default:
+			return "", fmt.Errorf("invalid escape sequence \\%c", c)
+		}
+	}
+	return b.String(), nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// Write writes the file in .po format.
+func Write(w io.Writer, file *File) error {
+	var buf bytes.Buffer
+
+	writeComments(&buf, "#", file.Comments)
+	var header strings.Builder
+	for _, field := range file.Header {
+		header.WriteString(field.Name + ": " + field.Value + "\n")
+	}
+	writeString(&buf, "msgid", "")
+	writeString(&buf, "msgstr", header.String())
+
+	for _, entry := range file.Entries {
+		buf.WriteByte('\n')
+		writeComments(&buf, "#", entry.Comments)
+		writeComments(&buf, "#.", entry.ExtractedComments)
+		if len(entry.References) > 0 {
+			buf.WriteString("#: " + strings.Join(entry.References, " ") + "\n")
+		}
+		if len(entry.Flags) > 0 {
+			buf.WriteString("#, " + strings.Join(entry.Flags, ", ") + "\n")
+		}
+		if entry.Context != "" {
+			writeString(&buf, "msgctxt", entry.Context)
+		}
+		writeString(&buf, "msgid", entry.ID)
+		if entry.IDPlural == "" {
+			str := ""
+			if len(entry.Str) > 0 {
+				str = entry.Str[0]
+			}
+			writeString(&buf, "msgstr", str)
+			continue
+		}
+		writeString(&buf, "msgid_plural", entry.IDPlural)
+		for i, str := range entry.Str {
+			writeString(&buf, "msgstr["+strconv.Itoa(i)+"]", str)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeComments(buf *bytes.Buffer, prefix string, comments []string) {
+	for _, comment := range comments {
+		if comment == "" {
+			buf.WriteString(prefix + "\n")
+		} else {
+			buf.WriteString(prefix + " " + comment + "\n")
+		}
+	}
+}
+
+// writeString writes a keyword and a quoted string. Strings with line breaks
+// are written with one line per string, after an empty first string.
+func writeString(buf *bytes.Buffer, keyword, s string) {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= 1 {
+		buf.WriteString(keyword + " " + quote(s) + "\n")
+		return
+	}
+	buf.WriteString(keyword + " \"\"\n")
+	for _, line := range lines {
+		buf.WriteString(quote(line) + "\n")
+	}
+}
+
+// quote quotes s as a C string literal.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
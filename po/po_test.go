@@ -0,0 +1,149 @@
+package po
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	input := `# Translation of the app.
+msgid ""
+msgstr ""
+"Language: de\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#. Shown in the menu.
+# Keep it short.
+#: src/menu.c:12 src/menu.c:40
+#, c-format, fuzzy
+msgctxt "menu"
+msgid "Open %s"
+msgstr "%s öffnen"
+
+msgid ""
+"Multiple "
+"lines\n"
+msgstr "Mehrere\tZeilen\n\"\\\101\x42"
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+
+#~ msgid "Obsolete"
+#~ msgstr "Veraltet"
+`
+
+	file, err := Parse([]byte(input))
+	require.NoError(t, err)
+	require.Equal(t, &File{
+		Comments: []string{"Translation of the app."},
+		Header: []Field{
+			{Name: "Language", Value: "de"},
+			{Name: "Plural-Forms", Value: "nplurals=2; plural=(n != 1);"},
+		},
+		Entries: []Entry{
+			{
+				Comments:          []string{"Keep it short."},
+				ExtractedComments: []string{"Shown in the menu."},
+				References:        []string{"src/menu.c:12", "src/menu.c:40"},
+				Flags:             []string{"c-format", "fuzzy"},
+				Context:           "menu",
+				ID:                "Open %s",
+				Str:               []string{"%s öffnen"},
+			},
+			{
+				ID:  "Multiple lines\n",
+				Str: []string{"Mehrere\tZeilen\n\"\\AB"},
+			},
+			{
+				ID:       "%d file",
+				IDPlural: "%d files",
+				Str:      []string{"%d Datei", "%d Dateien"},
+			},
+		},
+	}, file)
+	require.Equal(t, "de", file.HeaderField("language"))
+	require.True(t, file.Entries[0].HasFlag("fuzzy"))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"msgid \"a\"\n", `line 1: missing msgstr for "a"`},
+		{"msgid \"a\"\nmsgstr \"b\n", "line 2: expected a quoted string, got \"b"},
+		{"msgid \"a\"\nmsgstr[1] \"b\"\n", "line 2: unexpected msgstr[1]"},
+		{"\"a\"\n", "line 1: string without keyword"},
+		{"msgid \"a\"\nmsgfoo \"b\"\n", "line 2: unknown keyword msgfoo"},
+		{"msgid \"a\\q\"\n", `line 1: invalid escape sequence \q`},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse([]byte(tt.input))
+		require.EqualError(t, err, tt.expected)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	file := &File{
+		Comments: []string{"Translation of the app."},
+		Header:   []Field{{Name: "Language", Value: "de"}},
+		Entries: []Entry{
+			{
+				Comments:          []string{"Keep it short."},
+				ExtractedComments: []string{"Shown in the menu.", ""},
+				References:        []string{"src/menu.c:12", "src/menu.c:40"},
+				Flags:             []string{"c-format", "fuzzy"},
+				Context:           "menu",
+				ID:                "Open %s",
+				Str:               []string{"%s öffnen"},
+			},
+			{
+				ID:  "Multiple\nlines",
+				Str: []string{"Mehrere\tZeilen\n\"\\\x01"},
+			},
+			{
+				ID:       "%d file",
+				IDPlural: "%d files",
+				Str:      []string{"%d Datei", "%d Dateien"},
+			},
+		},
+	}
+
+	expected := `# Translation of the app.
+msgid ""
+msgstr "Language: de\n"
+
+# Keep it short.
+#. Shown in the menu.
+#.
+#: src/menu.c:12 src/menu.c:40
+#, c-format, fuzzy
+msgctxt "menu"
+msgid "Open %s"
+msgstr "%s öffnen"
+
+msgid ""
+"Multiple\n"
+"lines"
+msgstr ""
+"Mehrere\tZeilen\n"
+"\"\\\001"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+`
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, file))
+	require.Equal(t, expected, buf.String())
+
+	parsed, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, file, parsed)
+}
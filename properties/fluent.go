@@ -0,0 +1,59 @@
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/icu"
+	"github.com/michalnicp/fluent-go/internal/ident"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// ToFluent converts a .properties file to a Fluent resource.
+//
+// Entries become messages, with IDs derived from their keys, where characters
+// other than ASCII letters, digits, "_" and "-" become "-", e.g. "app.title"
+// becomes "app-title". IDs are made unique by appending a number. The comments
+// before entries become the comments of their messages.
+//
+// Values with placeholders are converted as java.text.MessageFormat patterns:
+// {0} becomes $arg0, {0,number} becomes NUMBER($arg0), and two apostrophes
+// become one. Values without placeholders are kept as they are, since they
+// are not formatted by MessageFormat.
+//
+// Values which cannot be expressed in Fluent, e.g. with choice formats, are
+// reported in the error, which joins an error for each of them, and left out.
+func ToFluent(file *File) (syntax.Resource, error) {
+	resource := syntax.Resource{Body: make([]syntax.Entry, 0)}
+
+	var errs []error
+	ids := make(map[string]bool)
+	for _, entry := range file.Entries {
+		value, err := pattern(entry.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", entry.Key, err))
+			continue
+		}
+
+		message := syntax.Message{
+			ID:         syntax.Identifier{Name: ident.Unique(ids, ident.FromKey(entry.Key))},
+			Value:      &value,
+			Attributes: make([]syntax.Attribute, 0),
+		}
+		if entry.Comment != "" {
+			message.Comment = &syntax.Comment{Content: entry.Comment}
+		}
+		resource.Body = append(resource.Body, message)
+	}
+
+	return resource, errors.Join(errs...)
+}
+
+// pattern converts a value to a pattern.
+func pattern(value string) (syntax.Pattern, error) {
+	if !strings.Contains(value, "{") {
+		return syntax.TextPattern(value), nil
+	}
+	return icu.ToFluent(value)
+}
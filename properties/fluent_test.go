@@ -0,0 +1,38 @@
+package properties
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestToFluent(t *testing.T) {
+	file := &File{Entries: []Entry{
+		{Comment: "Shown on the home screen.", Key: "app.title", Value: "My App"},
+		{Key: "greeting", Value: "Hello, {0}! You have {1,number,integer} messages."},
+		{Key: "apostrophe", Value: "It's"},
+		{Key: "quoted", Value: "It''s {0} and '{'literal'}'"},
+		{Key: "app-title", Value: "Duplicate"},
+		{Key: "404.page", Value: "Not found"},
+		{Key: "choice", Value: "{0,choice,0#none|1#one}"},
+		{Key: "empty", Value: ""},
+	}}
+
+	resource, err := ToFluent(file)
+	require.EqualError(t, err, "choice: offset 3: choice arguments cannot be expressed in Fluent")
+
+	var buf bytes.Buffer
+	require.NoError(t, syntax.Fprint(&buf, resource))
+	require.Equal(t, `# Shown on the home screen.
+app-title = My App
+greeting = Hello, { $arg0 }! You have { NUMBER($arg1, maximumFractionDigits: 0) } messages.
+apostrophe = It's
+quoted = It's { $arg0 } and { "{" }literal{ "}" }
+app-title-2 = Duplicate
+msg-404-page = Not found
+empty = { "" }
+`, buf.String())
+}
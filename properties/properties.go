@@ -0,0 +1,160 @@
+// Package properties converts Java .properties files to Fluent.
+package properties
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// A File is a parsed .properties file.
+type File struct {
+	Entries []Entry
+}
+
+// An Entry is a key = value pair of a .properties file.
+type Entry struct {
+	// Comment is the text of the comment lines directly before the entry.
+	Comment string
+
+	Key   string
+	Value string
+}
+
+// Parse parses a .properties file, in UTF-8 or, if it is not valid UTF-8, in
+// ISO-8859-1, the encoding of Properties.load before Java 9.
+func Parse(data []byte) (*File, error) {
+	text := string(data)
+	if !utf8.Valid(data) {
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	}
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = strings.Replace(text, "\r", "\n", -1)
+	lines := strings.Split(text, "\n")
+
+	file := &File{Entries: make([]Entry, 0)}
+	var comments []string
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		switch {
+		case line == "":
+			comments = nil
+			continue
+		case line[0] == '#' || line[0] == '!':
+			comments = append(comments, strings.TrimSpace(line[1:]))
+			continue
+		}
+
+		// A line ending in an odd number of backslashes continues on the
+		// next line, without its leading whitespace.
+		for continued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if continued(line) {
+			line = line[:len(line)-1]
+		}
+
+		key, value := split(line)
+		entry := Entry{Comment: strings.Join(comments, "\n")}
+		comments = nil
+		var err error
+		if entry.Key, err = unescape(key); err != nil {
+			return nil, fmt.Errorf("line %d: %v", number, err)
+		}
+		if entry.Value, err = unescape(value); err != nil {
+			return nil, fmt.Errorf("line %d: %v", number, err)
+		}
+		file.Entries = append(file.Entries, entry)
+	}
+	return file, nil
+}
+
+// continued reports whether line ends in an odd number of backslashes.
+func continued(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
+// split splits a line into its escaped key and value. The key ends at the
+// first unescaped =, : or whitespace, which is followed by the value after
+// whitespace and at most one = or :.
+func split(line string) (key, value string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+	key = line[:end]
+
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, ":") {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+// unescape replaces the escape sequences of s.
+func unescape(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch c := s[i]; c {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, ok := hex4(s[i+1:])
+			if !ok {
+				return "", fmt.Errorf("invalid escape sequence \\u")
+			}
+			i += 4
+			// Characters outside the BMP are written as two escape
+			// sequences of a surrogate pair.
+			if utf16.IsSurrogate(r) && strings.HasPrefix(s[i+1:], `\u`) {
+				if low, ok := hex4(s[i+3:]); ok {
+					r = utf16.DecodeRune(r, low)
+					i += 6
+				}
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// hex4 returns the character of the 4 hex digits s starts with.
+func hex4(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(s[:4], 16, 16)
+	return rune(n), err == nil
+}
@@ -0,0 +1,52 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	input := []byte(`# File header
+
+# Shown on the home screen.
+! Second line
+app.title = My App
+greeting:Hello, {0}!
+spaced     value with spaces
+key\ with\ spaces=a\=b\:c
+escapes = tab\tnewline\nbackslash\\ \u00e9 \ud83d\ude00
+multiline = first \
+            second \
+  third
+ends.with.backslash = a\\
+empty
+`)
+
+	file, err := Parse(input)
+	require.NoError(t, err)
+	require.Equal(t, &File{Entries: []Entry{
+		{Comment: "Shown on the home screen.\nSecond line", Key: "app.title", Value: "My App"},
+		{Key: "greeting", Value: "Hello, {0}!"},
+		{Key: "spaced", Value: "value with spaces"},
+		{Key: "key with spaces", Value: "a=b:c"},
+		{Key: "escapes", Value: "tab\tnewline\nbackslash\\ é 😀"},
+		{Key: "multiline", Value: "first second third"},
+		{Key: "ends.with.backslash", Value: `a\`},
+		{Key: "empty", Value: ""},
+	}}, file)
+}
+
+func TestParseLatin1(t *testing.T) {
+	file, err := Parse([]byte("key = caf\xe9\r\nnext = x\r\n"))
+	require.NoError(t, err)
+	require.Equal(t, &File{Entries: []Entry{
+		{Key: "key", Value: "café"},
+		{Key: "next", Value: "x"},
+	}}, file)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]byte("a = b\nc = \\u00zz\n"))
+	require.EqualError(t, err, `line 2: invalid escape sequence \u`)
+}
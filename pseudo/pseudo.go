@@ -0,0 +1,216 @@
+// Package pseudo pseudolocalizes Fluent resources, so that hard-coded strings,
+// truncated text and layouts which do not mirror for right-to-left locales can
+// be found before real translations arrive.
+package pseudo
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// A Strategy transforms the text of translations.
+type Strategy struct {
+	Name        string
+	Description string
+
+	// Transform transforms the value of a text element.
+	Transform func(text string) string
+}
+
+var (
+	// Accented replaces ASCII letters with accented letters, which remain
+	// readable.
+	Accented = Strategy{
+		Name:        "accented",
+		Description: "Replace letters with accented letters: Ȧƈƈḗƞŧḗḓ.",
+		Transform:   accented,
+	}
+
+	// Elongated repeats letters to make text about 35% longer, the growth
+	// expected from translations of English text.
+	Elongated = Strategy{
+		Name:        "elongated",
+		Description: "Repeat letters to make text about 35% longer: Ellongaateed.",
+		Transform:   elongated,
+	}
+
+	// Bidi replaces ASCII letters with upside down letters and writes each
+	// line right-to-left, like text in a right-to-left locale.
+	Bidi = Strategy{
+		Name:        "bidi",
+		Description: "Replace letters with upside down letters and write them right-to-left.",
+		Transform:   bidi,
+	}
+)
+
+// Strategies lists all strategies.
+var Strategies = []Strategy{Accented, Elongated, Bidi}
+
+// Lookup returns the strategy with the name.
+func Lookup(name string) (Strategy, bool) {
+	for _, strategy := range Strategies {
+		if strategy.Name == name {
+			return strategy, true
+		}
+	}
+	return Strategy{}, false
+}
+
+// Transform returns a copy of resource with the text of messages and terms
+// transformed by the strategies, in order. Only text elements are changed, so
+// identifiers, variables, variant keys, literals and comments are kept, and
+// the resource is left untouched.
+func Transform(resource syntax.Resource, strategies ...Strategy) syntax.Resource {
+	transform := func(text string) string {
+		for _, strategy := range strategies {
+			text = strategy.Transform(text)
+		}
+		return text
+	}
+
+	body := make([]syntax.Entry, len(resource.Body))
+	for i, entry := range resource.Body {
+		switch entry := entry.(type) {
+		case syntax.Message:
+			if entry.Value != nil {
+				value := transformPattern(*entry.Value, transform)
+				entry.Value = &value
+			}
+			entry.Attributes = transformAttributes(entry.Attributes, transform)
+			body[i] = entry
+		case syntax.Term:
+			entry.Value = transformPattern(entry.Value, transform)
+			entry.Attributes = transformAttributes(entry.Attributes, transform)
+			body[i] = entry
+		default:
+			body[i] = entry
+		}
+	}
+	resource.Body = body
+	return resource
+}
+
+func transformAttributes(attributes []syntax.Attribute, transform func(string) string) []syntax.Attribute {
+	if attributes == nil {
+		return nil
+	}
+	transformed := make([]syntax.Attribute, len(attributes))
+	for i, attribute := range attributes {
+		attribute.Value = transformPattern(attribute.Value, transform)
+		transformed[i] = attribute
+	}
+	return transformed
+}
+
+func transformPattern(pattern syntax.Pattern, transform func(string) string) syntax.Pattern {
+	if pattern.Elements == nil {
+		return pattern
+	}
+	elements := make([]syntax.PatternElement, len(pattern.Elements))
+	for i, element := range pattern.Elements {
+		switch element := element.(type) {
+		case syntax.TextElement:
+			element.Value = transform(element.Value)
+			elements[i] = element
+		case syntax.Placeable:
+			elements[i] = transformPlaceable(element, transform)
+		default:
+			elements[i] = element
+		}
+	}
+	pattern.Elements = elements
+	return pattern
+}
+
+func transformPlaceable(placeable syntax.Placeable, transform func(string) string) syntax.Placeable {
+	switch expr := placeable.Expr.(type) {
+	case syntax.Placeable:
+		placeable.Expr = transformPlaceable(expr, transform)
+	case syntax.SelectExpression:
+		variants := make([]syntax.Variant, len(expr.Variants))
+		for i, variant := range expr.Variants {
+			variant.Value = transformPattern(variant.Value, transform)
+			variants[i] = variant
+		}
+		expr.Variants = variants
+		placeable.Expr = expr
+	}
+	return placeable
+}
+
+var (
+	accentedUpper = []rune("ȦƁƇḒḖƑƓĦĪĴĶĿḾȠǾƤɊŘŞŦŬṼẆẊẎẐ")
+	accentedLower = []rune("ȧƀƈḓḗƒɠħīĵķŀḿƞǿƥɋřşŧŭṽẇẋẏẑ")
+	flippedUpper  = []rune("∀ԐↃᗡƎℲ⅁HIſӼ⅂WNOԀÒᴚS⊥∩ɅＭXʎZ")
+	flippedLower  = []rune("ɐqɔpǝɟƃɥıɾʞʅɯuodbɹsʇnʌʍxʎz")
+)
+
+// replaceLetters replaces ASCII letters with the runes of upper and lower at
+// their index in the alphabet.
+func replaceLetters(text string, upper, lower []rune) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'A' <= r && r <= 'Z':
+			return upper[r-'A']
+		case 'a' <= r && r <= 'z':
+			return lower[r-'a']
+		default:
+			return r
+		}
+	}, text)
+}
+
+func accented(text string) string {
+	return replaceLetters(text, accentedUpper, accentedLower)
+}
+
+// elongation is the growth of elongated text, in percent.
+const elongation = 35
+
+func elongated(text string) string {
+	runes := []rune(text)
+	letters := 0
+	for _, r := range runes {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters == 0 {
+		return text
+	}
+
+	// Spread the extra runes evenly over the letters.
+	extra := (len(runes)*elongation + 50) / 100
+	var b strings.Builder
+	j := 0
+	for _, r := range runes {
+		b.WriteRune(r)
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		repeat := (j+1)*extra/letters - j*extra/letters
+		for k := 0; k < repeat; k++ {
+			b.WriteRune(r)
+		}
+		j++
+	}
+	return b.String()
+}
+
+const (
+	rightToLeftOverride  = '‮'
+	popDirectionalFormat = '‬'
+)
+
+func bidi(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines[i] = string(rightToLeftOverride) + replaceLetters(line, flippedUpper, flippedLower) + string(popDirectionalFormat)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,94 @@
+package pseudo
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategies []Strategy
+		input      string
+		expected   string
+	}{
+		{
+			name:       "accented",
+			strategies: []Strategy{Accented},
+			input:      "# Comment\nhello = Hello, { $userName }!\n    .title = { -brand } Title\n",
+			expected:   "# Comment\nhello = Ħḗŀŀǿ, { $userName }!\n    .title = { -brand } Ŧīŧŀḗ\n",
+		},
+		{
+			name:       "select expression",
+			strategies: []Strategy{Accented},
+			input:      "-brand = { $case ->\n   *[nominative] Firefox\n    [genitive] { \"Firefoxes\" }\n}\n",
+			expected:   "-brand =\n    { $case ->\n       *[nominative] Ƒīřḗƒǿẋ\n        [genitive] { \"Firefoxes\" }\n    }\n",
+		},
+		{
+			name:       "elongated",
+			strategies: []Strategy{Elongated},
+			input:      "key = Hello world\n",
+			expected:   "key = Hellloo worrldd\n",
+		},
+		{
+			name:       "bidi",
+			strategies: []Strategy{Bidi},
+			input:      "key =\n    Hello\n    World\n",
+			expected:   "key =\n    ‮Hǝʅʅo‬\n    ‮\uff2doɹʅp‬\n",
+		},
+		{
+			name:       "combined",
+			strategies: []Strategy{Elongated, Accented},
+			input:      "key = abc\n",
+			expected:   "key = ȧƀƈƈ\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, err := syntax.Parse([]byte(tt.input))
+			require.NoError(t, err)
+
+			var before bytes.Buffer
+			require.NoError(t, syntax.Fprint(&before, resource))
+
+			transformed := Transform(resource, tt.strategies...)
+
+			var buf bytes.Buffer
+			require.NoError(t, syntax.Fprint(&buf, transformed))
+			require.Equal(t, tt.expected, buf.String())
+
+			_, err = syntax.Parse(buf.Bytes())
+			require.NoError(t, err)
+
+			// The resource is left untouched.
+			var after bytes.Buffer
+			require.NoError(t, syntax.Fprint(&after, resource))
+			require.Equal(t, before.String(), after.String())
+		})
+	}
+}
+
+func TestElongated(t *testing.T) {
+	for _, text := range []string{"Cancel", "Settings", "The quick brown fox jumps over the lazy dog."} {
+		n := utf8.RuneCountInString(text)
+		growth := float64(utf8.RuneCountInString(elongated(text))-n) / float64(n)
+		require.InDelta(t, 0.35, growth, 0.05, text)
+	}
+
+	require.Equal(t, " 123 ", elongated(" 123 "))
+}
+
+func TestLookup(t *testing.T) {
+	strategy, ok := Lookup("bidi")
+	require.True(t, ok)
+	require.Equal(t, "bidi", strategy.Name)
+
+	_, ok = Lookup("nope")
+	require.False(t, ok)
+}
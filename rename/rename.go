@@ -0,0 +1,94 @@
+// Package rename renames messages, terms and attributes in the resources of
+// all locales, together with the references to them, by editing the input of
+// the resources, so that their formatting is preserved.
+package rename
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/michalnicp/fluent-go/index"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Rename returns the edits which rename the message, term or attribute from
+// to to in the resources, e.g. the files of all locales. The edits of
+// resources[i] are returned at index i, sorted by their start, and are nil for
+// resources which do not change.
+//
+// Messages and terms keep their kind, attributes their message or term, and
+// the definitions and references of the attributes of renamed messages and
+// terms are edited too. Rename returns an error if from is not defined in any
+// resource, or to is already defined in one, so that resources which are used
+// together never end up with duplicate or undefined IDs. References in Junk
+// are not found, so the resources should parse without errors.
+func Rename(resources []syntax.Resource, from, to index.Symbol) ([][]syntax.Edit, error) {
+	switch {
+	case from.Kind != to.Kind:
+		return nil, fmt.Errorf("cannot rename %q to %q: messages and terms cannot be converted", from, to)
+	case (from.Attribute == "") != (to.Attribute == ""):
+		return nil, fmt.Errorf("cannot rename %q to %q: attributes cannot be converted to messages or terms", from, to)
+	case from.Attribute != "" && from.ID != to.ID:
+		return nil, fmt.Errorf("cannot rename %q to %q: attributes cannot be moved", from, to)
+	}
+	if _, err := index.ParseSymbol(to.String()); err != nil {
+		return nil, err
+	}
+
+	x := index.New(resources...)
+	if !x.Defined(from) {
+		return nil, fmt.Errorf("%q is not defined", from)
+	}
+	edits := make([][]syntax.Edit, len(resources))
+	if from == to {
+		return edits, nil
+	}
+	if x.Defined(to) {
+		return nil, fmt.Errorf("%q is already defined", to)
+	}
+
+	add := func(resource int, span syntax.Span, name string) {
+		edits[resource] = append(edits[resource], syntax.Edit{Start: span.Start, End: span.End, Text: []byte(name)})
+	}
+
+	if from.Attribute != "" {
+		for _, def := range x.Definitions(from) {
+			add(def.Resource, def.ID, to.Attribute)
+		}
+		for _, ref := range x.References(from) {
+			if ref.Attribute != nil {
+				add(ref.Resource, *ref.Attribute, to.Attribute)
+			}
+		}
+	} else {
+		// The identifiers of the definitions and references of attributes are
+		// those of their message or term.
+		for _, def := range x.Definitions(from) {
+			add(def.Resource, def.ID, to.ID)
+		}
+		for _, ref := range x.References(from) {
+			add(ref.Resource, ref.ID, to.ID)
+		}
+	}
+
+	for _, e := range edits {
+		sort.Slice(e, func(i, j int) bool { return e[i].Start < e[j].Start })
+	}
+	return edits, nil
+}
+
+// Apply returns input with the edits applied. The edits must be sorted by
+// their start and must not overlap, as returned by Rename.
+func Apply(input []byte, edits []syntax.Edit) []byte {
+	if len(edits) == 0 {
+		return input
+	}
+	out := make([]byte, 0, len(input))
+	end := 0
+	for _, edit := range edits {
+		out = append(out, input[end:edit.Start]...)
+		out = append(out, edit.Text...)
+		end = edit.End
+	}
+	return append(out, input[end:]...)
+}
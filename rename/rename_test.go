@@ -0,0 +1,221 @@
+package rename
+
+import (
+	"testing"
+
+	"github.com/michalnicp/fluent-go/index"
+	"github.com/michalnicp/fluent-go/syntax"
+	"github.com/stretchr/testify/require"
+)
+
+const en = `# The product name.
+-brand   =   Firefox
+    .gender = masculine
+
+## Login
+
+login = Log in
+    .title = Log in to { -brand }
+login-button = { login.title }
+# Not "login".
+welcome = { -brand.gender ->
+   *[masculine] Welcome to { -brand(case: "dative") }, { login }!
+}
+`
+
+const de = `-brand = Firefox
+login = Anmelden
+    .title = Bei { -brand } anmelden
+`
+
+func TestRename(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+	}{
+		{
+			name: "message",
+			from: "login",
+			to:   "sign-in",
+			want: []string{
+				`# The product name.
+-brand   =   Firefox
+    .gender = masculine
+
+## Login
+
+sign-in = Log in
+    .title = Log in to { -brand }
+login-button = { sign-in.title }
+# Not "login".
+welcome = { -brand.gender ->
+   *[masculine] Welcome to { -brand(case: "dative") }, { sign-in }!
+}
+`,
+				`-brand = Firefox
+sign-in = Anmelden
+    .title = Bei { -brand } anmelden
+`,
+			},
+		},
+		{
+			name: "term",
+			from: "-brand",
+			to:   "-product",
+			want: []string{
+				`# The product name.
+-product   =   Firefox
+    .gender = masculine
+
+## Login
+
+login = Log in
+    .title = Log in to { -product }
+login-button = { login.title }
+# Not "login".
+welcome = { -product.gender ->
+   *[masculine] Welcome to { -product(case: "dative") }, { login }!
+}
+`,
+				`-product = Firefox
+login = Anmelden
+    .title = Bei { -product } anmelden
+`,
+			},
+		},
+		{
+			name: "attribute",
+			from: "login.title",
+			to:   "login.tooltip",
+			want: []string{
+				`# The product name.
+-brand   =   Firefox
+    .gender = masculine
+
+## Login
+
+login = Log in
+    .tooltip = Log in to { -brand }
+login-button = { login.tooltip }
+# Not "login".
+welcome = { -brand.gender ->
+   *[masculine] Welcome to { -brand(case: "dative") }, { login }!
+}
+`,
+				`-brand = Firefox
+login = Anmelden
+    .tooltip = Bei { -brand } anmelden
+`,
+			},
+		},
+		{
+			name: "term attribute",
+			from: "-brand.gender",
+			to:   "-brand.genus",
+			want: []string{
+				`# The product name.
+-brand   =   Firefox
+    .genus = masculine
+
+## Login
+
+login = Log in
+    .title = Log in to { -brand }
+login-button = { login.title }
+# Not "login".
+welcome = { -brand.genus ->
+   *[masculine] Welcome to { -brand(case: "dative") }, { login }!
+}
+`,
+				de,
+			},
+		},
+		{
+			name: "same",
+			from: "login",
+			to:   "login",
+			want: []string{en, de},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs := []string{en, de}
+			resources := parseAll(t, inputs)
+
+			edits, err := Rename(resources, mustSymbol(t, tt.from), mustSymbol(t, tt.to))
+			require.NoError(t, err)
+			require.Len(t, edits, len(inputs))
+
+			for i, input := range inputs {
+				require.Equal(t, tt.want[i], string(Apply([]byte(input), edits[i])))
+				if tt.want[i] == input {
+					require.Nil(t, edits[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenameErrors(t *testing.T) {
+	tests := []struct {
+		from, to string
+		err      string
+	}{
+		{"login", "login-button", `"login-button" is already defined`},
+		{"login.title", "login.title", ""},
+		{"missing", "found", `"missing" is not defined`},
+		{"login.label", "login.text", `"login.label" is not defined`},
+		{"-brand.gender", "-brand.gender", ""},
+		{"login", "-login", `cannot rename "login" to "-login": messages and terms cannot be converted`},
+		{"login.title", "title", `cannot rename "login.title" to "title": attributes cannot be converted to messages or terms`},
+		{"login.title", "welcome.title", `cannot rename "login.title" to "welcome.title": attributes cannot be moved`},
+	}
+
+	resources := parseAll(t, []string{en, de})
+	for _, tt := range tests {
+		_, err := Rename(resources, mustSymbol(t, tt.from), mustSymbol(t, tt.to))
+		if tt.err == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.EqualError(t, err, tt.err)
+	}
+
+	// A collision in any locale prevents the rename.
+	resources = parseAll(t, []string{en, de + "sign-in = Einloggen\n"})
+	_, err := Rename(resources, mustSymbol(t, "login"), mustSymbol(t, "sign-in"))
+	require.EqualError(t, err, `"sign-in" is already defined`)
+
+	// Invalid IDs are rejected.
+	_, err = Rename(resources, mustSymbol(t, "login"), index.Symbol{ID: "sign in"})
+	require.EqualError(t, err, `invalid message or term "sign in"`)
+}
+
+func TestApply(t *testing.T) {
+	input := []byte("hello = { world }")
+	require.Equal(t, "hi = { earth }", string(Apply(input, []syntax.Edit{
+		{Start: 0, End: 5, Text: []byte("hi")},
+		{Start: 10, End: 15, Text: []byte("earth")},
+	})))
+	require.Equal(t, string(input), string(Apply(input, nil)))
+}
+
+func parseAll(t *testing.T, inputs []string) []syntax.Resource {
+	t.Helper()
+	var resources []syntax.Resource
+	for _, input := range inputs {
+		resource, err := syntax.Parse([]byte(input))
+		require.NoError(t, err)
+		resources = append(resources, resource)
+	}
+	return resources
+}
+
+func mustSymbol(t *testing.T, s string) index.Symbol {
+	t.Helper()
+	symbol, err := index.ParseSymbol(s)
+	require.NoError(t, err)
+	return symbol
+}
@@ -0,0 +1,140 @@
+package spreadsheet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/segment"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Export returns the rows of the messages and terms of a reference resource,
+// with path as their file, and Import applies edited rows back to a resource.
+//
+// Each value and attribute becomes a row, with the message ID, e.g. "hello"
+// or "-brand", or the ID and the attribute name, e.g. "hello.title", as ID.
+// The comment of the message is the comment of its first row. The first
+// pattern of each row is the pattern of the reference, followed by those of
+// translations, which are empty where a translation is nil or does not have
+// the pattern.
+func Export(path string, reference syntax.Resource, translations []*syntax.Resource) []Row {
+	translated := make([]map[string]syntax.Pattern, len(translations))
+	for i, translation := range translations {
+		translated[i] = segment.ByID(translation)
+	}
+
+	var rows []Row
+	for _, entry := range reference.Body {
+		for i, p := range segment.Patterns(entry) {
+			row := Row{File: path, ID: p.ID, Patterns: []string{syntax.FormatPattern(p.Pattern)}}
+			if i == 0 && p.Comment != nil {
+				row.Comment = p.Comment.Content
+			}
+			for _, t := range translated {
+				pattern := ""
+				if p, ok := t[p.ID]; ok {
+					pattern = syntax.FormatPattern(p)
+				}
+				row.Patterns = append(row.Patterns, pattern)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Import applies the patterns in the column of the rows with path as their
+// file to resource, where column is the index of the locale in the patterns.
+//
+// Each non-empty cell which differs from the pattern in resource is parsed as
+// a Fluent pattern, and replaces it or is added to the message or term, which
+// is added to the end of the resource if it does not exist. Cells which are
+// not a single valid pattern, and would therefore introduce Junk, are reported
+// in the error, which joins an error for each of them, and left out. The
+// resource has the other edits even if the error is not nil.
+func Import(path string, resource syntax.Resource, rows []Row, column int) (syntax.Resource, error) {
+	body := append([]syntax.Entry(nil), resource.Body...)
+	// index maps the IDs of messages and terms to their index in body.
+	index := make(map[string]int)
+	for i, entry := range body {
+		switch entry := entry.(type) {
+		case syntax.Message:
+			index[entry.ID.Name] = i
+		case syntax.Term:
+			index["-"+entry.ID.Name] = i
+		}
+	}
+
+	var errs []error
+	for _, row := range rows {
+		if row.File != path || column >= len(row.Patterns) || strings.TrimSpace(row.Patterns[column]) == "" {
+			continue
+		}
+		id, attribute, ok := segment.SplitID(row.ID)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%q is not a message ID", row.ID))
+			continue
+		}
+
+		pattern, err := syntax.ParsePattern(row.Patterns[column])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid pattern: %v", row.ID, err))
+			continue
+		}
+
+		i, ok := index[id]
+		if !ok {
+			if strings.HasPrefix(id, "-") && attribute != "" {
+				errs = append(errs, fmt.Errorf("%s: missing value of term %s", row.ID, id))
+				continue
+			}
+			i = len(body)
+			index[id] = i
+			if strings.HasPrefix(id, "-") {
+				body = append(body, syntax.Term{ID: syntax.Identifier{Name: id[1:]}, Attributes: make([]syntax.Attribute, 0)})
+			} else {
+				body = append(body, syntax.Message{ID: syntax.Identifier{Name: id}, Attributes: make([]syntax.Attribute, 0)})
+			}
+		}
+
+		switch entry := body[i].(type) {
+		case syntax.Message:
+			if attribute == "" {
+				if entry.Value == nil || syntax.FormatPattern(*entry.Value) != syntax.FormatPattern(pattern) {
+					entry.Value = &pattern
+				}
+			} else {
+				entry.Attributes = setAttribute(entry.Attributes, attribute, pattern)
+			}
+			body[i] = entry
+		case syntax.Term:
+			if attribute == "" {
+				if syntax.FormatPattern(entry.Value) != syntax.FormatPattern(pattern) {
+					entry.Value = pattern
+				}
+			} else {
+				entry.Attributes = setAttribute(entry.Attributes, attribute, pattern)
+			}
+			body[i] = entry
+		}
+	}
+
+	resource.Body = body
+	return resource, errors.Join(errs...)
+}
+
+// setAttribute returns attributes with the pattern of the attribute with the
+// name replaced, unless it is the same, or with the attribute added.
+func setAttribute(attributes []syntax.Attribute, name string, pattern syntax.Pattern) []syntax.Attribute {
+	result := append([]syntax.Attribute(nil), attributes...)
+	for i, attribute := range result {
+		if attribute.ID.Name == name {
+			if syntax.FormatPattern(attribute.Value) != syntax.FormatPattern(pattern) {
+				result[i].Value = pattern
+			}
+			return result
+		}
+	}
+	return append(result, syntax.Attribute{ID: syntax.Identifier{Name: name}, Value: pattern})
+}
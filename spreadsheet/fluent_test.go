@@ -0,0 +1,79 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestExport(t *testing.T) {
+	reference, err := syntax.Parse([]byte(`# Greets the user.
+hello = Hello, { $name }!
+    .title = Greeting
+-brand = Firefox
+emails =
+    { $count ->
+        [one] one email
+       *[other] { $count } emails
+    }
+`))
+	require.NoError(t, err)
+	translation, err := syntax.Parse([]byte(`hello = Hallo, { $name }!
+-brand = Firefox
+`))
+	require.NoError(t, err)
+
+	rows := Export("main.ftl", reference, []*syntax.Resource{&translation, nil})
+	require.Equal(t, []Row{
+		{File: "main.ftl", ID: "hello", Comment: "Greets the user.", Patterns: []string{"Hello, { $name }!", "Hallo, { $name }!", ""}},
+		{File: "main.ftl", ID: "hello.title", Patterns: []string{"Greeting", "", ""}},
+		{File: "main.ftl", ID: "-brand", Patterns: []string{"Firefox", "Firefox", ""}},
+		{File: "main.ftl", ID: "emails", Patterns: []string{"{ $count ->\n    [one] one email\n   *[other] { $count } emails\n}", "", ""}},
+	}, rows)
+}
+
+func TestImport(t *testing.T) {
+	resource, err := syntax.Parse([]byte(`# Begrüßt den Benutzer.
+hello = Hallo, { $name }!
+    .title = Gruß
+-brand = Firefox
+`))
+	require.NoError(t, err)
+
+	rows := []Row{
+		{File: "main.ftl", ID: "hello", Patterns: []string{"Hello, { $name }!", "Hallo { $name }!"}},
+		{File: "main.ftl", ID: "hello.title", Patterns: []string{"Greeting", "Begrüßung"}},
+		{File: "main.ftl", ID: "hello.alt", Patterns: []string{"Hi", "Hi"}},
+		{File: "main.ftl", ID: "-brand", Patterns: []string{"Firefox", "Firefox"}},
+		{File: "main.ftl", ID: "emails", Patterns: []string{"Emails", "{ $count ->\n    [one] eine E-Mail\n   *[other] { $count } E-Mails\n}"}},
+		{File: "main.ftl", ID: "untranslated", Patterns: []string{"Untranslated", ""}},
+		{File: "main.ftl", ID: "junk", Patterns: []string{"Junk", "Text }"}},
+		{File: "main.ftl", ID: "unclosed", Patterns: []string{"Unclosed", "{ $name"}},
+		{File: "main.ftl", ID: "-new.title", Patterns: []string{"Title", "Titel"}},
+		{File: "main.ftl", ID: "invalid id", Patterns: []string{"Invalid", "Ungültig"}},
+		{File: "other.ftl", ID: "other", Patterns: []string{"Other", "Andere"}},
+	}
+
+	imported, err := Import("main.ftl", resource, rows, 1)
+	require.EqualError(t, err, `junk: invalid pattern: 1:6: Unbalanced closing brace in TextElement.`+"\n"+
+		`unclosed: invalid pattern: 1:5: Expected token: "}"`+"\n"+
+		`-new.title: missing value of term -new`+"\n"+
+		`"invalid id" is not a message ID`)
+
+	var buf bytes.Buffer
+	require.NoError(t, syntax.Fprint(&buf, imported))
+	require.Equal(t, `# Begrüßt den Benutzer.
+hello = Hallo { $name }!
+    .title = Begrüßung
+    .alt = Hi
+-brand = Firefox
+emails =
+    { $count ->
+        [one] eine E-Mail
+       *[other] { $count } E-Mails
+    }
+`, buf.String())
+}
@@ -0,0 +1,135 @@
+// Package spreadsheet converts Fluent resources to and from CSV spreadsheets,
+// for review of translations by people who work in spreadsheet applications.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// columns are the names of the columns before the columns of the locales.
+var columns = []string{"file", "id", "comment"}
+
+// A Sheet is a table of the messages of a reference locale, with their
+// patterns in the reference and other locales.
+type Sheet struct {
+	// Locales are the names of the locales of the columns after the comment
+	// column, e.g. "en-US", the reference first.
+	Locales []string
+
+	Rows []Row
+}
+
+// A Row is the value or an attribute of a message or term.
+type Row struct {
+	// File is the path of the file of the message, e.g. "main.ftl".
+	File string
+
+	// ID is the ID of the message or term, e.g. "hello" or "-brand", or the
+	// ID and the attribute name, e.g. "hello.title".
+	ID string
+
+	Comment string
+
+	// Patterns are the patterns in Fluent syntax, as returned by
+	// syntax.FormatPattern, in the order of the locales. A pattern is empty if
+	// the locale does not have it.
+	Patterns []string
+}
+
+// Parse parses a CSV spreadsheet written by Write, or by a spreadsheet
+// application from it. The columns after the comment column are the locales.
+// Empty rows are skipped, and missing cells at the end of rows are empty.
+func Parse(data []byte) (*Sheet, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing header")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) <= len(columns) {
+		return nil, errors.New("line 1: missing locale columns")
+	}
+	for i, column := range columns {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), column) {
+			return nil, fmt.Errorf("line 1: expected column %q, got %q", column, header[i])
+		}
+	}
+
+	sheet := &Sheet{Rows: make([]Row, 0)}
+	for _, locale := range header[len(columns):] {
+		sheet.Locales = append(sheet.Locales, strings.TrimSpace(locale))
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := r.FieldPos(0)
+		if isEmpty(record) {
+			continue
+		}
+		if len(record) > len(header) {
+			return nil, fmt.Errorf("line %d: expected %d cells, got %d", line, len(header), len(record))
+		}
+		for len(record) < len(header) {
+			record = append(record, "")
+		}
+
+		sheet.Rows = append(sheet.Rows, Row{
+			File:     record[0],
+			ID:       record[1],
+			Comment:  record[2],
+			Patterns: record[len(columns):],
+		})
+	}
+
+	return sheet, nil
+}
+
+// isEmpty reports whether all cells of record are empty.
+func isEmpty(record []string) bool {
+	for _, cell := range record {
+		if cell != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Write writes sheet as CSV, with a header row naming the columns. The output
+// starts with a byte order mark, by which spreadsheet applications recognize
+// UTF-8.
+func Write(w io.Writer, sheet *Sheet) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(append([]string(nil), columns...), sheet.Locales...)); err != nil {
+		return err
+	}
+	for _, row := range sheet.Rows {
+		record := append([]string{row.File, row.ID, row.Comment}, row.Patterns...)
+		for len(record) < len(columns)+len(sheet.Locales) {
+			record = append(record, "")
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
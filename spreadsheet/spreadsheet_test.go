@@ -0,0 +1,67 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	sheet := &Sheet{
+		Locales: []string{"en-US", "de"},
+		Rows: []Row{
+			{File: "main.ftl", ID: "hello", Comment: "Greets the user.", Patterns: []string{"Hello, { $name }!", "Hallo, { $name }!"}},
+			{File: "main.ftl", ID: "emails", Patterns: []string{"{ $count ->\n    [one] one email\n   *[other] { $count } emails\n}", ""}},
+			{File: "main.ftl", ID: "short", Patterns: []string{"Short"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, sheet))
+	require.Equal(t, "\ufefffile,id,comment,en-US,de\n"+
+		"main.ftl,hello,Greets the user.,\"Hello, { $name }!\",\"Hallo, { $name }!\"\n"+
+		"main.ftl,emails,,\"{ $count ->\n    [one] one email\n   *[other] { $count } emails\n}\",\n"+
+		"main.ftl,short,,Short,\n", buf.String())
+
+	parsed, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	sheet.Rows[2].Patterns = append(sheet.Rows[2].Patterns, "")
+	require.Equal(t, sheet, parsed)
+}
+
+func TestParse(t *testing.T) {
+	input := "File,ID,Comment,en-US,de\r\n" +
+		"main.ftl,hello,,Hello,\"Hallo\r\nWelt\"\r\n" +
+		",,,,\r\n" +
+		"main.ftl,bye,,Bye\r\n"
+
+	sheet, err := Parse([]byte(input))
+	require.NoError(t, err)
+	require.Equal(t, &Sheet{
+		Locales: []string{"en-US", "de"},
+		Rows: []Row{
+			{File: "main.ftl", ID: "hello", Patterns: []string{"Hello", "Hallo\nWelt"}},
+			{File: "main.ftl", ID: "bye", Patterns: []string{"Bye", ""}},
+		},
+	}, sheet)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", "missing header"},
+		{"file,id,comment\n", "line 1: missing locale columns"},
+		{"file,key,comment,en\n", `line 1: expected column "id", got "key"`},
+		{"file,id,comment,en\na,b,c,d,e\n", "line 2: expected 4 cells, got 5"},
+		{"file,id,comment,en\na,b,c,\"d\n", `parse error on line 2, column 10: extraneous or missing " in quoted-field`},
+	}
+
+	for _, tt := range tests {
+		sheet, err := Parse([]byte(tt.input))
+		require.EqualError(t, err, tt.expected, tt.input)
+		require.Nil(t, sheet)
+	}
+}
@@ -1,7 +1,14 @@
 package syntax
 
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
 type Resource struct {
 	Body []Entry `json:"body"`
+	Span *Span   `json:"span,omitempty"`
 }
 
 func (a Resource) MarshalJSON() ([]byte, error) {
@@ -16,6 +23,24 @@ func (a Resource) MarshalJSON() ([]byte, error) {
 	return marshal(tmp)
 }
 
+// Span is the byte range [Start, End) of a node in the parsed input.
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+func (a Span) MarshalJSON() ([]byte, error) {
+	type alias Span
+	tmp := struct {
+		Type string `json:"type"`
+		alias
+	}{
+		Type:  "Span",
+		alias: alias(a),
+	}
+	return marshal(tmp)
+}
+
 type Entry interface {
 	Entry()
 }
@@ -30,6 +55,7 @@ func (a Junk) Entry()            {}
 type Junk struct {
 	Annotations []Annotation `json:"annotations"`
 	Content     string       `json:"content"`
+	Span        *Span        `json:"span,omitempty"`
 }
 
 func (a Junk) MarshalJSON() ([]byte, error) {
@@ -44,8 +70,14 @@ func (a Junk) MarshalJSON() ([]byte, error) {
 	return marshal(tmp)
 }
 
-// TODO: Implement. See https://github.com/projectfluent/fluent/pull/40
-type Annotation struct{}
+// Annotation describes a parse error in Junk. Code is one of the error codes
+// shared with the reference implementations, e.g. E0003.
+type Annotation struct {
+	Code      string   `json:"code"`
+	Arguments []string `json:"arguments"`
+	Message   string   `json:"message"`
+	Span      *Span    `json:"span,omitempty"`
+}
 
 func (a Annotation) MarshalJSON() ([]byte, error) {
 	type alias Annotation
@@ -64,6 +96,7 @@ type Message struct {
 	Value      *Pattern    `json:"value"`
 	Attributes []Attribute `json:"attributes"`
 	Comment    *Comment    `json:"comment"`
+	Span       *Span       `json:"span,omitempty"`
 }
 
 func (a Message) MarshalJSON() ([]byte, error) {
@@ -83,6 +116,7 @@ type Term struct {
 	Value      Pattern     `json:"value"`
 	Attributes []Attribute `json:"attributes"`
 	Comment    *Comment    `json:"comment"`
+	Span       *Span       `json:"span,omitempty"`
 }
 
 func (a Term) MarshalJSON() ([]byte, error) {
@@ -99,6 +133,7 @@ func (a Term) MarshalJSON() ([]byte, error) {
 
 type Pattern struct {
 	Elements []PatternElement `json:"elements"`
+	Span     *Span            `json:"span,omitempty"`
 }
 
 func (a Pattern) MarshalJSON() ([]byte, error) {
@@ -113,6 +148,15 @@ func (a Pattern) MarshalJSON() ([]byte, error) {
 	return marshal(tmp)
 }
 
+// TextPattern returns a pattern of text. Patterns cannot be empty, so empty
+// text becomes { "" }.
+func TextPattern(text string) Pattern {
+	if text == "" {
+		return Pattern{Elements: []PatternElement{Placeable{Expr: StringLiteral{Value: ""}}}}
+	}
+	return Pattern{Elements: []PatternElement{TextElement{Value: text}}}
+}
+
 type PatternElement interface {
 	PatternElement()
 }
@@ -123,6 +167,7 @@ func (a Placeable) PatternElement()   {}
 type Attribute struct {
 	ID    Identifier `json:"id"`
 	Value Pattern    `json:"value"`
+	Span  *Span      `json:"span,omitempty"`
 }
 
 func (a Attribute) MarshalJSON() ([]byte, error) {
@@ -139,6 +184,7 @@ func (a Attribute) MarshalJSON() ([]byte, error) {
 
 type Identifier struct {
 	Name string `json:"name"`
+	Span *Span  `json:"span,omitempty"`
 }
 
 func (a Identifier) MarshalJSON() ([]byte, error) {
@@ -157,6 +203,7 @@ type Variant struct {
 	Key     VariantKey `json:"key"`
 	Value   Pattern    `json:"value"`
 	Default bool       `json:"default"`
+	Span    *Span      `json:"span,omitempty"`
 }
 
 func (a Variant) MarshalJSON() ([]byte, error) {
@@ -188,6 +235,7 @@ func (a ResourceComment) CommentLine() {}
 
 type Comment struct {
 	Content string `json:"content"`
+	Span    *Span  `json:"span,omitempty"`
 }
 
 func (a Comment) MarshalJSON() ([]byte, error) {
@@ -204,6 +252,7 @@ func (a Comment) MarshalJSON() ([]byte, error) {
 
 type GroupComment struct {
 	Content string `json:"content"`
+	Span    *Span  `json:"span,omitempty"`
 }
 
 func (a GroupComment) MarshalJSON() ([]byte, error) {
@@ -220,6 +269,7 @@ func (a GroupComment) MarshalJSON() ([]byte, error) {
 
 type ResourceComment struct {
 	Content string `json:"content"`
+	Span    *Span  `json:"span,omitempty"`
 }
 
 func (a ResourceComment) MarshalJSON() ([]byte, error) {
@@ -236,6 +286,7 @@ func (a ResourceComment) MarshalJSON() ([]byte, error) {
 
 type TextElement struct {
 	Value string `json:"value"`
+	Span  *Span  `json:"span,omitempty"`
 }
 
 func (a TextElement) MarshalJSON() ([]byte, error) {
@@ -264,6 +315,7 @@ func (a Placeable) InlineExpression()         {}
 
 type StringLiteral struct {
 	Value string `json:"value"`
+	Span  *Span  `json:"span,omitempty"`
 }
 
 func (a StringLiteral) MarshalJSON() ([]byte, error) {
@@ -278,8 +330,49 @@ func (a StringLiteral) MarshalJSON() ([]byte, error) {
 	return marshal(tmp)
 }
 
+// Unescape returns the value of the string literal with escape sequences
+// replaced by the characters they represent. Code points which are not valid
+// are replaced by U+FFFD.
+func (a StringLiteral) Unescape() string {
+	if !strings.Contains(a.Value, `\`) {
+		return a.Value
+	}
+
+	var b strings.Builder
+	for s := a.Value; s != ""; {
+		i := strings.IndexByte(s, '\\')
+		if i < 0 || i == len(s)-1 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:i])
+		s = s[i+1:]
+
+		digits := 0
+		switch s[0] {
+		case 'u':
+			digits = 4
+		case 'U':
+			digits = 6
+		}
+		if digits == 0 || len(s) < digits+1 {
+			b.WriteByte(s[0])
+			s = s[1:]
+			continue
+		}
+		r, err := strconv.ParseUint(s[1:digits+1], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			r = utf8.RuneError
+		}
+		b.WriteRune(rune(r))
+		s = s[digits+1:]
+	}
+	return b.String()
+}
+
 type NumberLiteral struct {
 	Value string `json:"value"`
+	Span  *Span  `json:"span,omitempty"`
 }
 
 func (a NumberLiteral) MarshalJSON() ([]byte, error) {
@@ -297,6 +390,7 @@ func (a NumberLiteral) MarshalJSON() ([]byte, error) {
 type FunctionReference struct {
 	ID        Identifier    `json:"id"`
 	Arguments CallArguments `json:"arguments"`
+	Span      *Span         `json:"span,omitempty"`
 }
 
 func (a FunctionReference) MarshalJSON() ([]byte, error) {
@@ -314,6 +408,7 @@ func (a FunctionReference) MarshalJSON() ([]byte, error) {
 type MessageReference struct {
 	ID        Identifier  `json:"id"`
 	Attribute *Identifier `json:"attribute"`
+	Span      *Span       `json:"span,omitempty"`
 }
 
 func (a MessageReference) MarshalJSON() ([]byte, error) {
@@ -332,6 +427,7 @@ type TermReference struct {
 	ID        Identifier     `json:"id"`
 	Attribute *Identifier    `json:"attribute"`
 	Arguments *CallArguments `json:"arguments"`
+	Span      *Span          `json:"span,omitempty"`
 }
 
 func (a TermReference) MarshalJSON() ([]byte, error) {
@@ -347,7 +443,8 @@ func (a TermReference) MarshalJSON() ([]byte, error) {
 }
 
 type VariableReference struct {
-	ID Identifier `json:"id"`
+	ID   Identifier `json:"id"`
+	Span *Span      `json:"span,omitempty"`
 }
 
 func (a VariableReference) MarshalJSON() ([]byte, error) {
@@ -364,6 +461,7 @@ func (a VariableReference) MarshalJSON() ([]byte, error) {
 
 type Placeable struct {
 	Expr Expression `json:"expression"`
+	Span *Span      `json:"span,omitempty"`
 }
 
 func (a Placeable) MarshalJSON() ([]byte, error) {
@@ -395,6 +493,7 @@ func (a SelectExpression) Expression()  {}
 type SelectExpression struct {
 	Selector InlineExpression `json:"selector"`
 	Variants []Variant        `json:"variants"`
+	Span     *Span            `json:"span,omitempty"`
 }
 
 func (a SelectExpression) MarshalJSON() ([]byte, error) {
@@ -412,6 +511,7 @@ func (a SelectExpression) MarshalJSON() ([]byte, error) {
 type CallArguments struct {
 	Positional []InlineExpression `json:"positional"`
 	Named      []NamedArgument    `json:"named"`
+	Span       *Span              `json:"span,omitempty"`
 }
 
 func (a CallArguments) MarshalJSON() ([]byte, error) {
@@ -429,6 +529,7 @@ func (a CallArguments) MarshalJSON() ([]byte, error) {
 type NamedArgument struct {
 	Name  Identifier       `json:"name"`
 	Value InlineExpression `json:"value"`
+	Span  *Span            `json:"span,omitempty"`
 }
 
 func (a NamedArgument) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,284 @@
+package syntax
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Severity is how serious a Diagnostic is.
+type Severity int
+
+const (
+	// SeverityError is for problems which make the input invalid.
+	SeverityError Severity = iota
+	// SeverityWarning is for problems which are better fixed, but do not make
+	// the input invalid, e.g. style problems.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Diagnostic is a problem found in the input, such as a parse error.
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	Span     Span
+
+	// Hint optionally suggests how to fix the problem.
+	Hint string
+}
+
+// Diagnostic returns the diagnostic for the error.
+func (e *Error) Diagnostic() Diagnostic {
+	return Diagnostic{
+		Code:    e.Code,
+		Message: e.Message,
+		Span:    Span{Start: e.Offset, End: e.Offset},
+		Hint:    errorHint(e.Code, e.Arguments...),
+	}
+}
+
+// Position returns the line and column of the byte offset in input, both
+// starting at 1. Columns count runes, like the columns of Error, and a leading
+// byte order mark is not counted.
+func Position(input []byte, offset int) (line, column int) {
+	offset = clamp(offset, 0, len(input))
+	lineStart := bytes.LastIndexByte(input[:offset], '\n') + 1
+	line = bytes.Count(input[:offset], []byte("\n")) + 1
+	column = utf8.RuneCount(input[lineStart:offset]) + 1
+	if lineStart == 0 && offset > 0 && bytes.HasPrefix(input, []byte(string(bom))) {
+		column--
+	}
+	return line, column
+}
+
+// ANSI escape codes used by the Renderer.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[1;31m"
+	ansiYellow = "\x1b[1;33m"
+	ansiBlue   = "\x1b[1;34m"
+)
+
+// Renderer writes diagnostics together with the lines of input around them, in
+// the style of the rustc and Elm compilers:
+//
+//	error[E0003]: Expected token: "="
+//	 --> messages.ftl:2:5
+//	  |
+//	1 | hello = Hello
+//	2 | bye Bye
+//	  |     ^
+//	  |
+//	  = hint: ...
+type Renderer struct {
+	// Color enables ANSI escape codes, for output to a terminal.
+	Color bool
+
+	// Context is the number of lines shown before and after the line of the
+	// diagnostic.
+	Context int
+
+	// TabWidth is the distance between tab stops. Tabs are expanded to spaces
+	// so that the underline lines up. Zero means 4.
+	TabWidth int
+}
+
+// Render writes the diagnostic d about input, which was read from filename.
+// An empty filename is left out.
+func (r *Renderer) Render(w io.Writer, filename string, input []byte, d Diagnostic) error {
+	var b strings.Builder
+
+	start := clamp(d.Span.Start, 0, len(input))
+	end := clamp(d.Span.End, start, len(input))
+
+	lineStart := bytes.LastIndexByte(input[:start], '\n') + 1
+	line, col := Position(input, start)
+
+	lines := splitLines(input)
+	first := clamp(line-r.Context, 1, line)
+	last := clamp(line+r.Context, line, len(lines))
+	if last > line && len(lines[last-1]) == 0 {
+		last-- // the end of input after the final newline
+	}
+	gutter := strings.Repeat(" ", len(fmt.Sprint(last)))
+
+	color := ansiRed
+	if d.Severity == SeverityWarning {
+		color = ansiYellow
+	}
+
+	// Header and location.
+	b.WriteString(r.style(color, d.Severity.String()))
+	if d.Code != "" {
+		b.WriteString(r.style(color, "["+d.Code+"]"))
+	}
+	b.WriteString(r.style(ansiBold, ": "+d.Message))
+	b.WriteByte('\n')
+	location := fmt.Sprintf("%d:%d", line, col)
+	if filename != "" {
+		location = filename + ":" + location
+	}
+	fmt.Fprintf(&b, "%s%s %s\n", gutter, r.style(ansiBlue, "-->"), location)
+	fmt.Fprintf(&b, "%s %s\n", gutter, r.style(ansiBlue, "|"))
+
+	// Source lines with the span underlined below the line of the diagnostic.
+	for n := first; n <= last; n++ {
+		text := lines[n-1]
+		number := fmt.Sprintf("%*d", len(gutter), n)
+		fmt.Fprintf(&b, "%s %s", r.style(ansiBlue, number), r.style(ansiBlue, "|"))
+		if expanded := r.expand(text); expanded != "" {
+			b.WriteString(" " + expanded)
+		}
+		b.WriteByte('\n')
+
+		if n != line {
+			continue
+		}
+		lineEnd := clamp(end, start, lineStart+len(text))
+		before := r.width(input[lineStart:start])
+		underline := r.width(input[lineStart:lineEnd]) - before
+		if underline < 1 {
+			underline = 1
+		}
+		fmt.Fprintf(&b, "%s %s %s%s\n", gutter, r.style(ansiBlue, "|"),
+			strings.Repeat(" ", before), r.style(color, strings.Repeat("^", underline)))
+	}
+
+	if d.Hint != "" {
+		fmt.Fprintf(&b, "%s %s\n", gutter, r.style(ansiBlue, "|"))
+		fmt.Fprintf(&b, "%s %s %s\n", gutter, r.style(ansiBlue, "="), r.style(ansiBold, "hint:")+" "+d.Hint)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r *Renderer) style(code, s string) string {
+	if !r.Color {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func (r *Renderer) tabWidth() int {
+	if r.TabWidth <= 0 {
+		return 4
+	}
+	return r.TabWidth
+}
+
+// expand returns a line prepared for display: tabs are expanded to spaces, and
+// control characters and invalid UTF-8 are replaced with U+FFFD.
+func (r *Renderer) expand(line []byte) string {
+	var b strings.Builder
+	col := 0
+	for len(line) > 0 {
+		ch, w := utf8.DecodeRune(line)
+		line = line[w:]
+		switch {
+		case ch == '\t':
+			n := r.tabWidth() - col%r.tabWidth()
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		case unicode.IsControl(ch):
+			ch = utf8.RuneError
+		}
+		b.WriteRune(ch)
+		col += runeWidth(ch)
+	}
+	return b.String()
+}
+
+// width returns the number of terminal columns taken up by the expanded text.
+func (r *Renderer) width(text []byte) int {
+	col := 0
+	for len(text) > 0 {
+		ch, w := utf8.DecodeRune(text)
+		text = text[w:]
+		switch {
+		case ch == '\t':
+			col += r.tabWidth() - col%r.tabWidth()
+		case unicode.IsControl(ch):
+			col++
+		default:
+			col += runeWidth(ch)
+		}
+	}
+	return col
+}
+
+// splitLines splits input into lines without their line endings.
+func splitLines(input []byte) [][]byte {
+	lines := bytes.Split(input, []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimSuffix(line, []byte("\r"))
+	}
+	return lines
+}
+
+// runeWidth returns the number of terminal columns taken up by ch: zero for
+// combining marks, two for wide East Asian characters and one otherwise.
+func runeWidth(ch rune) int {
+	switch {
+	case unicode.In(ch, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isWide(ch):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// wide holds the main ranges of East Asian Wide and Fullwidth characters.
+var wide = []struct{ lo, hi rune }{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x2E80, 0x303E},   // CJK Radicals .. CJK Symbols and Punctuation
+	{0x3041, 0x33FF},   // Hiragana .. CJK Compatibility
+	{0x3400, 0x4DBF},   // CJK Unified Ideographs Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul Syllables
+	{0xF900, 0xFAFF},   // CJK Compatibility Ideographs
+	{0xFE30, 0xFE4F},   // CJK Compatibility Forms
+	{0xFF00, 0xFF60},   // Fullwidth Forms
+	{0xFFE0, 0xFFE6},   // Fullwidth Signs
+	{0x1F300, 0x1F64F}, // Miscellaneous Symbols and Pictographs, Emoticons
+	{0x1F900, 0x1F9FF}, // Supplemental Symbols and Pictographs
+	{0x20000, 0x3FFFD}, // CJK Unified Ideographs Extension B and later
+}
+
+func isWide(ch rune) bool {
+	for _, r := range wide {
+		if ch >= r.lo && ch <= r.hi {
+			return true
+		}
+	}
+	return false
+}
+
+func clamp(x, lo, hi int) int {
+	if x < lo {
+		return lo
+	}
+	if x > hi {
+		return hi
+	}
+	return x
+}
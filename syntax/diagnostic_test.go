@@ -0,0 +1,230 @@
+package syntax
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name       string
+		renderer   Renderer
+		filename   string
+		input      string
+		diagnostic Diagnostic
+		expected   string
+	}{
+		{
+			name:     "context",
+			renderer: Renderer{Context: 1},
+			filename: "messages.ftl",
+			input:    "hello = Hello\nbye Bye\nend = End\n",
+			diagnostic: Diagnostic{
+				Code:    "E0003",
+				Message: `Expected token: "="`,
+				Span:    Span{Start: 18, End: 18},
+			},
+			expected: `
+error[E0003]: Expected token: "="
+ --> messages.ftl:2:5
+  |
+1 | hello = Hello
+2 | bye Bye
+  |     ^
+3 | end = End
+`,
+		},
+		{
+			name:     "hint",
+			input:    "key = a\n",
+			renderer: Renderer{Context: 2},
+			diagnostic: Diagnostic{
+				Code:    "E0010",
+				Message: "Expected one of the variants to be marked as default (*)",
+				Span:    Span{Start: 6, End: 7},
+				Hint:    `Mark the fallback variant with "*", e.g. "*[other]".`,
+			},
+			expected: `
+error[E0010]: Expected one of the variants to be marked as default (*)
+ --> 1:7
+  |
+1 | key = a
+  |       ^
+  |
+  = hint: Mark the fallback variant with "*", e.g. "*[other]".
+`,
+		},
+		{
+			name:  "tabs",
+			input: "key =\n\tvalue {\n",
+			diagnostic: Diagnostic{
+				Message: "Unbalanced",
+				Span:    Span{Start: 7, End: 12},
+			},
+			expected: `
+error: Unbalanced
+ --> 2:2
+  |
+2 |     value {
+  |     ^^^^^
+`,
+		},
+		{
+			name:     "tab stops",
+			renderer: Renderer{TabWidth: 8},
+			input:    "key = ab\t{",
+			diagnostic: Diagnostic{
+				Message: "Expected",
+				Span:    Span{Start: 9, End: 9},
+			},
+			expected: `
+error: Expected
+ --> 1:10
+  |
+1 | key = ab        {
+  |                 ^
+`,
+		},
+		{
+			name:  "wide characters",
+			input: "key = 日本語 { x }",
+			diagnostic: Diagnostic{
+				Message: "Expected",
+				Span:    Span{Start: 16, End: 21},
+			},
+			expected: `
+error: Expected
+ --> 1:11
+  |
+1 | key = 日本語 { x }
+  |              ^^^^^
+`,
+		},
+		{
+			name:  "combining marks",
+			input: "key = e\u0301e\u0301 {",
+			diagnostic: Diagnostic{
+				Message: "Expected",
+				Span:    Span{Start: 13, End: 14},
+			},
+			expected: `
+error: Expected
+ --> 1:12
+  |
+1 | key = e` + "\u0301e\u0301" + ` {
+  |          ^
+`,
+		},
+		{
+			name:  "multiple lines",
+			input: "key = { $x\n  -> }\n",
+			diagnostic: Diagnostic{
+				Message: "Span",
+				Span:    Span{Start: 6, End: 17},
+			},
+			expected: `
+error: Span
+ --> 1:7
+  |
+1 | key = { $x
+  |       ^^^^
+`,
+		},
+		{
+			name:  "end of input",
+			input: "key = {\n",
+			diagnostic: Diagnostic{
+				Message: "Expected",
+				Span:    Span{Start: 8, End: 8},
+			},
+			expected: `
+error: Expected
+ --> 2:1
+  |
+2 |
+  | ^
+`,
+		},
+		{
+			name:  "warning",
+			input: "key = value ",
+			diagnostic: Diagnostic{
+				Severity: SeverityWarning,
+				Code:     "L0001",
+				Message:  "Trailing whitespace",
+				Span:     Span{Start: 11, End: 12},
+			},
+			expected: `
+warning[L0001]: Trailing whitespace
+ --> 1:12
+  |
+1 | key = value 
+  |            ^
+`,
+		},
+		{
+			name:     "color",
+			renderer: Renderer{Color: true},
+			input:    "key",
+			diagnostic: Diagnostic{
+				Code:    "E0003",
+				Message: "Expected",
+				Span:    Span{Start: 3, End: 3},
+			},
+			expected: "\n" +
+				"\x1b[1;31merror\x1b[0m\x1b[1;31m[E0003]\x1b[0m\x1b[1m: Expected\x1b[0m\n" +
+				" \x1b[1;34m-->\x1b[0m 1:4\n" +
+				"  \x1b[1;34m|\x1b[0m\n" +
+				"\x1b[1;34m1\x1b[0m \x1b[1;34m|\x1b[0m key\n" +
+				"  \x1b[1;34m|\x1b[0m    \x1b[1;31m^\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			err := tt.renderer.Render(&b, tt.filename, []byte(tt.input), tt.diagnostic)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected[1:], b.String())
+		})
+	}
+}
+
+func TestErrorDiagnostic(t *testing.T) {
+	_, err := Parse([]byte("key = {\"\\q\" }\n"))
+	require.Error(t, err)
+
+	perrs := err.(*ParseErrors)
+	require.Equal(t, Diagnostic{
+		Code:    "E0025",
+		Message: `Unknown escape sequence: \q.`,
+		Span:    Span{Start: 9, End: 9},
+		Hint:    `Only \\, \" and the unicode escapes \uXXXX and \UXXXXXX are allowed.`,
+	}, perrs.Errors()[0].Diagnostic())
+}
+
+func TestPosition(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+		line   int
+		column int
+	}{
+		{"key = value", 0, 1, 1},
+		{"key = value", 6, 1, 7},
+		{"a = 1\nb = 2", 6, 2, 1},
+		{"a = 1\r\nb = 2", 9, 2, 3},
+		{"a = é\nb", 7, 2, 1},
+		{"a = éb", 6, 1, 6},
+		{"\uFEFFa = b", 3, 1, 1},
+		{"key", 10, 1, 4},
+	}
+
+	for _, tt := range tests {
+		line, column := Position([]byte(tt.input), tt.offset)
+		require.Equal(t, tt.line, line, "line of %d in %q", tt.offset, tt.input)
+		require.Equal(t, tt.column, column, "column of %d in %q", tt.offset, tt.input)
+	}
+}
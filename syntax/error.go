@@ -6,29 +6,163 @@ import (
 	"strings"
 )
 
-type parseError struct {
-	line    int
-	col     int
-	pos     int
-	message string
+// Error is a single parse error at a position in the input.
+type Error struct {
+	Line   int // line number, starting at 1
+	Column int // column number in runes, starting at 1
+	Offset int // byte offset, starting at 0
+
+	// Code identifies the kind of error. Codes and their arguments are shared
+	// with the reference implementations, e.g. E0003.
+	Code      string
+	Arguments []string
+	Message   string
+}
+
+func newParseError(line, col, pos int, code string, args ...string) *Error {
+	if len(args) == 0 {
+		args = nil
+	}
+	return &Error{
+		Line:      line,
+		Column:    col,
+		Offset:    pos,
+		Code:      code,
+		Arguments: args,
+		Message:   errorMessage(code, args...),
+	}
+}
+
+// errorMessage returns the message for an error code. Codes and messages match
+// the projectfluent reference implementations.
+func errorMessage(code string, args ...string) string {
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+
+	switch code {
+	case "E0001":
+		return "Generic error"
+	case "E0002":
+		return "Expected an entry start"
+	case "E0003":
+		return fmt.Sprintf("Expected token: \"%s\"", arg(0))
+	case "E0004":
+		return fmt.Sprintf("Expected a character from range: \"%s\"", arg(0))
+	case "E0005":
+		return fmt.Sprintf("Expected message \"%s\" to have a value or attributes", arg(0))
+	case "E0006":
+		return fmt.Sprintf("Expected term \"-%s\" to have a value", arg(0))
+	case "E0007":
+		return "Keyword cannot end with a whitespace"
+	case "E0008":
+		return "The callee has to be an upper-case identifier or a term"
+	case "E0009":
+		return "The argument name has to be a simple identifier"
+	case "E0010":
+		return "Expected one of the variants to be marked as default (*)"
+	case "E0011":
+		return "Expected at least one variant after \"->\""
+	case "E0012":
+		return "Expected value"
+	case "E0013":
+		return "Expected variant key"
+	case "E0014":
+		return "Expected literal"
+	case "E0015":
+		return "Only one variant can be marked as default (*)"
+	case "E0016":
+		return "Message references cannot be used as selectors"
+	case "E0017":
+		return "Terms cannot be used as selectors"
+	case "E0018":
+		return "Attributes of messages cannot be used as selectors"
+	case "E0019":
+		return "Attributes of terms cannot be used as placeables"
+	case "E0020":
+		return "Unterminated string expression"
+	case "E0021":
+		return "Positional arguments must not follow named arguments"
+	case "E0022":
+		return "Named arguments must be unique"
+	case "E0024":
+		return "Cannot access variants of a message."
+	case "E0025":
+		return fmt.Sprintf("Unknown escape sequence: \\%s.", arg(0))
+	case "E0026":
+		return fmt.Sprintf("Invalid Unicode escape sequence: %s.", arg(0))
+	case "E0027":
+		return "Unbalanced closing brace in TextElement."
+	case "E0028":
+		return "Expected an inline expression"
+	case "E0029":
+		return "Expected simple expression as selector"
+	case "E0030": // Not part of the reference implementations.
+		return "Invalid UTF-8 encoding"
+	default:
+		return "Unknown error"
+	}
 }
 
-func newParseError(line, col, pos int, message string) *parseError {
-	return &parseError{
-		line:    line,
-		col:     col,
-		pos:     pos,
-		message: message,
+// errorHint returns a suggestion on how to fix an error, or an empty string.
+func errorHint(code string, args ...string) string {
+	switch code {
+	case "E0002":
+		return `Entries start at the beginning of a line with an identifier, "-" for terms or "#" for comments.`
+	case "E0003":
+		if len(args) > 0 && args[0] == "␤" {
+			return "Continuation lines of a value must be indented."
+		}
+		return ""
+	case "E0005":
+		return `Add a value after "=" or an indented attribute, e.g. ".title = Title".`
+	case "E0006":
+		return `Add a value after "=".`
+	case "E0010":
+		return `Mark the fallback variant with "*", e.g. "*[other]".`
+	case "E0015":
+		return `Remove the "*" from all but one variant.`
+	case "E0016", "E0017", "E0018":
+		return "Select on a variable, a literal or a term attribute instead."
+	case "E0020":
+		return "Close the string with a double quote on the same line."
+	case "E0025":
+		return `Only \\, \" and the unicode escapes \uXXXX and \UXXXXXX are allowed.`
+	case "E0027":
+		return `Use {"}"} for a literal closing brace.`
+	case "E0030":
+		return "Save the file with UTF-8 encoding."
+	default:
+		return ""
 	}
 }
 
-func (pe *parseError) Error() string {
-	return fmt.Sprintf("%d:%d: %s", pe.line, pe.col, pe.message)
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
 }
 
+// ParseErrors is returned by Parse when the input contains errors. Each error
+// corresponds to a Junk entry of the parsed Resource.
 type ParseErrors struct {
 	input  []byte
-	errors []error
+	errors []*Error
+}
+
+// Errors returns the errors in the order of the input.
+func (pe *ParseErrors) Errors() []*Error {
+	return pe.errors
+}
+
+// Unwrap returns the errors, so that errors.As can extract an *Error.
+func (pe *ParseErrors) Unwrap() []error {
+	errors := make([]error, len(pe.errors))
+	for i, err := range pe.errors {
+		errors[i] = err
+	}
+	return errors
 }
 
 func (pe *ParseErrors) Error() string {
@@ -43,27 +177,9 @@ func (pe *ParseErrors) Format(s fmt.State, verb rune) {
 	switch verb {
 	case 'v':
 		if s.Flag('+') {
-			for _, err := range pe.errors {
-				perr, ok := err.(*parseError)
-				if !ok {
-					fmt.Fprintln(s, err)
-					continue
-				}
-
-				// Grab the current line.
-				start := perr.pos - perr.col + 1 // col is 1 index based
-				pos := perr.pos
-				for pos < len(pe.input) {
-					if pe.input[pos] == '\n' {
-						break
-					}
-					pos++
-				}
-
-				// Print the position, line, and error with a '^' pointing to position where the error occured.
-				fmt.Fprintf(s, "%d:%d\n", perr.line, perr.col)
-				fmt.Fprintf(s, "%s\n", pe.input[start:pos])
-				fmt.Fprintf(s, "%*s %s\n", perr.col, "^", perr.message)
+			r := Renderer{Context: 1}
+			for _, perr := range pe.errors {
+				r.Render(s, "", pe.input, perr.Diagnostic())
 			}
 			return
 		}
@@ -0,0 +1,91 @@
+package syntax
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzParse(f *testing.F) {
+	paths, err := filepath.Glob("testdata/*.ftl")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		input, err := ioutil.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(input)
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		resource, err := Parse(input)
+		if err != nil {
+			_ = fmt.Sprintf("%+v", err)
+		}
+
+		// Every byte of input must belong to some entry. Only blank lines
+		// may appear between entries, and a byte order mark before them.
+		pos := 0
+		if bytes.HasPrefix(input, []byte(string(bom))) {
+			pos = utf8.RuneLen(bom)
+		}
+		for _, entry := range resource.Body {
+			span := entrySpan(entry)
+			if span == nil {
+				t.Fatalf("%T has no span", entry)
+			}
+			if span.Start < pos || span.End < span.Start || span.End > len(input) {
+				t.Fatalf("%T has invalid span [%d, %d) after %d", entry, span.Start, span.End, pos)
+			}
+			assertBlank(t, input[pos:span.Start])
+			if junk, ok := entry.(Junk); ok {
+				if junk.Content != string(input[span.Start:span.End]) {
+					t.Fatalf("junk content %q does not match span %q", junk.Content, input[span.Start:span.End])
+				}
+				for _, annotation := range junk.Annotations {
+					if annotation.Span.Start < span.Start || annotation.Span.End > span.End {
+						t.Fatalf("annotation %s at %d is outside of junk [%d, %d)", annotation.Code, annotation.Span.Start, span.Start, span.End)
+					}
+				}
+			}
+			pos = span.End
+		}
+		assertBlank(t, input[pos:])
+	})
+}
+
+func assertBlank(t *testing.T, b []byte) {
+	t.Helper()
+	for _, c := range b {
+		if c != ' ' && c != '\n' && c != '\r' {
+			t.Fatalf("non-blank input %q not covered by any entry", b)
+		}
+	}
+}
+
+func FuzzReparse(f *testing.F) {
+	paths, err := filepath.Glob("testdata/*.ftl")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		input, err := ioutil.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(input, len(input)/2, len(input)/2+1, []byte("\n"))
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte, start, end int, text []byte) {
+		if start < 0 || start > end || end > len(input) {
+			return
+		}
+		prev, _ := Parse(input)
+		assertReparse(t, prev, input, Edit{Start: start, End: end, Text: text})
+	})
+}
@@ -0,0 +1,51 @@
+package syntax
+
+// InspectPattern calls f for every expression in the pattern, including
+// expressions nested in placeables, call arguments, selectors and variants, in
+// the order of the input.
+func InspectPattern(pattern Pattern, f func(Expression)) {
+	for _, element := range pattern.Elements {
+		if placeable, ok := element.(Placeable); ok {
+			inspectExpression(placeable.Expr, f)
+		}
+	}
+}
+
+func inspectExpression(expr Expression, f func(Expression)) {
+	if expr == nil {
+		return
+	}
+	f(expr)
+
+	switch expr := expr.(type) {
+	case Placeable:
+		inspectExpression(expr.Expr, f)
+	case SelectExpression:
+		if selector, ok := expr.Selector.(Expression); ok {
+			inspectExpression(selector, f)
+		}
+		for _, variant := range expr.Variants {
+			InspectPattern(variant.Value, f)
+		}
+	case FunctionReference:
+		inspectArguments(&expr.Arguments, f)
+	case TermReference:
+		inspectArguments(expr.Arguments, f)
+	}
+}
+
+func inspectArguments(arguments *CallArguments, f func(Expression)) {
+	if arguments == nil {
+		return
+	}
+	for _, argument := range arguments.Positional {
+		if expr, ok := argument.(Expression); ok {
+			inspectExpression(expr, f)
+		}
+	}
+	for _, argument := range arguments.Named {
+		if expr, ok := argument.Value.(Expression); ok {
+			inspectExpression(expr, f)
+		}
+	}
+}
@@ -1,246 +1,342 @@
 package syntax
 
 import (
-	"fmt"
-	"os"
+	"bytes"
 	"strings"
-	"unicode"
 	"unicode/utf8"
 )
 
-var eof = rune(0)
+// eof is returned by the parser when the end of input has been reached. It
+// lies outside of the valid rune range so that NUL bytes in the input are
+// treated like any other character.
+const eof = rune(-1)
+
+// A Mode value is a set of flags (or 0). They control optional parser
+// functionality.
+type Mode uint
+
+const (
+	// ReplaceInvalidUTF8 replaces each invalid UTF-8 byte in parsed values
+	// with U+FFFD instead of turning the entry into Junk.
+	ReplaceInvalidUTF8 Mode = 1 << iota
+)
+
+// bom is the UTF-8 encoded byte order mark.
+const bom = '\uFEFF'
 
 func Parse(input []byte) (Resource, error) {
-	return newParser(input).parse()
+	return ParseMode(input, 0)
+}
+
+// ParseMode parses input like Parse, with optional functionality controlled by
+// mode.
+func ParseMode(input []byte, mode Mode) (Resource, error) {
+	return newParser(input, mode).parse()
+}
+
+// ParsePattern parses the text of a single pattern, as returned by
+// FormatPattern, e.g. "Hello, { $name }!". The error is an *Error with a
+// position in text.
+func ParsePattern(text string) (Pattern, error) {
+	// Parse the pattern as the value of a message. Lines are indented so that
+	// relative indentation is kept, unless the pattern starts with a character
+	// which is not allowed at the start of a line.
+	const key = "key ="
+	inline := strings.HasPrefix(text, "[") || strings.HasPrefix(text, "*") || strings.HasPrefix(text, ".")
+	first := "\n    "
+	if inline {
+		first = " "
+	}
+	prefix := key + first
+	input := prefix + strings.Replace(text, "\n", "\n    ", -1) + "\n"
+
+	// toText maps an offset in input to the offset in text.
+	toText := func(offset int) int {
+		lines := strings.Count(input[len(prefix):clamp(offset, len(prefix), len(input))], "\n")
+		return clamp(offset-len(prefix)-4*lines, 0, len(text))
+	}
+
+	resource, err := Parse([]byte(input))
+	if err != nil {
+		perr := err.(*ParseErrors).Errors()[0]
+		offset := toText(perr.Offset)
+		line, column := Position([]byte(text), offset)
+		return Pattern{}, &Error{
+			Line:      line,
+			Column:    column,
+			Offset:    offset,
+			Code:      perr.Code,
+			Arguments: perr.Arguments,
+			Message:   perr.Message,
+		}
+	}
+
+	if len(resource.Body) == 1 {
+		if message, ok := resource.Body[0].(Message); ok && message.Value != nil && len(message.Attributes) == 0 {
+			return mapPattern(*message.Value, toText), nil
+		}
+	}
+	return Pattern{}, &Error{Line: 1, Column: 1, Message: "Expected a single pattern"}
 }
 
 type parser struct {
 	input []byte
+	mode  Mode
 	pos   int
 	ch    rune
 	w     int
 	line  int
 	col   int
+
+	// err is the first encoding error encountered in the current entry.
+	err *Error
+
+	// reuse holds entries from a previous parse, with spans shifted to the
+	// current input. Once parsing reaches the start of one of them, it and
+	// all following entries are taken as they are.
+	reuse []Entry
 }
 
-func newParser(input []byte) *parser {
+func newParser(input []byte, mode Mode) *parser {
 	p := parser{
 		input: input,
+		mode:  mode,
 		pos:   0,
 		line:  1,
 	}
 	p.next()
 
+	// A leading byte order mark is not part of the content.
+	if p.ch == bom {
+		p.next()
+		p.col = 1
+	}
+
 	return &p
 }
 
 // next advances the parser by one rune. Updates line and column on the parser.
+// A CRLF sequence is read as a single '\n'.
 func (p *parser) next() {
+	if p.ch == eof {
+		return
+	}
+
 	if p.ch == '\n' {
 		p.line++
 		p.col = 0
 	}
 	p.col++
 
+	p.pos += p.w
 	if p.pos >= len(p.input) {
-		p.ch = eof
+		p.pos = len(p.input)
+		p.ch, p.w = eof, 0
 		return
 	}
 
-	// TODO: check for utf8 errors
-	p.pos += p.w
-	p.ch, p.w = utf8.DecodeRune(p.input[p.pos:])
-	if p.ch == utf8.RuneError && p.w == 1 {
-		// invalid utf8 encoding
-		fmt.Fprintln(os.Stderr, "invalid utf8 encoding")
+	p.ch, p.w = p.decode(p.pos)
+	if p.ch == utf8.RuneError && p.w == 1 && p.mode&ReplaceInvalidUTF8 == 0 && p.err == nil {
+		p.err = newParseError(p.line, p.col, p.pos, "E0030")
 	}
+}
 
-	return
+// decode returns the rune at pos and its width in bytes.
+func (p *parser) decode(pos int) (rune, int) {
+	if pos >= len(p.input) {
+		return eof, 0
+	}
+	if p.input[pos] == '\r' && pos+1 < len(p.input) && p.input[pos+1] == '\n' {
+		return '\n', 2
+	}
+	return utf8.DecodeRune(p.input[pos:])
 }
 
+// peek returns the rune following the current one.
 func (p *parser) peek() rune {
-	if p.pos > len(p.input)-1 {
-		return eof
-	}
-	ch, _ := utf8.DecodeRune(p.input[p.pos+p.w:])
+	ch, _ := p.decode(p.pos + p.w)
 	return ch
 }
 
-func (p *parser) peekn(n int) string {
-	var runes []rune
+// state is a snapshot of the parser position used for backtracking.
+type state struct {
+	pos  int
+	ch   rune
+	w    int
+	line int
+	col  int
+	err  *Error
+}
 
-	pos := p.pos + p.w
-	for i := 0; i < n; i++ {
-		r, size := utf8.DecodeRune(p.input[pos:])
-		if size > 0 {
-			pos += size
-			runes = append(runes, r)
-		}
+func (p *parser) save() state {
+	return state{
+		pos:  p.pos,
+		ch:   p.ch,
+		w:    p.w,
+		line: p.line,
+		col:  p.col,
+		err:  p.err,
 	}
+}
 
-	return string(runes)
+func (p *parser) restore(s state) {
+	p.pos, p.ch, p.w = s.pos, s.ch, s.w
+	p.line, p.col = s.line, s.col
+	p.err = s.err
 }
 
-func (p *parser) errorf(format string, a ...interface{}) error {
-	return p.error(fmt.Sprintf(format, a...))
+// seek moves the parser forward to pos, which must not be before the current
+// position, without parsing the input in between.
+func (p *parser) seek(pos int) {
+	if lines := bytes.Count(p.input[p.pos:pos], []byte("\n")); lines > 0 {
+		lineStart := bytes.LastIndexByte(p.input[:pos], '\n') + 1
+		p.line += lines
+		p.col = utf8.RuneCount(p.input[lineStart:pos]) + 1
+	} else {
+		p.col += utf8.RuneCount(p.input[p.pos:pos])
+	}
+
+	p.pos = pos
+	p.ch, p.w = p.decode(pos)
+	p.err = nil
+	if p.ch == utf8.RuneError && p.w == 1 && p.mode&ReplaceInvalidUTF8 == 0 {
+		p.err = newParseError(p.line, p.col, p.pos, "E0030")
+	}
 }
 
-func (p *parser) error(message string) error {
-	return newParseError(p.line, p.col, p.pos, message)
+func (p *parser) error(code string, args ...string) error {
+	return newParseError(p.line, p.col, p.pos, code, args...)
 }
 
-func (p *parser) skipWhitespace() {
-	for p.ch == ' ' || p.ch == '\t' || p.ch == '\n' {
-		p.next()
-	}
+// span returns the span from start to the current position.
+func (p *parser) span(start int) *Span {
+	return &Span{Start: start, End: p.pos}
 }
 
-func (p *parser) isEOL() bool {
-	switch {
-	case p.ch == '\n':
-		return true
-	case p.ch == '\r' && p.peek() == '\n':
-		return true
-	default:
-		return false
+func (p *parser) expect(ch rune) error {
+	if p.ch != ch {
+		return p.error("E0003", string(ch))
 	}
+	p.next()
+	return nil
 }
 
-func (p *parser) skipEOL() bool {
-	switch {
-	case p.ch == '\n':
-		p.next()
-		return true
-	case p.ch == '\r' && p.peek() == '\n':
-		p.next()
+// expectLineEnd skips a line end. The end of input is a valid line end.
+func (p *parser) expectLineEnd() error {
+	switch p.ch {
+	case eof:
+		return nil
+	case '\n':
 		p.next()
-		return true
+		return nil
 	default:
-		return false
+		return p.error("E0003", "␤") // SYMBOL FOR NEWLINE
 	}
 }
 
 func (p *parser) skipBlankInline() int {
-	start := p.pos
+	count := 0
 	for p.ch == ' ' {
 		p.next()
+		count++
 	}
-	return p.pos - start
+	return count
 }
 
+// skipBlankBlock skips blank lines and returns how many were skipped. The
+// parser is left at the start of the first non-blank line. Trailing blanks at
+// the end of input are skipped as well.
 func (p *parser) skipBlankBlock() int {
 	var count int
 	for {
-		start, ch := p.pos, p.ch
+		start := p.save()
 		p.skipBlankInline()
-		if !p.skipEOL() {
-			p.col = 1
-			p.pos, p.ch = start, ch
+		if p.ch == eof {
+			break
+		}
+		if p.ch != '\n' {
+			p.restore(start)
 			break
 		}
+		p.next()
 		count++
 	}
 	return count
 }
 
 func (p *parser) skipBlank() {
-	for {
-		p.skipBlankInline()
-		if !p.skipEOL() {
-			break
-		}
-	}
-}
-
-func (p *parser) skipToNextEntryStart() {
-	for p.ch != eof {
-		newline := false
-		if p.pos == 0 || p.input[p.pos-1] == '\n' {
-			newline = true
-		}
-		if newline && (isLetter(p.ch) || p.ch == '-' || p.ch == '#') {
-			break
-		}
+	for p.ch == ' ' || p.ch == '\n' {
 		p.next()
 	}
 }
 
-func (p *parser) skipUnicodeEscapeSequence() error {
-	var need int
-	switch p.ch {
-	case 'u':
-		need = 4
-	case 'U':
-		need = 6
+// skipToNextEntryStart skips to the start of the next line which looks like an
+// entry start. start is the position of the broken entry.
+func (p *parser) skipToNextEntryStart(start int) {
+	// If the last seen line end is after the start of the junk it is safe to
+	// rewind to it, so that the line with the error is inspected as well.
+	if i := bytes.LastIndexByte(p.input[:p.pos], '\n'); i > start {
+		if p.input[i-1] == '\r' {
+			i--
+		}
+		lineStart := bytes.LastIndexByte(p.input[:i], '\n') + 1
+		ch, w := p.decode(i)
+		p.restore(state{
+			pos:  i,
+			ch:   ch,
+			w:    w,
+			line: p.line - 1,
+			col:  utf8.RuneCount(p.input[lineStart:i]) + 1,
+			err:  p.err,
+		})
 	}
-	p.next()
 
-	taken := 0
 	for p.ch != eof {
-		if taken >= need {
-			break
+		if p.ch != '\n' {
+			p.next()
+			continue
 		}
-		if !isHex(p.ch) {
+		p.next()
+		if isLetter(p.ch) || p.ch == '-' || p.ch == '#' {
 			break
 		}
-		taken++
-		p.next()
 	}
-	if taken < need {
-		return p.error("invalid unicode escape sequence")
-	}
-	return nil
-}
-
-func (p *parser) skipDigits() int {
-	count := 0
-	for isDigit(p.ch) {
-		p.next()
-		count++
-	}
-	return count
-}
-
-func (p *parser) debug(n int) {
-	min := p.pos - n
-	if min < 0 {
-		min = 0
-	}
-
-	max := p.pos + n
-	if max > len(p.input)-1 {
-		max = len(p.input) - 1
-	}
-
-	fmt.Println(string(p.input[min:max]))
 }
 
 // parse parses the root level resource.
 func (p *parser) parse() (Resource, error) {
-	var errors []error
-
 	p.skipBlankBlock()
+	return p.parseEntries(make([]Entry, 0), nil)
+}
 
-	entries := make([]Entry, 0)
+// parseEntries parses the entries from the current position to the end of
+// input, appending them to entries and their errors to errors.
+func (p *parser) parseEntries(entries []Entry, errors []*Error) (Resource, error) {
 	var lastComment *Comment
 
-	for p.pos < len(p.input) {
-		start := p.pos
+	for p.ch != eof {
+		for len(p.reuse) > 0 && entrySpan(p.reuse[0]).Start < p.pos {
+			p.reuse = p.reuse[1:]
+		}
+		if lastComment == nil && len(p.reuse) > 0 && entrySpan(p.reuse[0]).Start == p.pos {
+			for _, entry := range p.reuse {
+				if junk, ok := entry.(Junk); ok {
+					errors = append(errors, p.junkErrors(junk)...)
+				}
+			}
+			entries = append(entries, p.reuse...)
+			break
+		}
 
-		entry, err := p.parseEntry()
+		entry, err := p.parseEntryOrJunk()
 		if err != nil {
 			errors = append(errors, err)
-
-			p.skipToNextEntryStart()
-			content := string(p.input[start:p.pos])
-			entry = Junk{
-				Content:     content,
-				Annotations: make([]Annotation, 0),
-			}
 		}
 
+		// Comments are attached to a directly following message or term,
+		// but stand alone when followed by anything else, including Junk.
 		blankLines := p.skipBlankBlock()
-		if comment, ok := entry.(Comment); ok && blankLines == 0 {
+		if comment, ok := entry.(Comment); ok && blankLines == 0 && p.ch != eof {
 			lastComment = &comment
 			continue
 		}
@@ -249,9 +345,11 @@ func (p *parser) parse() (Resource, error) {
 			switch v := entry.(type) {
 			case Message:
 				v.Comment = lastComment
+				v.Span.Start = lastComment.Span.Start
 				entry = v
 			case Term:
 				v.Comment = lastComment
+				v.Span.Start = lastComment.Span.Start
 				entry = v
 			default:
 				entries = append(entries, *lastComment)
@@ -262,13 +360,9 @@ func (p *parser) parse() (Resource, error) {
 		entries = append(entries, entry)
 	}
 
-	if lastComment != nil {
-		entries = append(entries, *lastComment)
-		lastComment = nil
-	}
-
 	resource := Resource{
 		Body: entries,
+		Span: &Span{Start: 0, End: len(p.input)},
 	}
 
 	var err error
@@ -282,86 +376,141 @@ func (p *parser) parse() (Resource, error) {
 	return resource, err
 }
 
-func (p *parser) parseEntry() (Entry, error) {
-	switch p.ch {
-	case '#':
-		return p.parseComment()
-	case '-':
-		return p.parseTerm()
-	default:
-		return p.parseMessage()
+// parseEntryOrJunk parses the next entry. If the entry is invalid it is
+// returned as Junk along with the error.
+func (p *parser) parseEntryOrJunk() (Entry, *Error) {
+	start := p.pos
+
+	entry, err := p.parseEntry()
+	if err == nil {
+		err = p.expectLineEnd()
+	}
+	if p.err != nil && p.err.Offset < p.pos {
+		// An encoding error always precedes any syntax error in the same
+		// entry, since parsing stops at the first one.
+		err = p.err
+	}
+	if err == nil {
+		return entry, nil
 	}
-}
 
-// parseCommentLevel returns to
-func (p *parser) parseCommentLevel() int {
-	level := 0
-	for p.ch != eof {
-		if p.ch != '#' {
-			break
-		}
-		level++
-		p.next()
+	perr, ok := err.(*Error)
+	if !ok {
+		perr = newParseError(p.line, p.col, p.pos, "E0001")
+	}
+
+	p.skipToNextEntryStart(start)
+	if p.err != nil && p.err.Offset < p.pos {
+		p.err = nil
+	}
+
+	// The position of the error must be inside of the Junk's span.
+	if perr.Offset > p.pos {
+		perr = newParseError(p.line, p.col, p.pos, perr.Code, perr.Arguments...)
 	}
-	return level
+
+	args := perr.Arguments
+	if args == nil {
+		args = make([]string, 0)
+	}
+
+	junk := Junk{
+		Content: string(p.input[start:p.pos]),
+		Annotations: []Annotation{{
+			Code:      perr.Code,
+			Arguments: args,
+			Message:   perr.Message,
+			Span:      &Span{Start: perr.Offset, End: perr.Offset},
+		}},
+		Span: p.span(start),
+	}
+
+	return junk, perr
 }
 
-func (p *parser) parseCommentLine() string {
-	start := p.pos
-	for p.ch != eof {
-		if p.isEOL() {
-			break
-		}
-		p.next()
+func (p *parser) parseEntry() (Entry, error) {
+	switch {
+	case p.ch == '#':
+		return p.parseComment()
+	case p.ch == '-':
+		return p.parseTerm()
+	case isLetter(p.ch):
+		return p.parseMessage()
+	default:
+		return nil, p.error("E0002")
 	}
-	line := string(p.input[start:p.pos])
-	return line
 }
 
 func (p *parser) parseComment() (Entry, error) {
-	var lines []string
+	start := p.pos
 
-	lastLevel := 0
-	for p.ch != eof {
-		pos, ch := p.pos, p.ch
-		level := p.parseCommentLevel()
-		if level == 0 {
-			break
+	// 0 - comment, 1 - group comment, 2 - resource comment
+	level := -1
+	var content strings.Builder
+	for {
+		max := level
+		if level == -1 {
+			max = 2
 		}
-		if lastLevel != 0 && level != lastLevel {
-			p.pos, p.ch = pos, ch
-			break
+		i := -1
+		for p.ch == '#' && i < max {
+			p.next()
+			i++
+		}
+		if level == -1 {
+			level = i
 		}
-		lastLevel = level
 
-		var line string
-		if !p.isEOL() {
-			if p.ch != ' ' {
-				return Comment{}, p.error(fmt.Sprintf("expected %q, found %q", ' ', p.ch))
+		if p.ch != '\n' && p.ch != eof {
+			if err := p.expect(' '); err != nil {
+				return nil, err
+			}
+			for p.ch != '\n' && p.ch != eof {
+				content.WriteRune(p.ch)
+				p.next()
 			}
-			p.next() // skip ' '
-			line = p.parseCommentLine()
 		}
 
-		lines = append(lines, line)
-		p.skipEOL()
+		if !p.isNextLineComment(level) {
+			break
+		}
+		content.WriteRune('\n')
+		p.next()
 	}
 
-	content := strings.Join(lines, "\n")
-
-	switch lastLevel {
+	switch level {
+	case 0:
+		return Comment{Content: content.String(), Span: p.span(start)}, nil
 	case 1:
-		return Comment{Content: content}, nil
-	case 2:
-		return GroupComment{Content: content}, nil
-	case 3:
-		return ResourceComment{Content: content}, nil
+		return GroupComment{Content: content.String(), Span: p.span(start)}, nil
 	default:
-		panic("shouldn't happen")
+		return ResourceComment{Content: content.String(), Span: p.span(start)}, nil
+	}
+}
+
+// isNextLineComment reports whether the next line continues a comment of the
+// given level.
+func (p *parser) isNextLineComment(level int) bool {
+	if p.ch != '\n' {
+		return false
+	}
+
+	start := p.save()
+	defer p.restore(start)
+
+	p.next()
+	for i := 0; i <= level; i++ {
+		if p.ch != '#' {
+			return false
+		}
+		p.next()
 	}
+	return p.ch == ' ' || p.ch == '\n' || p.ch == eof
 }
 
 func (p *parser) parseMessage() (Message, error) {
+	start := p.pos
+
 	id, err := p.parseIdentifier()
 	if err != nil {
 		return Message{}, err
@@ -369,195 +518,358 @@ func (p *parser) parseMessage() (Message, error) {
 
 	p.skipBlankInline()
 
-	if p.ch != '=' {
-		return Message{}, p.error(fmt.Sprintf("expected %q, got %q", '=', p.ch))
-	}
-	p.next()
-
-	pattern, err := p.parsePattern()
-	if err != nil {
+	if err := p.expect('='); err != nil {
 		return Message{}, err
 	}
 
-	p.skipBlankBlock()
-
-	attributes, err := p.parseAttributes()
+	pattern, err := p.maybeParsePattern()
 	if err != nil {
 		return Message{}, err
 	}
 
-	if len(pattern.Elements) == 0 && len(attributes) == 0 {
-		return Message{}, p.error("expected message field")
+	attributes, attrErr := p.parseAttributes()
+
+	if pattern == nil && len(attributes) == 0 {
+		if attrErr != nil {
+			return Message{}, attrErr
+		}
+		return Message{}, p.error("E0005", id.Name)
 	}
 
 	message := Message{
 		ID:         id,
+		Value:      pattern,
 		Attributes: attributes,
-	}
-
-	if len(pattern.Elements) != 0 {
-		message.Value = &pattern
+		Span:       p.span(start),
 	}
 
 	return message, nil
 }
 
-func (p *parser) parsePattern() (Pattern, error) {
-	var elements []PatternElement
-
-	var (
-		block        = false // inline or block text/placeable
-		lastNonBlank = -1    // last non blank element index
-		commonIndent = 0     // track comment indent for detentation
-	)
-
-	p.skipBlankInline()
+func (p *parser) parseTerm() (Term, error) {
+	start := p.pos
 
-	if p.skipEOL() {
-		p.skipBlankBlock()
-		block = true
+	if err := p.expect('-'); err != nil {
+		return Term{}, err
 	}
 
-	for p.ch != eof {
-		if p.skipEOL() {
-			block = true
-		}
-
-		if p.ch == '{' {
-			element, err := p.parsePlaceable()
-			if err != nil {
-				return Pattern{}, err
-			}
-			lastNonBlank = len(elements)
-			elements = append(elements, element)
-			continue
-		}
+	id, err := p.parseIdentifier()
+	if err != nil {
+		return Term{}, err
+	}
 
-		if block {
-			start, ch := p.pos, p.ch
-			indent := p.skipBlankInline()
-			if indent == 0 && !p.isEOL() {
-				break
-			}
-			if p.ch == '[' || p.ch == '*' || p.ch == '.' {
-				break
-			}
+	p.skipBlankInline()
 
-			if indent > 0 &&
-				(indent < commonIndent || commonIndent == 0) {
-				commonIndent = indent
-			}
-			p.pos, p.ch = start, ch
-		}
+	if err := p.expect('='); err != nil {
+		return Term{}, err
+	}
 
-		element, err := p.parseTextElement()
-		if err != nil {
-			return Pattern{}, err
-		}
+	value, err := p.maybeParsePattern()
+	if err != nil {
+		return Term{}, err
+	}
+	if value == nil {
+		return Term{}, p.error("E0006", id.Name)
+	}
 
-		if element.Value != "" {
-			lastNonBlank = len(elements)
-		}
+	attributes, _ := p.parseAttributes()
 
-		elements = append(elements, element)
+	term := Term{
+		ID:         id,
+		Value:      *value,
+		Attributes: attributes,
+		Span:       p.span(start),
 	}
 
-	if lastNonBlank < 0 {
+	return term, nil
+}
+
+// parseAttributes parses the attributes of a message or term. An attribute
+// which fails to parse is not part of the entry and is left to be parsed as
+// Junk of its own. Its error is returned so that it can be reported if the
+// entry turns out to be invalid without it.
+func (p *parser) parseAttributes() ([]Attribute, error) {
+	attributes := make([]Attribute, 0)
 
-		// TODO: Should this be an error?
-		return Pattern{}, nil
-	}
+	for {
+		start := p.save()
 
-	// dedent common indent, remove trailing whitespace, and join adjacent text elements
-	var processed []PatternElement
+		p.skipBlank()
 
-	// TODO: trailing newlines are being included.
-	indent := strings.Repeat(" ", commonIndent)
-	var buf []string
-	for _, element := range elements {
-		if text, ok := element.(TextElement); ok {
-			s := strings.TrimPrefix(text.Value, indent)
-			buf = append(buf, s)
-			continue
-		}
-		if len(buf) > 0 {
-			text := TextElement{
-				Value: strings.Join(buf, "\n"),
-			}
-			processed = append(processed, text)
-			buf = nil
-		}
-		processed = append(processed, element)
-	}
-	if len(buf) > 0 {
-		value := strings.TrimRightFunc(strings.Join(buf, "\n"), unicode.IsSpace)
-		if value != "" {
-			text := TextElement{
-				Value: value,
-			}
-			processed = append(processed, text)
+		if p.ch != '.' {
+			p.restore(start)
+			return attributes, nil
 		}
-	}
 
-	pattern := Pattern{
-		Elements: processed,
+		attr, err := p.parseAttribute()
+		if err != nil {
+			p.restore(start)
+			return attributes, err
+		}
+		attributes = append(attributes, attr)
 	}
-	return pattern, nil
 }
 
-func (p *parser) parsePlaceable() (Placeable, error) {
-	p.next() // skip '{'
+func (p *parser) parseAttribute() (Attribute, error) {
+	start := p.pos
 
-	p.skipBlank()
+	if err := p.expect('.'); err != nil {
+		return Attribute{}, err
+	}
 
-	expr, err := p.parseExpression()
+	id, err := p.parseIdentifier()
 	if err != nil {
-		return Placeable{}, err
+		return Attribute{}, err
 	}
 
 	p.skipBlankInline()
 
-	if p.ch != '}' {
-		return Placeable{}, p.error("missing closing '}'")
+	if err := p.expect('='); err != nil {
+		return Attribute{}, err
+	}
+
+	value, err := p.maybeParsePattern()
+	if err != nil {
+		return Attribute{}, err
+	}
+	if value == nil {
+		return Attribute{}, p.error("E0012")
+	}
+
+	attr := Attribute{
+		ID:    id,
+		Value: *value,
+		Span:  p.span(start),
+	}
+
+	return attr, nil
+}
+
+func (p *parser) parseIdentifier() (Identifier, error) {
+	start := p.pos
+
+	if !isLetter(p.ch) {
+		return Identifier{}, p.error("E0004", "a-zA-Z")
 	}
 	p.next()
 
-	placeable := Placeable{
-		Expr: expr,
+	for isLetter(p.ch) || isDigit(p.ch) || p.ch == '_' || p.ch == '-' {
+		p.next()
 	}
 
-	return placeable, nil
+	id := Identifier{
+		Name: string(p.input[start:p.pos]),
+		Span: p.span(start),
+	}
+
+	return id, nil
+}
+
+// maybeParsePattern parses an inline or a block pattern. It returns nil if
+// there is no pattern.
+//
+// Inline patterns start on the same line as the identifier, block patterns
+// start on a new line. The indent of the first line of a block pattern is
+// taken into account when calculating the common indent.
+func (p *parser) maybeParsePattern() (*Pattern, error) {
+	start := p.save()
+
+	p.skipBlankInline()
+	if p.ch != '\n' && p.ch != eof {
+		pattern, err := p.parsePattern(false)
+		return &pattern, err
+	}
+
+	p.skipBlankBlock()
+	if p.isValueContinuation() {
+		pattern, err := p.parsePattern(true)
+		return &pattern, err
+	}
+
+	p.restore(start)
+	return nil, nil
+}
+
+// isValueContinuation reports whether the line starting at the current
+// position continues a pattern.
+func (p *parser) isValueContinuation() bool {
+	start := p.save()
+	defer p.restore(start)
+
+	indent := p.skipBlankInline()
+	switch {
+	case p.ch == '{':
+		return true
+	case indent == 0:
+		return false
+	default:
+		return p.ch != eof && !isSpecialLineStart(p.ch)
+	}
+}
+
+// indent is a temporary pattern element holding blank lines and indentation
+// until the common indent of the pattern is known.
+type indent struct {
+	value string
+	span  Span
 }
 
-// parses a text element up to but not including a newline.
-// TODO: consider changing this to just return a line of text
-func (p *parser) parseTextElement() (TextElement, error) {
+func (a indent) PatternElement() {}
+
+func (p *parser) parsePattern(block bool) (Pattern, error) {
 	start := p.pos
 
-loop:
+	var elements []PatternElement
+
+	commonIndent := -1
+	if block {
+		// Measure the indent of the first line of a block pattern.
+		blankStart := p.pos
+		n := p.skipBlankInline()
+		elements = append(elements, indent{
+			value: strings.Repeat(" ", n),
+			span:  Span{Start: blankStart, End: p.pos},
+		})
+		commonIndent = n
+	}
+
 	for p.ch != eof {
-		if p.isEOL() {
-			break
+		if p.ch == '\n' {
+			blankStart := p.save()
+			lines := p.skipBlankBlock()
+			if !p.isValueContinuation() {
+				// A line end which is not followed by a continuation ends
+				// the pattern.
+				p.restore(blankStart)
+				break
+			}
+
+			n := p.skipBlankInline()
+			if commonIndent == -1 || n < commonIndent {
+				commonIndent = n
+			}
+			elements = append(elements, indent{
+				value: strings.Repeat("\n", lines) + strings.Repeat(" ", n),
+				span:  Span{Start: blankStart.pos, End: p.pos},
+			})
+			continue
 		}
+
 		switch p.ch {
-		case '{':
-			break loop
 		case '}':
-			return TextElement{}, p.error("unbalanced closing '}'")
+			return Pattern{}, p.error("E0027")
+		case '{':
+			element, err := p.parsePlaceable()
+			if err != nil {
+				return Pattern{}, err
+			}
+			elements = append(elements, element)
+		default:
+			elements = append(elements, p.parseTextElement())
+		}
+	}
+
+	pattern := Pattern{
+		Elements: dedent(elements, commonIndent),
+		Span:     p.span(start),
+	}
+	return pattern, nil
+}
+
+// dedent removes the common indent from the beginning of text lines, joins
+// adjacent text elements, and trims trailing whitespace from the pattern.
+func dedent(elements []PatternElement, commonIndent int) []PatternElement {
+	trimmed := make([]PatternElement, 0, len(elements))
+
+	for _, element := range elements {
+		var text TextElement
+		switch v := element.(type) {
+		case indent:
+			value := v.value[:len(v.value)-commonIndent]
+			if value == "" {
+				continue
+			}
+			span := v.span
+			text = TextElement{Value: value, Span: &span}
+		case TextElement:
+			text = v
 		default:
+			trimmed = append(trimmed, element)
+			continue
+		}
+
+		if len(trimmed) > 0 {
+			if prev, ok := trimmed[len(trimmed)-1].(TextElement); ok {
+				trimmed[len(trimmed)-1] = TextElement{
+					Value: prev.Value + text.Value,
+					Span:  &Span{Start: prev.Span.Start, End: text.Span.End},
+				}
+				continue
+			}
 		}
+
+		trimmed = append(trimmed, text)
+	}
+
+	if len(trimmed) > 0 {
+		if text, ok := trimmed[len(trimmed)-1].(TextElement); ok {
+			text.Value = strings.TrimRight(text.Value, " \r\n")
+			if text.Value == "" {
+				trimmed = trimmed[:len(trimmed)-1]
+			} else {
+				trimmed[len(trimmed)-1] = text
+			}
+		}
+	}
+
+	return trimmed
+}
+
+// parseTextElement parses text up to a placeable or the end of the line.
+func (p *parser) parseTextElement() TextElement {
+	start := p.pos
+
+	var value strings.Builder
+	for p.ch != eof && p.ch != '\n' && !isSpecialTextChar(p.ch) {
+		value.WriteRune(p.ch)
 		p.next()
 	}
 
-	value := string(p.input[start:p.pos])
-	text := TextElement{
-		Value: value,
+	return TextElement{
+		Value: value.String(),
+		Span:  p.span(start),
+	}
+}
+
+func (p *parser) parsePlaceable() (Placeable, error) {
+	start := p.pos
+
+	if err := p.expect('{'); err != nil {
+		return Placeable{}, err
+	}
+
+	p.skipBlank()
+
+	expr, err := p.parseExpression()
+	if err != nil {
+		return Placeable{}, err
+	}
+
+	if err := p.expect('}'); err != nil {
+		return Placeable{}, err
+	}
+
+	placeable := Placeable{
+		Expr: expr,
+		Span: p.span(start),
 	}
 
-	return text, nil
+	return placeable, nil
 }
 
 func (p *parser) parseExpression() (Expression, error) {
+	start := p.pos
+
 	selector, err := p.parseInlineExpression()
 	if err != nil {
 		return nil, err
@@ -566,123 +878,123 @@ func (p *parser) parseExpression() (Expression, error) {
 	p.skipBlank()
 
 	if p.ch != '-' || p.peek() != '>' {
-		if ref, ok := selector.(TermReference); ok {
-			if ref.Attribute != nil {
-				return nil, p.error("term attribute as placeable")
-			}
+		if ref, ok := selector.(TermReference); ok && ref.Attribute != nil {
+			return nil, p.error("E0019")
 		}
-		return selector.(Expression), nil
+		expr, ok := selector.(Expression)
+		if !ok {
+			return nil, p.error("E0028")
+		}
+		return expr, nil
 	}
 
-	if ref, ok := selector.(MessageReference); ok {
-		if ref.Attribute == nil {
-			return nil, p.error("message reference as selector")
+	switch v := selector.(type) {
+	case MessageReference:
+		if v.Attribute == nil {
+			return nil, p.error("E0016")
 		}
-		return nil, p.error("message attribute as selector")
-	}
-	if ref, ok := selector.(TermReference); ok {
-		if ref.Attribute == nil {
-			return nil, p.error("term attribute used as placeable")
+		return nil, p.error("E0018")
+	case TermReference:
+		if v.Attribute == nil {
+			return nil, p.error("E0017")
 		}
+	case StringLiteral, NumberLiteral, VariableReference, FunctionReference:
+	default:
+		return nil, p.error("E0029")
 	}
 
 	p.next() // skip '-'
 	p.next() // skip '>'
 
 	p.skipBlankInline()
-	if !p.skipEOL() {
-		return nil, p.error("expected eol")
+	if err := p.expectLineEnd(); err != nil {
+		return nil, err
 	}
-	p.skipBlank()
 
 	variants, err := p.parseVariants()
 	if err != nil {
-		return nil, p.errorf("parse variants: %v", err)
+		return nil, err
 	}
 
 	selectExp := SelectExpression{
 		Selector: selector,
 		Variants: variants,
+		Span:     p.span(start),
 	}
 
 	return selectExp, nil
 }
 
-func (p *parser) parseLiteral() (InlineExpression, error) {
-	if isDigit(p.ch) {
-		return p.parseNumberLiteral()
-	}
-	if p.ch == '"' {
-		return p.parseStringLiteral()
-	}
-	return nil, p.error("expected literal") // E0014
-}
-
 func (p *parser) parseInlineExpression() (InlineExpression, error) {
+	start := p.pos
+
 	switch {
+	case p.ch == '{':
+		return p.parsePlaceable()
+	case p.isNumberStart():
+		return p.parseNumberLiteral()
 	case p.ch == '"':
 		return p.parseStringLiteral()
-	case isDigit(p.ch):
-		return p.parseNumberLiteral()
+	case p.ch == '$':
+		p.next()
+		id, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		ref := VariableReference{
+			ID:   id,
+			Span: p.span(start),
+		}
+		return ref, nil
 	case p.ch == '-':
-		ch := p.peek()
-		if isLetter(ch) {
-			p.next()
+		p.next()
+		id, err := p.parseIdentifier()
+		if err != nil {
+			return nil, err
+		}
 
+		var attr *Identifier
+		if p.ch == '.' {
+			p.next()
 			id, err := p.parseIdentifier()
 			if err != nil {
 				return nil, err
 			}
+			attr = &id
+		}
 
-			var attr *Identifier
-			if p.ch == '.' {
-				p.next()
-				id, err := p.parseIdentifier()
-				if err != nil {
-					return nil, err
-				}
-				attr = &id
-			}
-
-			var arguments *CallArguments
-			if p.ch == '(' {
-				args, err := p.parseCallArguments()
-				if err != nil {
-					return nil, err
-				}
-				arguments = &args
-			}
-
-			ref := TermReference{
-				ID:        id,
-				Attribute: attr,
-				Arguments: arguments,
+		var arguments *CallArguments
+		end := p.save()
+		p.skipBlank()
+		if p.ch == '(' {
+			args, err := p.parseCallArguments()
+			if err != nil {
+				return nil, err
 			}
-
-			return ref, nil
-		}
-		return p.parseNumberLiteral()
-	case p.ch == '$':
-		p.next()
-		id, err := p.parseIdentifier()
-		if err != nil {
-			return nil, err
+			arguments = &args
+		} else {
+			p.restore(end)
 		}
-		ref := VariableReference{
-			ID: id,
+
+		ref := TermReference{
+			ID:        id,
+			Attribute: attr,
+			Arguments: arguments,
+			Span:      p.span(start),
 		}
 		return ref, nil
-	case isLetter(p.ch): // identifier start
+	case isLetter(p.ch):
 		id, err := p.parseIdentifier()
 		if err != nil {
 			return nil, err
 		}
 
+		end := p.save()
+		p.skipBlank()
 		if p.ch == '(' { // it's a function
-			for _, ch := range id.Name {
-				if !(isUppercase(ch) || isDigit(ch) || ch == '_' || ch == '-') {
-					return nil, p.error("forbidden callee") // E0008
-				}
+			if !isCallee(id.Name) {
+				p.restore(end)
+				return nil, p.error("E0008")
 			}
 			arguments, err := p.parseCallArguments()
 			if err != nil {
@@ -691,9 +1003,11 @@ func (p *parser) parseInlineExpression() (InlineExpression, error) {
 			ref := FunctionReference{
 				ID:        id,
 				Arguments: arguments,
+				Span:      p.span(start),
 			}
 			return ref, nil
 		}
+		p.restore(end)
 
 		var attr *Identifier
 		if p.ch == '.' {
@@ -708,17 +1022,20 @@ func (p *parser) parseInlineExpression() (InlineExpression, error) {
 		ref := MessageReference{
 			ID:        id,
 			Attribute: attr,
+			Span:      p.span(start),
 		}
 		return ref, nil
-	case p.ch == '{':
-		return p.parsePlaceable()
 	default:
-		return nil, p.error("expected inline expression") // E0028
+		return nil, p.error("E0028")
 	}
 }
 
 func (p *parser) parseCallArguments() (CallArguments, error) {
-	p.next() // skip '('
+	start := p.pos
+
+	if err := p.expect('('); err != nil {
+		return CallArguments{}, err
+	}
 
 	positional := make([]InlineExpression, 0)
 	named := make([]NamedArgument, 0)
@@ -726,10 +1043,8 @@ func (p *parser) parseCallArguments() (CallArguments, error) {
 
 	p.skipBlank()
 
-	for p.ch != eof {
-		if p.ch == ')' {
-			break
-		}
+	for p.ch != ')' {
+		argStart := p.pos
 
 		exp, err := p.parseInlineExpression()
 		if err != nil {
@@ -741,7 +1056,7 @@ func (p *parser) parseCallArguments() (CallArguments, error) {
 		if p.ch == ':' { // named argument
 			ref, ok := exp.(MessageReference)
 			if !ok || ref.Attribute != nil {
-				return CallArguments{}, p.error("argument name must be simple identifier") // E0009
+				return CallArguments{}, p.error("E0009")
 			}
 
 			p.next() // skip ':'
@@ -753,284 +1068,276 @@ func (p *parser) parseCallArguments() (CallArguments, error) {
 			}
 
 			if containsString(argumentNames, ref.ID.Name) {
-				return CallArguments{}, p.error("named arguments must be unique")
+				return CallArguments{}, p.error("E0022")
 			}
 
 			arg := NamedArgument{
 				Name:  ref.ID,
 				Value: value,
+				Span:  p.span(argStart),
 			}
 
 			named = append(named, arg)
 			argumentNames = append(argumentNames, arg.Name.Name)
 		} else if len(argumentNames) > 0 {
-			return CallArguments{}, p.error("positional argument follows names") // E0021
+			return CallArguments{}, p.error("E0021")
 		} else {
 			positional = append(positional, exp)
 		}
 
 		p.skipBlank()
 
-		if p.ch == ',' {
-			p.next()
-			p.skipBlank()
-			continue
+		if p.ch != ',' {
+			break
 		}
-
-		break
+		p.next()
+		p.skipBlank()
 	}
 
-	if p.ch != ')' {
-		return CallArguments{}, p.error("expected ')'")
+	if err := p.expect(')'); err != nil {
+		return CallArguments{}, err
 	}
-	p.next()
 
 	args := CallArguments{
 		Positional: positional,
 		Named:      named,
+		Span:       p.span(start),
 	}
 
 	return args, nil
 }
 
-func (p *parser) parseStringLiteral() (StringLiteral, error) {
-	p.next() // skip '"'
+func (p *parser) parseLiteral() (InlineExpression, error) {
+	switch {
+	case p.isNumberStart():
+		return p.parseNumberLiteral()
+	case p.ch == '"':
+		return p.parseStringLiteral()
+	default:
+		return nil, p.error("E0014")
+	}
+}
 
+func (p *parser) parseStringLiteral() (StringLiteral, error) {
 	start := p.pos
-loop:
-	for p.ch != eof {
-		if p.isEOL() {
-			return StringLiteral{}, p.error("unexpected eol")
-		}
-		switch p.ch {
-		case '\\': // escape special characters
-			p.next()
-			switch p.ch {
-			case '\\', '"':
-				p.next()
-			case 'u', 'U':
-				if err := p.skipUnicodeEscapeSequence(); err != nil {
-					return StringLiteral{}, err
-				}
-			default:
-				return StringLiteral{}, p.error("invalid escape sequence")
-			}
-		case '"':
-			break loop
-		default:
+
+	if err := p.expect('"'); err != nil {
+		return StringLiteral{}, err
+	}
+
+	var value strings.Builder
+	for p.ch != eof && p.ch != '"' && p.ch != '\n' {
+		if p.ch != '\\' {
+			value.WriteRune(p.ch)
 			p.next()
+			continue
+		}
+
+		p.next() // skip '\'
+		seq, err := p.parseEscapeSequence()
+		if err != nil {
+			return StringLiteral{}, err
 		}
+		value.WriteString(seq)
 	}
 
-	value := string(p.input[start:p.pos])
-	lit := StringLiteral{
-		Value: value,
+	if p.ch == '\n' {
+		return StringLiteral{}, p.error("E0020")
 	}
 
-	p.next() // skip closing '"'
+	if err := p.expect('"'); err != nil {
+		return StringLiteral{}, err
+	}
+
+	lit := StringLiteral{
+		Value: value.String(),
+		Span:  p.span(start),
+	}
 
 	return lit, nil
 }
 
+// parseEscapeSequence parses an escape sequence following a '\'. The sequence
+// is returned as written, including the '\'.
+func (p *parser) parseEscapeSequence() (string, error) {
+	switch p.ch {
+	case '\\', '"':
+		ch := p.ch
+		p.next()
+		return `\` + string(ch), nil
+	case 'u':
+		return p.parseUnicodeEscapeSequence(4)
+	case 'U':
+		return p.parseUnicodeEscapeSequence(6)
+	default:
+		return "", p.error("E0025", runeString(p.ch))
+	}
+}
+
+func (p *parser) parseUnicodeEscapeSequence(digits int) (string, error) {
+	sequence := `\` + string(p.ch)
+	p.next() // skip 'u' or 'U'
+
+	for i := 0; i < digits; i++ {
+		if !isHex(p.ch) {
+			return "", p.error("E0026", sequence+runeString(p.ch))
+		}
+		sequence += string(p.ch)
+		p.next()
+	}
+
+	return sequence, nil
+}
+
 func (p *parser) parseNumberLiteral() (NumberLiteral, error) {
 	start := p.pos
+
 	if p.ch == '-' {
 		p.next()
 	}
 
-	if p.skipDigits() == 0 {
-		return NumberLiteral{}, p.error("expected digit")
+	if err := p.skipDigits(); err != nil {
+		return NumberLiteral{}, err
 	}
 
 	if p.ch == '.' {
 		p.next()
-		if p.skipDigits() == 0 {
-			return NumberLiteral{}, p.error("expected digit")
+		if err := p.skipDigits(); err != nil {
+			return NumberLiteral{}, err
 		}
 	}
 
-	value := string(p.input[start:p.pos])
 	lit := NumberLiteral{
-		Value: value,
+		Value: string(p.input[start:p.pos]),
+		Span:  p.span(start),
 	}
 	return lit, nil
 }
 
-func (p *parser) parseAttributes() ([]Attribute, error) {
-	attributes := make([]Attribute, 0)
+func (p *parser) skipDigits() error {
+	if !isDigit(p.ch) {
+		return p.error("E0004", "0-9")
+	}
+	for isDigit(p.ch) {
+		p.next()
+	}
+	return nil
+}
 
-	for p.ch != eof {
-		start := p.pos
+func (p *parser) isNumberStart() bool {
+	ch := p.ch
+	if ch == '-' {
+		ch = p.peek()
+	}
+	return isDigit(ch)
+}
 
-		p.skipBlankInline()
+func (p *parser) parseVariants() ([]Variant, error) {
+	variants := make([]Variant, 0)
 
-		if p.ch != '.' {
-			p.pos = start
-			break
-		}
+	hasDefault := false
 
-		attr, err := p.parseAttribute()
+	p.skipBlank()
+	for p.isVariantStart() {
+		variant, err := p.parseVariant(hasDefault)
 		if err != nil {
 			return nil, err
 		}
-		attributes = append(attributes, attr)
-	}
-
-	return attributes, nil
-}
-
-func (p *parser) parseAttribute() (Attribute, error) {
-	p.next() // skip '.'
-
-	id, err := p.parseIdentifier()
-	if err != nil {
-		return Attribute{}, err
-	}
-
-	p.skipBlankInline()
+		if variant.Default {
+			hasDefault = true
+		}
+		variants = append(variants, variant)
 
-	if p.ch != '=' {
-		return Attribute{}, p.error("unexpected character")
+		if err := p.expectLineEnd(); err != nil {
+			return nil, err
+		}
+		p.skipBlank()
 	}
-	p.next()
-
-	p.skipBlankInline()
 
-	pattern, err := p.parsePattern()
-	if err != nil {
-		return Attribute{}, err
+	if len(variants) == 0 {
+		return nil, p.error("E0011")
 	}
-
-	attr := Attribute{
-		ID:    id,
-		Value: pattern,
+	if !hasDefault {
+		return nil, p.error("E0010")
 	}
 
-	return attr, nil
+	return variants, nil
 }
 
-func (p *parser) parseTerm() (Term, error) {
-	if p.ch != '-' {
-		return Term{}, p.error("expected '-'")
-	}
-	p.next()
-
-	id, err := p.parseIdentifier()
-	if err != nil {
-		return Term{}, p.error("expected identifier")
-	}
-	p.skipBlankInline()
-
-	if p.ch != '=' {
-		return Term{}, p.error("expected '='")
-	}
-	p.next()
-
-	p.skipBlankInline()
-
-	value, err := p.parsePattern()
-	if err != nil {
-		return Term{}, err
-	}
-
-	p.skipBlankBlock()
-
-	attributes, err := p.parseAttributes()
-	if err != nil {
-		return Term{}, err
-	}
+func (p *parser) isVariantStart() bool {
+	start := p.save()
+	defer p.restore(start)
 
-	term := Term{
-		ID:         id,
-		Value:      value,
-		Attributes: attributes,
+	if p.ch == '*' {
+		p.next()
 	}
-
-	return term, nil
+	return p.ch == '[' && p.peek() != '['
 }
 
-func (p *parser) parseIdentifier() (Identifier, error) {
+func (p *parser) parseVariant(hasDefault bool) (Variant, error) {
 	start := p.pos
 
-	if !isLetter(p.ch) {
-		return Identifier{}, p.error("expected identifier")
-	}
-
-	for p.ch != eof {
-		p.next()
-		if !(isLetter(p.ch) || isDigit(p.ch) || p.ch == '_' || p.ch == '-') {
-			break
+	defaultVariant := false
+	if p.ch == '*' {
+		if hasDefault {
+			return Variant{}, p.error("E0015")
 		}
+		p.next()
+		defaultVariant = true
 	}
 
-	id := Identifier{
-		Name: string(p.input[start:p.pos]),
+	if err := p.expect('['); err != nil {
+		return Variant{}, err
 	}
 
-	return id, nil
-}
-
-func (p *parser) parseVariants() ([]Variant, error) {
-	variants := make([]Variant, 0)
+	p.skipBlank()
 
-	defaultVariant := false
-	for p.ch == '*' || p.ch == '[' {
-		if p.ch == '*' {
-			defaultVariant = true
-			p.next()
-		}
+	key, err := p.parseVariantKey()
+	if err != nil {
+		return Variant{}, err
+	}
 
-		key, err := p.parseVariantKey()
-		if err != nil {
-			return nil, p.errorf("parse variant key: %v", err)
-		}
+	p.skipBlank()
 
-		value, err := p.parsePattern()
-		if err != nil {
-			return nil, p.errorf("parse pattern: %v", err)
-		}
+	if err := p.expect(']'); err != nil {
+		return Variant{}, err
+	}
 
-		variant := Variant{
-			Key:     key,
-			Value:   value,
-			Default: defaultVariant,
-		}
-		variants = append(variants, variant)
-		p.skipBlank()
+	value, err := p.maybeParsePattern()
+	if err != nil {
+		return Variant{}, err
+	}
+	if value == nil {
+		return Variant{}, p.error("E0012")
 	}
 
-	if !defaultVariant {
-		return nil, p.error("missing default variant")
+	variant := Variant{
+		Key:     key,
+		Value:   *value,
+		Default: defaultVariant,
+		Span:    p.span(start),
 	}
 
-	return variants, nil
+	return variant, nil
 }
 
 func (p *parser) parseVariantKey() (VariantKey, error) {
-	if p.ch != '[' {
-		return nil, p.error("expected '['")
-	}
-	p.next()
-	p.skipBlank()
-
-	var key VariantKey
-	var err error
-	if isDigit(p.ch) {
-		key, err = p.parseNumberLiteral()
-	} else {
-		key, err = p.parseIdentifier()
-	}
-	if err != nil {
-		return nil, err
+	switch {
+	case p.ch == eof:
+		return nil, p.error("E0013")
+	case isDigit(p.ch) || p.ch == '-':
+		return p.parseNumberLiteral()
+	default:
+		return p.parseIdentifier()
 	}
+}
 
-	p.skipBlank()
-	if p.ch != ']' {
-		return nil, p.error("expected ']'")
+// runeString returns ch as a string, or an empty string at the end of input.
+func runeString(ch rune) string {
+	if ch == eof {
+		return ""
 	}
-	p.next()
-
-	return key, nil
+	return string(ch)
 }
 
 func isWhitespace(ch rune) bool {
@@ -1046,13 +1353,23 @@ func isLetter(ch rune) bool {
 }
 
 func isHex(ch rune) bool {
-	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+	return (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F') || (ch >= '0' && ch <= '9')
 }
 
 func isUppercase(ch rune) bool {
 	return ch >= 'A' && ch <= 'Z'
 }
 
+// isCallee reports whether name is a valid function name.
+func isCallee(name string) bool {
+	for i, ch := range name {
+		if !(isUppercase(ch) || (i > 0 && (isDigit(ch) || ch == '_' || ch == '-'))) {
+			return false
+		}
+	}
+	return true
+}
+
 func isTextChar(ch rune) bool {
 	return !isSpecialTextChar(ch) && ch != '\n'
 }
@@ -1061,6 +1378,12 @@ func isSpecialTextChar(ch rune) bool {
 	return ch == '{' || ch == '}'
 }
 
+// isSpecialLineStart reports whether ch cannot start a pattern continuation
+// line.
+func isSpecialLineStart(ch rune) bool {
+	return ch == '}' || ch == '.' || ch == '[' || ch == '*'
+}
+
 func containsString(a []string, x string) bool {
 	for _, n := range a {
 		if x == n {
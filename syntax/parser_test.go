@@ -2,8 +2,10 @@ package syntax
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -30,13 +32,69 @@ func TestParse(t *testing.T) {
 			actual, err := marshal(resource)
 			require.NoError(t, err)
 
+			require.JSONEq(t, string(expected), string(stripSpans(t, actual)))
+		})
+	}
+}
+
+// TestParseStructure compares the full AST, including spans and annotations.
+func TestParseStructure(t *testing.T) {
+	paths, err := filepath.Glob("testdata/structure/*.ftl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		name := filepath.Base(path[:len(path)-4]) // strip .ftl
+		t.Run(name, func(t *testing.T) {
+			expected, err := ioutil.ReadFile(path[:len(path)-4] + ".json")
+			require.NoError(t, err)
+
+			input, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+
+			resource, _ := Parse(input)
+
+			actual, err := marshal(resource)
+			require.NoError(t, err)
+
 			require.JSONEq(t, string(expected), string(actual))
 		})
 	}
 }
 
+// stripSpans removes all "span" fields and Junk annotations from the JSON
+// encoded AST. The reference fixtures include neither.
+func stripSpans(t *testing.T, data []byte) []byte {
+	var v interface{}
+	require.NoError(t, json.Unmarshal(data, &v))
+
+	var strip func(v interface{})
+	strip = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			delete(v, "span")
+			if _, ok := v["annotations"]; ok {
+				v["annotations"] = []interface{}{}
+			}
+			for _, x := range v {
+				strip(x)
+			}
+		case []interface{}:
+			for _, x := range v {
+				strip(x)
+			}
+		}
+	}
+	strip(v)
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
 func TestMarshalJSON(t *testing.T) {
-	comment := Comment{"Standalone Comment"}
+	comment := Comment{Content: "Standalone Comment"}
 
 	actual, err := json.Marshal(comment)
 	require.NoError(t, err)
@@ -45,3 +103,199 @@ func TestMarshalJSON(t *testing.T) {
 
 	require.JSONEq(t, string(actual), expected)
 }
+
+func TestParseEncoding(t *testing.T) {
+	message := func(name string, start, valueStart, end int, value string) Message {
+		return Message{
+			ID: Identifier{
+				Name: name,
+				Span: &Span{Start: start, End: start + len(name)},
+			},
+			Value: &Pattern{
+				Elements: []PatternElement{
+					TextElement{
+						Value: value,
+						Span:  &Span{Start: valueStart, End: end},
+					},
+				},
+				Span: &Span{Start: valueStart, End: end},
+			},
+			Attributes: []Attribute{},
+			Span:       &Span{Start: start, End: end},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		mode     Mode
+		expected []Entry
+		errors   []string
+	}{
+		{
+			name:  "bom",
+			input: "\uFEFFkey = value\n",
+			expected: []Entry{
+				message("key", 3, 9, 14, "value"),
+			},
+		},
+		{
+			name:  "invalid utf8",
+			input: "key = a\xffb\nother = value\n",
+			expected: []Entry{
+				Junk{
+					Content: "key = a\xffb\n",
+					Annotations: []Annotation{{
+						Code:      "E0030",
+						Arguments: []string{},
+						Message:   "Invalid UTF-8 encoding",
+						Span:      &Span{Start: 7, End: 7},
+					}},
+					Span: &Span{Start: 0, End: 10},
+				},
+				message("other", 10, 18, 23, "value"),
+			},
+			errors: []string{"1:8: Invalid UTF-8 encoding"},
+		},
+		{
+			name:  "replace invalid utf8",
+			input: "key = a\xff\xfeb\n",
+			mode:  ReplaceInvalidUTF8,
+			expected: []Entry{
+				message("key", 0, 6, 10, "a\uFFFD\uFFFDb"),
+			},
+		},
+		{
+			name:  "lone cr and nul",
+			input: "key = a\rb\x00c\n",
+			expected: []Entry{
+				message("key", 0, 6, 11, "a\rb\x00c"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, err := ParseMode([]byte(tt.input), tt.mode)
+			if tt.errors == nil {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, strings.Join(tt.errors, "; "))
+			}
+			require.Equal(t, tt.expected, resource.Body)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	input := "a = 1\nkey = { $x ->\n   *[other] foo\n    [é\n}\nb = {\n"
+	_, err := Parse([]byte(input))
+	require.Error(t, err)
+
+	var perrs *ParseErrors
+	require.True(t, errors.As(err, &perrs))
+	require.Equal(t, []*Error{
+		{
+			// The position of the variant key, not of the select expression.
+			Line:      4,
+			Column:    6,
+			Offset:    41,
+			Code:      "E0004",
+			Arguments: []string{"a-zA-Z"},
+			Message:   `Expected a character from range: "a-zA-Z"`,
+		},
+		{
+			Line:    7,
+			Column:  1,
+			Offset:  52,
+			Code:    "E0028",
+			Message: "Expected an inline expression",
+		},
+	}, perrs.Errors())
+
+	var perr *Error
+	require.True(t, errors.As(err, &perr))
+	require.Equal(t, perrs.Errors()[0], perr)
+}
+
+func TestParsePattern(t *testing.T) {
+	pattern, err := ParsePattern("Hello,\n  { $name }!")
+	require.NoError(t, err)
+	require.Equal(t, Pattern{
+		Elements: []PatternElement{
+			TextElement{Value: "Hello,\n  ", Span: &Span{Start: 0, End: 9}},
+			Placeable{
+				Expr: VariableReference{
+					ID:   Identifier{Name: "name", Span: &Span{Start: 12, End: 16}},
+					Span: &Span{Start: 11, End: 16},
+				},
+				Span: &Span{Start: 9, End: 18},
+			},
+			TextElement{Value: "!", Span: &Span{Start: 18, End: 19}},
+		},
+		Span: &Span{Start: 0, End: 19},
+	}, pattern)
+
+	_, err = ParsePattern("Hello\n  { $name !")
+	require.EqualError(t, err, `2:11: Expected token: "}"`)
+	require.Equal(t, 16, err.(*Error).Offset)
+
+	_, err = ParsePattern("Hello\n.title = Title")
+	require.EqualError(t, err, "1:1: Expected a single pattern")
+
+	_, err = ParsePattern("")
+	require.Error(t, err)
+}
+
+// TestFormatPattern checks that FormatPattern and ParsePattern round trip the
+// patterns of the fixtures.
+func TestFormatPattern(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.ftl")
+	require.NoError(t, err)
+
+	for _, path := range paths {
+		input, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		resource, _ := Parse(input)
+
+		var patterns []Pattern
+		for _, entry := range resource.Body {
+			switch entry := entry.(type) {
+			case Message:
+				if entry.Value != nil {
+					patterns = append(patterns, *entry.Value)
+				}
+				for _, attribute := range entry.Attributes {
+					patterns = append(patterns, attribute.Value)
+				}
+			case Term:
+				patterns = append(patterns, entry.Value)
+			}
+		}
+
+		for _, pattern := range patterns {
+			text := FormatPattern(pattern)
+			parsed, err := ParsePattern(text)
+			require.NoError(t, err, "%s: %q", path, text)
+			require.Equal(t, text, FormatPattern(parsed), path)
+			require.Equal(t, &Span{Start: 0, End: len(text)}, parsed.Span, "%s: %q", path, text)
+		}
+	}
+}
+
+func TestStringLiteralUnescape(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{`abc`, "abc"},
+		{`a\"b\\c`, `a"b\c`},
+		{`é\U01F602`, "é😂"},
+		{`\UFFFFFF`, "�"},
+		{`\uD800`, "�"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, StringLiteral{Value: tt.value}.Unescape(), tt.value)
+	}
+}
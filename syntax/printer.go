@@ -2,11 +2,47 @@ package syntax
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"strings"
 )
 
+// Fprint writes node in Fluent syntax to w. node is a Resource, an Entry, a
+// Pattern or an Expression.
+//
+// The output is normalized as by the reference serializer: entries are not
+// separated by blank lines, except before standalone comments, patterns are
+// indented by four spaces and multiline patterns start on a new line. Junk is
+// written as is. Braces in text, which cannot be written as text, are written
+// as string literals.
+func Fprint(w io.Writer, node interface{}) error {
+	p := newPrinter()
+	if err := p.print(node); err != nil {
+		return err
+	}
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
+
+// FormatPattern returns the pattern in Fluent syntax, as it would follow the
+// "=" of a message but without leading space and indentation, e.g. "Hello, {
+// $name }!". ParsePattern parses it back.
+func FormatPattern(pattern Pattern) string {
+	s := patternString(pattern)
+	if strings.HasPrefix(s, "\n    ") {
+		s = s[len("\n    "):]
+	} else {
+		s = s[len(" "):]
+	}
+	return strings.Replace(s, "\n    ", "\n", -1)
+}
+
 type printer struct {
 	buf *bytes.Buffer
+
+	// hasEntries is whether an entry has been printed, so that a standalone
+	// comment needs a blank line to not be attached to it.
+	hasEntries bool
 }
 
 func newPrinter() *printer {
@@ -15,6 +51,287 @@ func newPrinter() *printer {
 	}
 }
 
-func (p *printer) printf(w io.Writer, n interface{}) error {
+func (p *printer) print(node interface{}) error {
+	switch n := node.(type) {
+	case Resource:
+		for _, entry := range n.Body {
+			p.printEntry(entry)
+		}
+	case Entry:
+		p.printEntry(n)
+	case Pattern:
+		p.buf.WriteString(patternString(n))
+	case Expression:
+		p.buf.WriteString(expressionString(n))
+	default:
+		return fmt.Errorf("cannot print %T", node)
+	}
 	return nil
 }
+
+func (p *printer) printEntry(entry Entry) {
+	switch entry := entry.(type) {
+	case Message:
+		p.printComment(entry.Comment)
+		p.buf.WriteString(entry.ID.Name + " =")
+		if entry.Value != nil {
+			p.buf.WriteString(patternString(*entry.Value))
+		}
+		p.printAttributes(entry.Attributes)
+		p.buf.WriteByte('\n')
+	case Term:
+		p.printComment(entry.Comment)
+		p.buf.WriteString("-" + entry.ID.Name + " =")
+		p.buf.WriteString(patternString(entry.Value))
+		p.printAttributes(entry.Attributes)
+		p.buf.WriteByte('\n')
+	case Comment:
+		p.printStandaloneComment("#", entry.Content)
+	case GroupComment:
+		p.printStandaloneComment("##", entry.Content)
+	case ResourceComment:
+		p.printStandaloneComment("###", entry.Content)
+	case Junk:
+		p.buf.WriteString(entry.Content)
+	}
+	p.hasEntries = true
+}
+
+func (p *printer) printComment(comment *Comment) {
+	if comment != nil {
+		p.buf.WriteString(commentString("#", comment.Content))
+	}
+}
+
+func (p *printer) printStandaloneComment(prefix, content string) {
+	if p.hasEntries {
+		p.buf.WriteByte('\n')
+	}
+	p.buf.WriteString(commentString(prefix, content))
+	p.buf.WriteByte('\n')
+}
+
+func (p *printer) printAttributes(attributes []Attribute) {
+	for _, attribute := range attributes {
+		p.buf.WriteString("\n    ." + attribute.ID.Name + " =")
+		p.buf.WriteString(indentExceptFirstLine(patternString(attribute.Value)))
+	}
+}
+
+func commentString(prefix, content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = prefix
+		} else {
+			lines[i] = prefix + " " + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// patternString returns the pattern with a leading space or, if it is
+// multiline, a leading line break, and lines indented by four spaces. An
+// empty pattern is returned as { "" }.
+func patternString(pattern Pattern) string {
+	var b strings.Builder
+	block := startsOnNewLine(pattern)
+	last := len(pattern.Elements) - 1
+	for i, element := range pattern.Elements {
+		switch element := element.(type) {
+		case TextElement:
+			b.WriteString(textString(element.Value, block, i == 0, i == last))
+		case Placeable:
+			b.WriteString(placeableString(element))
+		}
+	}
+
+	if b.Len() == 0 {
+		// A value cannot be empty, so empty text is printed as a string
+		// literal.
+		return ` { "" }`
+	}
+	if block {
+		return "\n    " + indentExceptFirstLine(b.String())
+	}
+	return " " + indentExceptFirstLine(b.String())
+}
+
+// startsOnNewLine reports whether a pattern is multiline and can start on a
+// new line. Text starting with "[", "*" or "." cannot, because it would be
+// parsed as a variant or an attribute.
+func startsOnNewLine(pattern Pattern) bool {
+	multiline := false
+	for _, element := range pattern.Elements {
+		switch element := element.(type) {
+		case TextElement:
+			if strings.Contains(element.Value, "\n") {
+				multiline = true
+			}
+		case Placeable:
+			if _, ok := element.Expr.(SelectExpression); ok {
+				multiline = true
+			}
+		}
+	}
+	if !multiline {
+		return false
+	}
+
+	if len(pattern.Elements) > 0 {
+		if text, ok := pattern.Elements[0].(TextElement); ok && text.Value != "" {
+			switch text.Value[0] {
+			case '[', '*', '.':
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// textString returns text with the characters which the parser would not read
+// as text written as string literals: braces, whitespace at the start and end
+// of the pattern, and "[", "*" and "." at the start of a line. Spaces at the
+// start of a block pattern are kept as its indentation.
+func textString(text string, block, first, last bool) string {
+	var lead, trail string
+	if first {
+		cutset := " \n"
+		if block {
+			cutset = "\n"
+		}
+		n := len(text) - len(strings.TrimLeft(text, cutset))
+		lead, text = text[:n], text[n:]
+	}
+	if last {
+		n := len(strings.TrimRight(text, " \n"))
+		text, trail = text[:n], text[n:]
+	}
+
+	var b strings.Builder
+	if lead != "" {
+		b.WriteString(literalString(lead))
+	}
+	// The first line either follows the "=" or starts with another
+	// character, unless startsOnNewLine avoids it.
+	lineStart := false
+	for _, r := range text {
+		switch {
+		case r == '{' || r == '}':
+			b.WriteString(literalString(string(r)))
+		case lineStart && (r == '[' || r == '*' || r == '.'):
+			b.WriteString(literalString(string(r)))
+		default:
+			b.WriteRune(r)
+		}
+		if r == '\n' {
+			lineStart = true
+		} else if r != ' ' {
+			lineStart = false
+		}
+	}
+	if trail != "" {
+		b.WriteString(literalString(trail))
+	}
+	return b.String()
+}
+
+// literalString returns a placeable with a string literal of s.
+func literalString(s string) string {
+	var b strings.Builder
+	b.WriteString(`{ "`)
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteString(`" }`)
+	return b.String()
+}
+
+func placeableString(placeable Placeable) string {
+	switch expr := placeable.Expr.(type) {
+	case Placeable:
+		return "{" + placeableString(expr) + "}"
+	case SelectExpression:
+		// The select expression ends with a line break before the closing
+		// brace.
+		return "{ " + expressionString(expr) + "}"
+	default:
+		return "{ " + expressionString(expr) + " }"
+	}
+}
+
+func expressionString(expr Expression) string {
+	switch expr := expr.(type) {
+	case StringLiteral:
+		return `"` + expr.Value + `"`
+	case NumberLiteral:
+		return expr.Value
+	case VariableReference:
+		return "$" + expr.ID.Name
+	case MessageReference:
+		s := expr.ID.Name
+		if expr.Attribute != nil {
+			s += "." + expr.Attribute.Name
+		}
+		return s
+	case TermReference:
+		s := "-" + expr.ID.Name
+		if expr.Attribute != nil {
+			s += "." + expr.Attribute.Name
+		}
+		if expr.Arguments != nil {
+			s += callArgumentsString(*expr.Arguments)
+		}
+		return s
+	case FunctionReference:
+		return expr.ID.Name + callArgumentsString(expr.Arguments)
+	case Placeable:
+		return placeableString(expr)
+	case SelectExpression:
+		var b strings.Builder
+		b.WriteString(inlineExpressionString(expr.Selector) + " ->")
+		for _, variant := range expr.Variants {
+			if variant.Default {
+				b.WriteString("\n   *[")
+			} else {
+				b.WriteString("\n    [")
+			}
+			b.WriteString(variantKeyName(variant.Key) + "]")
+			b.WriteString(indentExceptFirstLine(patternString(variant.Value)))
+		}
+		b.WriteString("\n")
+		return b.String()
+	default:
+		return ""
+	}
+}
+
+func inlineExpressionString(expr InlineExpression) string {
+	if e, ok := expr.(Expression); ok {
+		return expressionString(e)
+	}
+	return ""
+}
+
+func callArgumentsString(arguments CallArguments) string {
+	args := make([]string, 0, len(arguments.Positional)+len(arguments.Named))
+	for _, argument := range arguments.Positional {
+		args = append(args, inlineExpressionString(argument))
+	}
+	for _, argument := range arguments.Named {
+		args = append(args, argument.Name.Name+": "+inlineExpressionString(argument.Value))
+	}
+	return "(" + strings.Join(args, ", ") + ")"
+}
+
+func indentExceptFirstLine(s string) string {
+	return strings.Replace(s, "\n", "\n    ", -1)
+}
@@ -0,0 +1,169 @@
+package syntax
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "message",
+			input:    "key   =   Value\n",
+			expected: "key = Value\n",
+		},
+		{
+			name:     "comments",
+			input:    "### Resource\n\n## Group\n\n# Attached\nkey = Value\n# Standalone\n\nother = x\n",
+			expected: "### Resource\n\n\n## Group\n\n# Attached\nkey = Value\n\n# Standalone\n\nother = x\n",
+		},
+		{
+			name:     "attributes",
+			input:    "key =\n  .title = Title\n  .label = Label\n",
+			expected: "key =\n    .title = Title\n    .label = Label\n",
+		},
+		{
+			name:     "multiline",
+			input:    "key =\n  Line 1\n    Line 2\n  .title =\n      A\n      B\n",
+			expected: "key =\n    Line 1\n      Line 2\n    .title =\n        A\n        B\n",
+		},
+		{
+			name:     "multiline starting with special character",
+			input:    "key = [a]\n  b\n",
+			expected: "key = [a]\n    b\n",
+		},
+		{
+			name:     "select expression",
+			input:    "key = { $n ->\n  [one] One { $x ->\n    *[a] A\n  }\n  *[other] Other\n}\n",
+			expected: "key =\n    { $n ->\n        [one]\n            One { $x ->\n               *[a] A\n            }\n       *[other] Other\n    }\n",
+		},
+		{
+			name:     "expressions",
+			input:    "key = { \"a\\\"b\" } { 1.5 } { msg.attr } { -term.attr(case: \"gen\") } { FUN($x, 1, a: 2) } {{ $y }}\n",
+			expected: "key = { \"a\\\"b\" } { 1.5 } { msg.attr } { -term.attr(case: \"gen\") } { FUN($x, 1, a: 2) } {{ $y }}\n",
+		},
+		{
+			name:     "junk",
+			input:    "key = Value\nbad\nother = x\n",
+			expected: "key = Value\nbad\nother = x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, _ := Parse([]byte(tt.input))
+
+			var buf bytes.Buffer
+			require.NoError(t, Fprint(&buf, resource))
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+// TestFprintText checks text which was not parsed, and so may contain
+// characters the parser would not read as text.
+func TestFprintText(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"a {b} c", "key = a { \"{\" }b{ \"}\" } c\n"},
+		{"  a  ", "key = { \"  \" }a{ \"  \" }\n"},
+		{"a\n.b\n  [c]\n*d", "key =\n    a\n    { \".\" }b\n      { \"[\" }c]\n    { \"*\" }d\n"},
+		{"\n\"a\"\n", "key =\n    { \"\\u000A\" }\"a\"{ \"\\u000A\" }\n"},
+		{"[a] *b .c", "key = [a] *b .c\n"},
+		{"", "key = { \"\" }\n"},
+	}
+
+	for _, tt := range tests {
+		pattern := Pattern{Elements: []PatternElement{TextElement{Value: tt.text}}}
+		resource := Resource{Body: []Entry{Message{ID: Identifier{Name: "key"}, Value: &pattern}}}
+
+		var buf bytes.Buffer
+		require.NoError(t, Fprint(&buf, resource))
+		require.Equal(t, tt.expected, buf.String())
+
+		_, err := Parse(buf.Bytes())
+		require.NoError(t, err)
+	}
+}
+
+func TestFprintEmptyPattern(t *testing.T) {
+	resource := Resource{Body: []Entry{
+		Message{
+			ID:         Identifier{Name: "key"},
+			Value:      &Pattern{},
+			Attributes: []Attribute{{ID: Identifier{Name: "title"}}},
+		},
+		Term{ID: Identifier{Name: "brand"}},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Fprint(&buf, resource))
+	require.Equal(t, "key = { \"\" }\n    .title = { \"\" }\n-brand = { \"\" }\n", buf.String())
+
+	_, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+}
+
+func TestTextPattern(t *testing.T) {
+	empty, text := TextPattern(""), TextPattern("Text")
+	resource := Resource{Body: []Entry{
+		Message{ID: Identifier{Name: "empty"}, Value: &empty},
+		Message{ID: Identifier{Name: "text"}, Value: &text},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, Fprint(&buf, resource))
+	require.Equal(t, "empty = { \"\" }\ntext = Text\n", buf.String())
+}
+
+// TestFprintRoundTrip checks that printed fixtures parse to the same AST,
+// apart from spans.
+func TestFprintRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.ftl")
+	require.NoError(t, err)
+
+	for _, path := range paths {
+		name := filepath.Base(path[:len(path)-4]) // strip .ftl
+		t.Run(name, func(t *testing.T) {
+			if name == "cr" {
+				// The comment ends with a CR, which becomes part of the line
+				// ending when printed.
+				t.Skip()
+			}
+
+			input, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+
+			resource, _ := Parse(input)
+			var buf bytes.Buffer
+			require.NoError(t, Fprint(&buf, resource))
+			printed, _ := Parse(buf.Bytes())
+
+			expected, err := marshal(withoutJunk(resource))
+			require.NoError(t, err)
+			actual, err := marshal(withoutJunk(printed))
+			require.NoError(t, err)
+			require.JSONEq(t, string(stripSpans(t, expected)), string(stripSpans(t, actual)), "printed:\n%s", buf.String())
+		})
+	}
+}
+
+func withoutJunk(resource Resource) Resource {
+	var body []Entry
+	for _, entry := range resource.Body {
+		if _, ok := entry.(Junk); !ok {
+			body = append(body, entry)
+		}
+	}
+	return Resource{Body: body}
+}
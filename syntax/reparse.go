@@ -0,0 +1,329 @@
+package syntax
+
+import "bytes"
+
+// Edit describes a change to the input of a Resource: the bytes in
+// [Start, End) of the old input were replaced with Text.
+type Edit struct {
+	Start int
+	End   int
+	Text  []byte
+}
+
+// Reparse parses input, the result of applying edit to the input of prev, and
+// returns the same as Parse(input). Only the entries of prev around the edit
+// are parsed again, the others are reused with their spans shifted, which makes
+// it suitable for reparsing on every keystroke in an editor.
+//
+// prev must have been returned by Parse or Reparse. If edit does not fit prev
+// or input, the whole input is parsed.
+func Reparse(prev Resource, input []byte, edit Edit) (Resource, error) {
+	return ReparseMode(prev, input, edit, 0)
+}
+
+// ReparseMode reparses input like Reparse, with optional functionality
+// controlled by mode. prev must have been parsed with the same mode.
+func ReparseMode(prev Resource, input []byte, edit Edit, mode Mode) (Resource, error) {
+	if !edit.fits(prev, input) {
+		return ParseMode(input, mode)
+	}
+
+	// Entries are independent of each other at the top level, with two
+	// exceptions: an edit at the start of an entry may turn it into part of
+	// the previous one, and a comment attaches to a directly following
+	// message or term. Parsing therefore starts one entry before the first
+	// one touching the edit, or two if that entry is a comment.
+	first := 0
+	for first < len(prev.Body) && entrySpan(prev.Body[first]).End < edit.Start {
+		first++
+	}
+	if first > 0 {
+		first--
+	}
+	if first > 0 {
+		if _, ok := prev.Body[first-1].(Comment); ok {
+			first--
+		}
+	}
+
+	p := newParser(input, mode)
+
+	var errors []*Error
+	entries := make([]Entry, 0, len(prev.Body))
+	for _, entry := range prev.Body[:first] {
+		if junk, ok := entry.(Junk); ok {
+			errors = append(errors, p.junkErrors(junk)...)
+		}
+		entries = append(entries, entry)
+	}
+
+	if first > 0 {
+		p.seek(entrySpan(prev.Body[first]).Start)
+	} else {
+		p.skipBlankBlock()
+	}
+
+	// Entries starting after the edit can be reused as soon as parsing gets
+	// back in step with them.
+	delta := len(edit.Text) - (edit.End - edit.Start)
+	shift := func(offset int) int { return offset + delta }
+	for _, entry := range prev.Body[first:] {
+		if entrySpan(entry).Start >= edit.End {
+			p.reuse = append(p.reuse, mapEntry(entry, shift))
+		}
+	}
+
+	return p.parseEntries(entries, errors)
+}
+
+// fits reports whether the edit turns the input of prev into input.
+func (e Edit) fits(prev Resource, input []byte) bool {
+	if prev.Span == nil || e.Start < 0 || e.Start > e.End || e.End > prev.Span.End {
+		return false
+	}
+	if prev.Span.End-(e.End-e.Start)+len(e.Text) != len(input) {
+		return false
+	}
+	if !bytes.Equal(input[e.Start:e.Start+len(e.Text)], e.Text) {
+		return false
+	}
+	for _, entry := range prev.Body {
+		if entrySpan(entry) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// junkErrors returns the parse errors for the annotations of Junk from a
+// previous parse. The parser is moved to the position of the last one.
+func (p *parser) junkErrors(junk Junk) []*Error {
+	errors := make([]*Error, 0, len(junk.Annotations))
+	for _, annotation := range junk.Annotations {
+		p.seek(annotation.Span.Start)
+		errors = append(errors, newParseError(p.line, p.col, p.pos, annotation.Code, annotation.Arguments...))
+	}
+	return errors
+}
+
+// entrySpan returns the span of an entry.
+func entrySpan(entry Entry) *Span {
+	switch v := entry.(type) {
+	case Message:
+		return v.Span
+	case Term:
+		return v.Span
+	case Comment:
+		return v.Span
+	case GroupComment:
+		return v.Span
+	case ResourceComment:
+		return v.Span
+	case Junk:
+		return v.Span
+	default:
+		return nil
+	}
+}
+
+// mapEntry returns a copy of entry with the offsets of all spans mapped by f.
+// Nodes are copied as needed so that entry itself is left untouched.
+func mapEntry(entry Entry, f func(int) int) Entry {
+	switch v := entry.(type) {
+	case Message:
+		v.ID = mapIdentifier(v.ID, f)
+		if v.Value != nil {
+			value := mapPattern(*v.Value, f)
+			v.Value = &value
+		}
+		v.Attributes = mapAttributes(v.Attributes, f)
+		if v.Comment != nil {
+			comment := *v.Comment
+			comment.Span = mapSpan(comment.Span, f)
+			v.Comment = &comment
+		}
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case Term:
+		v.ID = mapIdentifier(v.ID, f)
+		v.Value = mapPattern(v.Value, f)
+		v.Attributes = mapAttributes(v.Attributes, f)
+		if v.Comment != nil {
+			comment := *v.Comment
+			comment.Span = mapSpan(comment.Span, f)
+			v.Comment = &comment
+		}
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case Comment:
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case GroupComment:
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case ResourceComment:
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case Junk:
+		annotations := make([]Annotation, len(v.Annotations))
+		for i, annotation := range v.Annotations {
+			annotation.Span = mapSpan(annotation.Span, f)
+			annotations[i] = annotation
+		}
+		v.Annotations = annotations
+		v.Span = mapSpan(v.Span, f)
+		return v
+	default:
+		return entry
+	}
+}
+
+func mapSpan(span *Span, f func(int) int) *Span {
+	if span == nil {
+		return nil
+	}
+	return &Span{Start: f(span.Start), End: f(span.End)}
+}
+
+func mapIdentifier(id Identifier, f func(int) int) Identifier {
+	id.Span = mapSpan(id.Span, f)
+	return id
+}
+
+func mapAttributes(attributes []Attribute, f func(int) int) []Attribute {
+	if attributes == nil {
+		return nil
+	}
+	shifted := make([]Attribute, len(attributes))
+	for i, attribute := range attributes {
+		attribute.ID = mapIdentifier(attribute.ID, f)
+		attribute.Value = mapPattern(attribute.Value, f)
+		attribute.Span = mapSpan(attribute.Span, f)
+		shifted[i] = attribute
+	}
+	return shifted
+}
+
+func mapPattern(pattern Pattern, f func(int) int) Pattern {
+	if pattern.Elements != nil {
+		elements := make([]PatternElement, len(pattern.Elements))
+		for i, element := range pattern.Elements {
+			switch v := element.(type) {
+			case TextElement:
+				v.Span = mapSpan(v.Span, f)
+				elements[i] = v
+			case Placeable:
+				elements[i] = mapPlaceable(v, f)
+			default:
+				elements[i] = element
+			}
+		}
+		pattern.Elements = elements
+	}
+	pattern.Span = mapSpan(pattern.Span, f)
+	return pattern
+}
+
+func mapPlaceable(placeable Placeable, f func(int) int) Placeable {
+	placeable.Expr = mapExpression(placeable.Expr, f)
+	placeable.Span = mapSpan(placeable.Span, f)
+	return placeable
+}
+
+func mapExpression(expr Expression, f func(int) int) Expression {
+	switch v := expr.(type) {
+	case SelectExpression:
+		v.Selector = mapInlineExpression(v.Selector, f)
+		if v.Variants != nil {
+			variants := make([]Variant, len(v.Variants))
+			for i, variant := range v.Variants {
+				switch key := variant.Key.(type) {
+				case Identifier:
+					variant.Key = mapIdentifier(key, f)
+				case NumberLiteral:
+					key.Span = mapSpan(key.Span, f)
+					variant.Key = key
+				}
+				variant.Value = mapPattern(variant.Value, f)
+				variant.Span = mapSpan(variant.Span, f)
+				variants[i] = variant
+			}
+			v.Variants = variants
+		}
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case InlineExpression:
+		if shifted, ok := mapInlineExpression(v, f).(Expression); ok {
+			return shifted
+		}
+		return expr
+	default:
+		return expr
+	}
+}
+
+func mapInlineExpression(expr InlineExpression, f func(int) int) InlineExpression {
+	switch v := expr.(type) {
+	case StringLiteral:
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case NumberLiteral:
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case FunctionReference:
+		v.ID = mapIdentifier(v.ID, f)
+		v.Arguments = mapCallArguments(v.Arguments, f)
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case MessageReference:
+		v.ID = mapIdentifier(v.ID, f)
+		if v.Attribute != nil {
+			attribute := mapIdentifier(*v.Attribute, f)
+			v.Attribute = &attribute
+		}
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case TermReference:
+		v.ID = mapIdentifier(v.ID, f)
+		if v.Attribute != nil {
+			attribute := mapIdentifier(*v.Attribute, f)
+			v.Attribute = &attribute
+		}
+		if v.Arguments != nil {
+			arguments := mapCallArguments(*v.Arguments, f)
+			v.Arguments = &arguments
+		}
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case VariableReference:
+		v.ID = mapIdentifier(v.ID, f)
+		v.Span = mapSpan(v.Span, f)
+		return v
+	case Placeable:
+		return mapPlaceable(v, f)
+	default:
+		return expr
+	}
+}
+
+func mapCallArguments(arguments CallArguments, f func(int) int) CallArguments {
+	if arguments.Positional != nil {
+		positional := make([]InlineExpression, len(arguments.Positional))
+		for i, argument := range arguments.Positional {
+			positional[i] = mapInlineExpression(argument, f)
+		}
+		arguments.Positional = positional
+	}
+	if arguments.Named != nil {
+		named := make([]NamedArgument, len(arguments.Named))
+		for i, argument := range arguments.Named {
+			argument.Name = mapIdentifier(argument.Name, f)
+			argument.Value = mapInlineExpression(argument.Value, f)
+			argument.Span = mapSpan(argument.Span, f)
+			named[i] = argument
+		}
+		arguments.Named = named
+	}
+	arguments.Span = mapSpan(arguments.Span, f)
+	return arguments
+}
@@ -0,0 +1,81 @@
+package syntax
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReparse(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.ftl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	structure, err := filepath.Glob("testdata/structure/*.ftl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, structure...)
+
+	texts := []string{"", "x", " ", "\n", "\n\n", "#", "-", "=", "{", "}", "[", "*", ".", "key = value\n"}
+
+	for _, path := range paths {
+		name := filepath.Base(path[:len(path)-4]) // strip .ftl
+		t.Run(name, func(t *testing.T) {
+			input, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+
+			prev, _ := Parse(input)
+			before, err := marshal(prev)
+			require.NoError(t, err)
+
+			for start := 0; start <= len(input); start += 11 {
+				for i, text := range texts {
+					end := start + i%3 // replace up to two bytes
+					if end > len(input) {
+						end = len(input)
+					}
+					edit := Edit{Start: start, End: end, Text: []byte(text)}
+					assertReparse(t, prev, input, edit)
+				}
+			}
+
+			after, err := marshal(prev)
+			require.NoError(t, err)
+			require.Equal(t, string(before), string(after), "previous resource was modified")
+		})
+	}
+}
+
+func TestReparseInvalidEdit(t *testing.T) {
+	input := []byte("foo = Foo\nbar = Bar\n")
+	prev, err := Parse(input)
+	require.NoError(t, err)
+
+	// The edit does not match the new input, so it is parsed from scratch.
+	resource, err := Reparse(prev, []byte("baz = Baz\n"), Edit{Start: 0, End: 1, Text: []byte("x")})
+	require.NoError(t, err)
+
+	expected, err := Parse([]byte("baz = Baz\n"))
+	require.NoError(t, err)
+	require.Equal(t, expected, resource)
+}
+
+// assertReparse checks that reparsing after edit gives the same result as
+// parsing the edited input from scratch.
+func assertReparse(t *testing.T, prev Resource, input []byte, edit Edit) {
+	t.Helper()
+
+	edited := make([]byte, 0, len(input)+len(edit.Text))
+	edited = append(edited, input[:edit.Start]...)
+	edited = append(edited, edit.Text...)
+	edited = append(edited, input[edit.End:]...)
+
+	expected, expectedErr := Parse(edited)
+	actual, actualErr := Reparse(prev, edited, edit)
+
+	require.Equal(t, expected, actual, "edit %+v of %q", edit, input)
+	require.Equal(t, expectedErr, actualErr, "edit %+v of %q", edit, input)
+}
@@ -0,0 +1,4 @@
+key =
+    {"."}Value
+    .attr = Attribute
+  .broken
@@ -0,0 +1 @@
+key = { foo.23 }
@@ -0,0 +1,5 @@
+-brand-name = Aurora
+    .gender = feminine
+
+update-successful =
+    { -brand-name.gender }
@@ -0,0 +1,7 @@
+foo = Foo
+    .attr = Foo Attr
+
+bar =
+    { foo.attr ->
+       *[foo] Foo
+    }
@@ -0,0 +1,3 @@
+foo = Value
+    .attr =
+        Value 2
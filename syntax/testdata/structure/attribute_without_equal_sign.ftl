@@ -0,0 +1,2 @@
+key =
+    .attr Value
@@ -0,0 +1,8 @@
+
+
+foo = Foo
+
+
+
+bar = Bar
+
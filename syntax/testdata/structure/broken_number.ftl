@@ -0,0 +1,6 @@
+### Broken Numbers
+
+key1 = { -2.4.5 }
+key2 = { -2..4 }
+key3 = { - }
+key4 = { 1. }
@@ -0,0 +1,6 @@
+err01 = { FUN(1 2) }
+err02 = { FUN(, ) }
+err03 = { FUN(a: b) }
+err04 = { FUN(a: 1, a: 2) }
+err05 = { FUN(a: 1, 2) }
+err06 = { FUN(1
@@ -0,0 +1,6 @@
+# Standalone
+
+## Group
+### Resource
+# Attached
+key = Value
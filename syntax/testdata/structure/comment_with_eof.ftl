@@ -0,0 +1 @@
+# Comment
\ No newline at end of file
@@ -0,0 +1,4 @@
+key1 = Value 1
+key2 =
+    Value 2
+    Continued
@@ -0,0 +1,2 @@
+key = Value
+-
\ No newline at end of file
@@ -0,0 +1,4 @@
+foo =
+    Foo
+    { $bar }
+    Baz
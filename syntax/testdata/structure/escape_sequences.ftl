@@ -0,0 +1,3 @@
+key = {"\\ \" \u0041 \U01F602"}
+bad = {"\q"}
+short = {"\u00"}
@@ -0,0 +1,4 @@
+key = { FUN(1, "a", $x, -term, style: "long", min: -1.5) }
+key2 = { FUN(a: 1, a: 2) }
+key3 = { FUN(a: 1, $x) }
+key4 = { fun() }
@@ -0,0 +1,8 @@
+key1 = Value 1
+key2 =
+    Value 2
+key3 =
+ Value 3
+key4 = {
+  $foo
+}
@@ -0,0 +1,5 @@
+key = {
+}
+
+# Comment
+key2 = Value
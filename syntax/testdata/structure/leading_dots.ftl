@@ -0,0 +1,5 @@
+key1 = .Value
+key2 = ..Value
+key3 =
+    .Value
+key4 = { "." }Value
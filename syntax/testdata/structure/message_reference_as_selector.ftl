@@ -0,0 +1,6 @@
+foo = Foo
+
+bar =
+    { foo ->
+       *[foo] Foo
+    }
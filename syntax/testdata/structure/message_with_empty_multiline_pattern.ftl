@@ -0,0 +1,4 @@
+key1 =
+    
+
+key2 =
@@ -0,0 +1,3 @@
+key1 =
+key2 = 
+key3 =  
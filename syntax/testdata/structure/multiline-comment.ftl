@@ -0,0 +1,2 @@
+# This is a multiline
+# comment
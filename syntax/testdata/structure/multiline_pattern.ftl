@@ -0,0 +1,13 @@
+key01 = Value
+    Continued here.
+
+key02 = Value
+
+    Continued here.
+
+# ERROR "Continued" looks like a new message.
+# key03 parses fine with just "Value".
+key03 =
+    Value
+Continued here
+    and here.
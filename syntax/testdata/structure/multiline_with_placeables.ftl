@@ -0,0 +1,5 @@
+key =
+    First {$x}
+
+      Second { -term }
+    Third
@@ -0,0 +1,2 @@
+foo = Foo
+    .2 = Foo
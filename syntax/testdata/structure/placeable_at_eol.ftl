@@ -0,0 +1,10 @@
+key1 =
+    A multiline message
+    { "with a placeable" } at the end of line.
+
+key2 = A single line message { "with a placeable" }
+
+key3 =
+    A multiline message
+    with a placeable { "at the end" }
+    of a line
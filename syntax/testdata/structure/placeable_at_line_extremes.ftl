@@ -0,0 +1,9 @@
+key1 =
+    { "a placeable" } at the beginning.
+
+key2 =
+    at the end { "a placeable" }
+
+key3 =
+    { "a placeable" }
+    across { "lines" }
@@ -0,0 +1,6 @@
+key1 = {{ foo }}
+
+key2 = { { foo } }
+
+# Some Comment
+key3 = { { foo } }
@@ -0,0 +1,2 @@
+key = { $x
+key2 = Value
@@ -0,0 +1,2 @@
+### This is a resource wide comment
+### It's multiline
@@ -0,0 +1,4 @@
+key = Value
+    .attr1 = Value 1
+    .attr2 =
+        Value 2
@@ -0,0 +1,3 @@
+key = { $foo $bar ->
+   *[key] Value
+}
@@ -0,0 +1,3 @@
+key = { $foo -
+   *[a] Value
+}
@@ -0,0 +1,5 @@
+key = { $x ->
+    [one] One
+   *[other] Other { $x }
+    [-1] Negative
+}
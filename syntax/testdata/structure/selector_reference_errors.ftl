@@ -0,0 +1,7 @@
+key = { msg ->
+   *[one] One
+}
+key2 = { -term ->
+   *[one] One
+}
+key3 = { -term.attr }
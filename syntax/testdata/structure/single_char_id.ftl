@@ -0,0 +1 @@
+k = Value
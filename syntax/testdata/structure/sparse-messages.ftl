@@ -0,0 +1,12 @@
+key1 =
+    Value 1
+
+
+
+key2 =
+
+    .attr =
+        Attribute 2
+
+key3 =
+    Value 3
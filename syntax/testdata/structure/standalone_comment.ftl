@@ -0,0 +1,7 @@
+key = Value
+
+# Standalone Comment
+
+# Another standalone
+#
+#     with indent
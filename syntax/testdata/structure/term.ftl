@@ -0,0 +1,7 @@
+-brand = Firefox
+    .gender = masculine
+
+-brand-short = { $case ->
+   *[nominative] Fx
+    [genitive] Fxa
+}
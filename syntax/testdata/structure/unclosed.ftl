@@ -0,0 +1,2 @@
+key = { $foo
+bar = Value
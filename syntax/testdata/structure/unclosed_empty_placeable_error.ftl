@@ -0,0 +1,2 @@
+foo = {
+bar = Bar
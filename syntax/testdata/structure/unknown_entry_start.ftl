@@ -0,0 +1,2 @@
+= Value
+key = Value
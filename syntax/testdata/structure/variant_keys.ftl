@@ -0,0 +1,15 @@
+key01 = { $sel ->
+   *[key] Value
+}
+
+key02 = { $sel ->
+   *[    key    ] Value
+}
+
+key03 = { $sel ->
+   *[-3.14] Value
+}
+
+key04 = { $sel ->
+   *[ 1 ] Value
+}
@@ -0,0 +1,4 @@
+key = { $sel ->
+   *[one]
+        Value
+}
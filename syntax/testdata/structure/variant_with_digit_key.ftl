@@ -0,0 +1,4 @@
+key = { $sel ->
+    [0] Zero
+   *[other] Other
+}
@@ -0,0 +1,3 @@
+key = { $sel ->
+   *[     one] Value
+}
@@ -0,0 +1,3 @@
+key = { $sel ->
+   *[New York] Nowy Jork
+}
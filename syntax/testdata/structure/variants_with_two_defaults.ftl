@@ -0,0 +1,4 @@
+key = { $x ->
+   *[one] One
+   *[other] Other
+}
@@ -0,0 +1,4 @@
+key = Value   
+key2 =
+    Value
+    
@@ -0,0 +1,173 @@
+package syntax
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Validate checks resources which are used together, e.g. the files of one
+// locale, for problems which are syntactically valid. The codes of the
+// diagnostics are:
+//
+//   - V0001: a message is defined more than once,
+//   - V0002: a term is defined more than once,
+//   - V0003: an attribute is defined more than once on a message or term,
+//   - V0004: a variant key is used more than once in a select expression,
+//   - V0005: a reference to an undefined message,
+//   - V0006: a reference to an undefined term,
+//   - V0007: a reference to an undefined attribute,
+//   - V0008: a term reference with positional arguments, which are ignored.
+//
+// The diagnostics for resources[i] are returned at index i, so that their
+// spans refer to the input of the right resource.
+func Validate(resources ...Resource) [][]Diagnostic {
+	v := validator{
+		messages:    make(map[string]definition),
+		terms:       make(map[string]definition),
+		diagnostics: make([][]Diagnostic, len(resources)),
+	}
+
+	// Collect the definitions first, so that references can point forward and
+	// across resources.
+	for i, resource := range resources {
+		v.resource = i
+		for _, entry := range resource.Body {
+			switch entry := entry.(type) {
+			case Message:
+				v.define(v.messages, entry.ID, entry.ID.Name, entry.Attributes, "V0001", "Message")
+			case Term:
+				v.define(v.terms, entry.ID, "-"+entry.ID.Name, entry.Attributes, "V0002", "Term")
+			}
+		}
+	}
+
+	for i, resource := range resources {
+		v.resource = i
+		for _, entry := range resource.Body {
+			switch entry := entry.(type) {
+			case Message:
+				if entry.Value != nil {
+					v.checkPattern(*entry.Value)
+				}
+				v.checkAttributes(entry.Attributes)
+			case Term:
+				v.checkPattern(entry.Value)
+				v.checkAttributes(entry.Attributes)
+			}
+		}
+	}
+
+	return v.diagnostics
+}
+
+// definition is a message or term and the names of its attributes.
+type definition struct {
+	attributes map[string]bool
+}
+
+type validator struct {
+	messages    map[string]definition
+	terms       map[string]definition
+	resource    int
+	diagnostics [][]Diagnostic
+}
+
+func (v *validator) report(code string, span *Span, format string, args ...interface{}) {
+	d := Diagnostic{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if span != nil {
+		d.Span = *span
+	}
+	v.diagnostics[v.resource] = append(v.diagnostics[v.resource], d)
+}
+
+// define adds the definition of a message or term. name is the identifier as
+// written in references, e.g. "-brand" for a term.
+func (v *validator) define(definitions map[string]definition, id Identifier, name string, attributes []Attribute, code, kind string) {
+	if _, ok := definitions[id.Name]; ok {
+		v.report(code, id.Span, "%s \"%s\" is already defined", kind, name)
+		return
+	}
+
+	def := definition{attributes: make(map[string]bool)}
+	for _, attribute := range attributes {
+		if def.attributes[attribute.ID.Name] {
+			v.report("V0003", attribute.ID.Span, "Attribute \"%s\" is already defined on \"%s\"", attribute.ID.Name, name)
+			continue
+		}
+		def.attributes[attribute.ID.Name] = true
+	}
+	definitions[id.Name] = def
+}
+
+func (v *validator) checkAttributes(attributes []Attribute) {
+	for _, attribute := range attributes {
+		v.checkPattern(attribute.Value)
+	}
+}
+
+func (v *validator) checkPattern(pattern Pattern) {
+	InspectPattern(pattern, func(expr Expression) {
+		switch expr := expr.(type) {
+		case MessageReference:
+			v.checkReference(v.messages, expr.ID.Name, expr.Attribute, expr.Span, "V0005", "message")
+		case TermReference:
+			v.checkReference(v.terms, "-"+expr.ID.Name, expr.Attribute, expr.Span, "V0006", "term")
+			if expr.Arguments != nil && len(expr.Arguments.Positional) > 0 {
+				v.report("V0008", expr.Arguments.Span, "Positional arguments to term \"-%s\" are ignored", expr.ID.Name)
+			}
+		case SelectExpression:
+			v.checkVariants(expr.Variants)
+		}
+	})
+}
+
+func (v *validator) checkReference(definitions map[string]definition, name string, attribute *Identifier, span *Span, code, kind string) {
+	def, ok := definitions[strings.TrimPrefix(name, "-")]
+	if !ok {
+		v.report(code, span, "Unknown %s \"%s\"", kind, name)
+		return
+	}
+	if attribute != nil && !def.attributes[attribute.Name] {
+		v.report("V0007", attribute.Span, "Unknown attribute \"%s.%s\"", name, attribute.Name)
+	}
+}
+
+func (v *validator) checkVariants(variants []Variant) {
+	seen := make(map[string]bool)
+	for _, variant := range variants {
+		var key string
+		var span *Span
+		switch k := variant.Key.(type) {
+		case Identifier:
+			key, span = k.Name, k.Span
+		case NumberLiteral:
+			// Numbers are matched by value, so 1 and 1.0 are the same key.
+			key, span = k.Value, k.Span
+			if f, err := strconv.ParseFloat(k.Value, 64); err == nil {
+				key = strconv.FormatFloat(f, 'g', -1, 64)
+			}
+		default:
+			continue
+		}
+		if seen[key] {
+			v.report("V0004", span, "Variant key \"%s\" is already used", variantKeyName(variant.Key))
+			continue
+		}
+		seen[key] = true
+	}
+}
+
+func variantKeyName(key VariantKey) string {
+	switch k := key.(type) {
+	case Identifier:
+		return k.Name
+	case NumberLiteral:
+		return k.Value
+	default:
+		return ""
+	}
+}
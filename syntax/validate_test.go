@@ -0,0 +1,91 @@
+package syntax
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		inputs   []string
+		expected [][]Diagnostic
+	}{
+		{
+			name: "valid",
+			inputs: []string{
+				"-brand = Firefox\n    .gender = masculine\nhello = Hello { -brand }\n",
+				"about = About { hello } { -brand.gender ->\n    [masculine] him\n   *[other] it\n}\n",
+			},
+			expected: [][]Diagnostic{nil, nil},
+		},
+		{
+			name: "duplicate definitions",
+			inputs: []string{
+				"hello = Hello\n-brand = Firefox\n",
+				"hello = Hi\n-brand = Fx\nbrand = Message\n",
+			},
+			expected: [][]Diagnostic{
+				nil,
+				{
+					{Code: "V0001", Message: `Message "hello" is already defined`, Span: Span{Start: 0, End: 5}},
+					{Code: "V0002", Message: `Term "-brand" is already defined`, Span: Span{Start: 12, End: 17}},
+				},
+			},
+		},
+		{
+			name: "duplicate attributes",
+			inputs: []string{
+				"login =\n    .title = Log in\n    .title = Sign in\n",
+			},
+			expected: [][]Diagnostic{{
+				{Code: "V0003", Message: `Attribute "title" is already defined on "login"`, Span: Span{Start: 33, End: 38}},
+			}},
+		},
+		{
+			name: "duplicate variant keys",
+			inputs: []string{
+				"emails = { $n ->\n    [1] one\n    [1.0] one\n    [one] one\n   *[one] other\n}\n",
+			},
+			expected: [][]Diagnostic{{
+				{Code: "V0004", Message: `Variant key "1.0" is already used`, Span: Span{Start: 34, End: 37}},
+				{Code: "V0004", Message: `Variant key "one" is already used`, Span: Span{Start: 62, End: 65}},
+			}},
+		},
+		{
+			name: "undefined references",
+			inputs: []string{
+				"hello = { missing } { -missing }\nbye = { hello.title } { NUMBER(-brand.x) }\n-brand = Fx\n",
+			},
+			expected: [][]Diagnostic{{
+				{Code: "V0005", Message: `Unknown message "missing"`, Span: Span{Start: 10, End: 17}},
+				{Code: "V0006", Message: `Unknown term "-missing"`, Span: Span{Start: 22, End: 30}},
+				{Code: "V0007", Message: `Unknown attribute "hello.title"`, Span: Span{Start: 47, End: 52}},
+				{Code: "V0007", Message: `Unknown attribute "-brand.x"`, Span: Span{Start: 71, End: 72}},
+			}},
+		},
+		{
+			name: "term positional arguments",
+			inputs: []string{
+				"-brand = Fx\nhello = { -brand(1, case: \"nom\") }\n",
+			},
+			expected: [][]Diagnostic{{
+				{Code: "V0008", Message: `Positional arguments to term "-brand" are ignored`, Span: Span{Start: 28, End: 44}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resources := make([]Resource, len(tt.inputs))
+			for i, input := range tt.inputs {
+				resource, err := Parse([]byte(input))
+				require.NoError(t, err)
+				resources[i] = resource
+			}
+
+			require.Equal(t, tt.expected, Validate(resources...))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package tmx
+
+import (
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/segment"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// A Translation is a Fluent resource in a language.
+type Translation struct {
+	// Language is the BCP 47 language tag of the resource, e.g. "de".
+	Language string
+
+	// Resource is nil if the language does not have the file.
+	Resource *syntax.Resource
+}
+
+// Export aligns the messages and terms of a reference resource with those of
+// translations of the same file by their ID, and returns the translation units
+// of their patterns. The IDs of the units are path, the message ID, e.g.
+// "hello" or "-brand", or the ID and the attribute name, e.g. "hello.title",
+// joined by ":". The comment of the message becomes a note.
+//
+// The pattern is a segment, unless it has select expressions, in which case
+// the text around them and the value of each variant are segments, which are
+// aligned with the segments of the variants of the translations with the same
+// key, and the IDs of their units end in "/" and the position of the segment
+// in the pattern, e.g. "main.ftl:emails/0:one:0". Placeables become
+// placeholders, which are numbered so that those with the same code in the
+// source and a translation have the same number.
+//
+// Segments without text are left out, as are units without a translation.
+func Export(path string, reference Translation, translations []Translation) []Unit {
+	translated := make([]map[string]syntax.Pattern, len(translations))
+	for i, translation := range translations {
+		translated[i] = segment.ByID(translation.Resource)
+	}
+
+	var units []Unit
+	if reference.Resource == nil {
+		return units
+	}
+	for _, entry := range reference.Resource.Body {
+		for _, p := range segment.Patterns(entry) {
+			// targets maps the IDs of segments to the segments in each
+			// translation.
+			targets := make([]map[string][]syntax.PatternElement, len(translations))
+			for i := range translations {
+				if pattern, ok := translated[i][p.ID]; ok {
+					targets[i] = segment.Runs(pattern)
+				}
+			}
+
+			hasSelect := false
+			for _, element := range p.Pattern.Elements {
+				if placeable, ok := element.(syntax.Placeable); ok {
+					if _, ok := placeable.Expr.(syntax.SelectExpression); ok {
+						hasSelect = true
+					}
+				}
+			}
+
+			segment.MapRuns(p.Pattern, "", func(id string, elements []syntax.PatternElement) []syntax.PatternElement {
+				if !hasText(elements) {
+					return elements
+				}
+				unit := Unit{ID: path + ":" + p.ID}
+				if hasSelect {
+					unit.ID += "/" + id
+				}
+				if p.Comment != nil {
+					unit.Notes = []string{p.Comment.Content}
+				}
+
+				source := inlines(elements, nil)
+				unit.Variants = []Variant{{Language: reference.Language, Segment: source}}
+				for i, translation := range translations {
+					if target, ok := targets[i][id]; ok && hasText(target) {
+						unit.Variants = append(unit.Variants, Variant{
+							Language: translation.Language,
+							Segment:  inlines(target, source),
+						})
+					}
+				}
+				if len(unit.Variants) > 1 {
+					units = append(units, unit)
+				}
+				return elements
+			})
+		}
+	}
+	return units
+}
+
+// hasText reports whether elements have text other than whitespace, which is
+// worth storing in a translation memory.
+func hasText(elements []syntax.PatternElement) bool {
+	for _, element := range elements {
+		if text, ok := element.(syntax.TextElement); ok && strings.TrimSpace(text.Value) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// inlines converts elements to inlines. Placeables of a target get the number
+// of an unused placeholder of the source with the same code, or a number
+// after those of the source.
+func inlines(elements []syntax.PatternElement, source []Inline) []Inline {
+	result := make([]Inline, 0, len(elements))
+	used := make(map[int]bool)
+	next := 1
+	for _, inline := range source {
+		if inline.Placeholder != nil && inline.Placeholder.X >= next {
+			next = inline.Placeholder.X + 1
+		}
+	}
+
+	for _, element := range elements {
+		switch element := element.(type) {
+		case syntax.TextElement:
+			result = append(result, Inline{Text: element.Value})
+		case syntax.Placeable:
+			code := syntax.FormatPattern(syntax.Pattern{Elements: []syntax.PatternElement{element}})
+			x := 0
+			for _, inline := range source {
+				if ph := inline.Placeholder; ph != nil && !used[ph.X] && ph.Code == code {
+					x = ph.X
+					break
+				}
+			}
+			if x == 0 {
+				x = next
+				next++
+			}
+			used[x] = true
+			result = append(result, Inline{Placeholder: &Placeholder{X: x, Code: code}})
+		}
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+package tmx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestExport(t *testing.T) {
+	reference, err := syntax.Parse([]byte(`# Greets the user.
+hello = Hello, { $name }!
+    .title = Greeting
+-brand = Firefox
+brand-only = { -brand }
+emails =
+    You have { $count ->
+        [one] one email
+       *[other] { $count } emails
+    }.
+untranslated = Untranslated
+`))
+	require.NoError(t, err)
+	de, err := syntax.Parse([]byte(`hello = { $name }, hallo { $name }!
+-brand = Firefox
+brand-only = { -brand }
+emails =
+    Sie haben { $count ->
+        [one] eine E-Mail
+       *[other] { $count } E-Mails
+    }.
+`))
+	require.NoError(t, err)
+	pl, err := syntax.Parse([]byte(`hello = Cześć, { $name }!
+    .title = Powitanie
+`))
+	require.NoError(t, err)
+
+	units := Export("main.ftl", Translation{Language: "en-US", Resource: &reference}, []Translation{
+		{Language: "de", Resource: &de},
+		{Language: "pl", Resource: &pl},
+		{Language: "fr"},
+	})
+
+	name := func(x int) Inline { return Inline{Placeholder: &Placeholder{X: x, Code: "{ $name }"}} }
+	count := Inline{Placeholder: &Placeholder{X: 1, Code: "{ $count }"}}
+	require.Equal(t, []Unit{
+		{
+			ID:    "main.ftl:hello",
+			Notes: []string{"Greets the user."},
+			Variants: []Variant{
+				{Language: "en-US", Segment: []Inline{{Text: "Hello, "}, name(1), {Text: "!"}}},
+				{Language: "de", Segment: []Inline{name(1), {Text: ", hallo "}, name(2), {Text: "!"}}},
+				{Language: "pl", Segment: []Inline{{Text: "Cześć, "}, name(1), {Text: "!"}}},
+			},
+		},
+		{
+			ID:    "main.ftl:hello.title",
+			Notes: []string{"Greets the user."},
+			Variants: []Variant{
+				{Language: "en-US", Segment: []Inline{{Text: "Greeting"}}},
+				{Language: "pl", Segment: []Inline{{Text: "Powitanie"}}},
+			},
+		},
+		{
+			ID: "main.ftl:-brand",
+			Variants: []Variant{
+				{Language: "en-US", Segment: []Inline{{Text: "Firefox"}}},
+				{Language: "de", Segment: []Inline{{Text: "Firefox"}}},
+			},
+		},
+		{
+			ID: "main.ftl:emails/0",
+			Variants: []Variant{
+				{Language: "en-US", Segment: []Inline{{Text: "You have "}}},
+				{Language: "de", Segment: []Inline{{Text: "Sie haben "}}},
+			},
+		},
+		{
+			ID: "main.ftl:emails/1:one:0",
+			Variants: []Variant{
+				{Language: "en-US", Segment: []Inline{{Text: "one email"}}},
+				{Language: "de", Segment: []Inline{{Text: "eine E-Mail"}}},
+			},
+		},
+		{
+			ID: "main.ftl:emails/1:other:0",
+			Variants: []Variant{
+				{Language: "en-US", Segment: []Inline{count, {Text: " emails"}}},
+				{Language: "de", Segment: []Inline{count, {Text: " E-Mails"}}},
+			},
+		},
+		{
+			ID: "main.ftl:emails/2",
+			Variants: []Variant{
+				{Language: "en-US", Segment: []Inline{{Text: "."}}},
+				{Language: "de", Segment: []Inline{{Text: "."}}},
+			},
+		},
+	}, units)
+}
@@ -0,0 +1,121 @@
+// Package tmx converts Fluent resources to TMX 1.4 translation memories, to
+// reuse existing translations in CAT tools.
+package tmx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/segment"
+)
+
+// A Document is a TMX 1.4 translation memory.
+type Document struct {
+	// CreationTool and CreationToolVersion identify the tool which wrote the
+	// document, e.g. "fluent" and "1.0.0".
+	CreationTool        string
+	CreationToolVersion string
+
+	// SourceLanguage is the BCP 47 language tag of the source of the units,
+	// e.g. "en-US".
+	SourceLanguage string
+
+	Units []Unit
+}
+
+// A Unit is a translation unit, a segment in the source language and its
+// translations.
+type Unit struct {
+	ID    string
+	Notes []string
+
+	// Variants hold the segment in each language, the source first.
+	Variants []Variant
+}
+
+// A Variant is the segment of a unit in a language.
+type Variant struct {
+	// Language is a BCP 47 language tag, e.g. "de".
+	Language string
+
+	Segment []Inline
+}
+
+// An Inline is text or a placeholder in a segment.
+type Inline struct {
+	Text string
+
+	// Placeholder is set for a <ph> element, in which case Text is empty.
+	Placeholder *Placeholder
+}
+
+// A Placeholder is a <ph> element, a code which is not translated.
+type Placeholder struct {
+	// X matches the placeholder with those of the other variants of the unit
+	// which have the same X.
+	X int
+
+	// Code is the native code of the placeholder, e.g. "{ $name }".
+	Code string
+}
+
+// Write writes the document as TMX 1.4. Segments are plain text, with
+// placeables of Fluent patterns as the native codes of placeholders. Nothing
+// is written if the document has characters which XML does not allow, e.g.
+// U+0001.
+func Write(w io.Writer, doc *Document) error {
+	var (
+		buf bytes.Buffer
+		esc segment.XMLEscaper
+	)
+
+	buf.WriteString(xml.Header)
+	buf.WriteString("<tmx version=\"1.4\">\n")
+	fmt.Fprintf(&buf, "  <header creationtool=\"%s\" creationtoolversion=\"%s\" segtype=\"block\" o-tmf=\"Fluent\" adminlang=\"en\" srclang=\"%s\" datatype=\"plaintext\"/>\n",
+		esc.Attr(doc.CreationTool), esc.Attr(doc.CreationToolVersion), esc.Attr(doc.SourceLanguage))
+	buf.WriteString("  <body>\n")
+	for _, unit := range doc.Units {
+		writeUnit(&buf, &esc, unit)
+		if err := esc.Err(); err != nil {
+			return fmt.Errorf("unit %s: %v", unit.ID, err)
+		}
+	}
+	buf.WriteString("  </body>\n")
+	buf.WriteString("</tmx>\n")
+	if err := esc.Err(); err != nil {
+		return err
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeUnit(buf *bytes.Buffer, esc *segment.XMLEscaper, unit Unit) {
+	fmt.Fprintf(buf, "    <tu tuid=\"%s\">\n", esc.Attr(unit.ID))
+	for _, note := range unit.Notes {
+		fmt.Fprintf(buf, "      <note>%s</note>\n", esc.Text(note))
+	}
+	for _, variant := range unit.Variants {
+		fmt.Fprintf(buf, "      <tuv xml:lang=\"%s\">\n", esc.Attr(variant.Language))
+		fmt.Fprintf(buf, "        <seg>%s</seg>\n", inlinesString(esc, variant.Segment))
+		buf.WriteString("      </tuv>\n")
+	}
+	buf.WriteString("    </tu>\n")
+}
+
+// inlinesString returns the inlines as XML.
+func inlinesString(esc *segment.XMLEscaper, inlines []Inline) string {
+	var b strings.Builder
+	for _, inline := range inlines {
+		ph := inline.Placeholder
+		if ph == nil {
+			b.WriteString(esc.Text(inline.Text))
+			continue
+		}
+		fmt.Fprintf(&b, `<ph x="%d">%s</ph>`, ph.X, esc.Text(ph.Code))
+	}
+	return b.String()
+}
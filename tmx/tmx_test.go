@@ -0,0 +1,87 @@
+package tmx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestWrite(t *testing.T) {
+	doc := &Document{
+		CreationTool:        "fluent",
+		CreationToolVersion: "1.0.0",
+		SourceLanguage:      "en-US",
+		Units: []Unit{
+			{
+				ID:    "main.ftl:hello",
+				Notes: []string{"Greets <the> user & friends."},
+				Variants: []Variant{
+					{Language: "en-US", Segment: []Inline{
+						{Text: "Hello, "},
+						{Placeholder: &Placeholder{X: 1, Code: "{ $name }"}},
+						{Text: "!"},
+					}},
+					{Language: "de", Segment: []Inline{
+						{Text: "Hallo, "},
+						{Placeholder: &Placeholder{X: 1, Code: "{ $name }"}},
+						{Text: "!"},
+					}},
+				},
+			},
+			{
+				ID: "main.ftl:tags",
+				Variants: []Variant{
+					{Language: "en-US", Segment: []Inline{{Text: "<b>Bold</b>"}}},
+					{Language: "de", Segment: []Inline{{Text: "<b>Fett</b>"}}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, doc))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="fluent" creationtoolversion="1.0.0" segtype="block" o-tmf="Fluent" adminlang="en" srclang="en-US" datatype="plaintext"/>
+  <body>
+    <tu tuid="main.ftl:hello">
+      <note>Greets &lt;the&gt; user &amp; friends.</note>
+      <tuv xml:lang="en-US">
+        <seg>Hello, <ph x="1">{ $name }</ph>!</seg>
+      </tuv>
+      <tuv xml:lang="de">
+        <seg>Hallo, <ph x="1">{ $name }</ph>!</seg>
+      </tuv>
+    </tu>
+    <tu tuid="main.ftl:tags">
+      <tuv xml:lang="en-US">
+        <seg>&lt;b&gt;Bold&lt;/b&gt;</seg>
+      </tuv>
+      <tuv xml:lang="de">
+        <seg>&lt;b&gt;Fett&lt;/b&gt;</seg>
+      </tuv>
+    </tu>
+  </body>
+</tmx>
+`, buf.String())
+}
+
+func TestWriteInvalidCharacter(t *testing.T) {
+	reference, err := syntax.Parse([]byte("hello = Hello\x01!\n"))
+	require.NoError(t, err)
+	de, err := syntax.Parse([]byte("hello = Hallo!\n"))
+	require.NoError(t, err)
+	doc := &Document{
+		SourceLanguage: "en-US",
+		Units: Export("main.ftl", Translation{Language: "en-US", Resource: &reference}, []Translation{
+			{Language: "de", Resource: &de},
+		}),
+	}
+
+	var buf bytes.Buffer
+	require.EqualError(t, Write(&buf, doc), "unit main.ftl:hello: character U+0001 is not allowed in XML")
+	require.Empty(t, buf.String())
+}
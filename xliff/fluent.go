@@ -0,0 +1,248 @@
+package xliff
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/segment"
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+// Export converts a Fluent resource to a file of an XLIFF document, with path
+// as the original, and Import converts the translated file back.
+//
+// Each value and attribute of the messages and terms of reference becomes a
+// unit, with the message ID, e.g. "hello" or "-brand", or the ID and the
+// attribute name, e.g. "hello.title", as ID. The comment of the message
+// becomes a note of its first unit.
+//
+// The pattern becomes a segment, unless it has select expressions, in which
+// case the text around them and the value of each variant become segments.
+// Placeables become placeholders, which translators cannot change. The
+// pattern is also stored as metadata, from which Import rebuilds the select
+// expressions, so that translations have the variants of the source.
+//
+// Segments have a target if translation is given and it has a pattern with
+// the same segment.
+func Export(path string, reference syntax.Resource, translation *syntax.Resource) File {
+	file := File{Original: path, Units: make([]Unit, 0)}
+
+	translated := segment.ByID(translation)
+
+	for _, entry := range reference.Body {
+		for i, p := range segment.Patterns(entry) {
+			unit := Unit{ID: p.ID, Pattern: syntax.FormatPattern(p.Pattern)}
+			if i == 0 && p.Comment != nil {
+				unit.Notes = []string{p.Comment.Content}
+			}
+
+			var targets map[string][]syntax.PatternElement
+			if pattern, ok := translated[p.ID]; ok {
+				targets = segment.Runs(pattern)
+			}
+
+			e := exporter{unit: &unit}
+			segment.MapRuns(p.Pattern, "", func(id string, elements []syntax.PatternElement) []syntax.PatternElement {
+				if isBlank(elements) {
+					return elements
+				}
+				seg := Segment{ID: id, Source: e.inlines(elements, nil)}
+				if target, ok := targets[id]; ok {
+					seg.State = "translated"
+					seg.Target = e.inlines(target, seg.Source)
+				}
+				unit.Segments = append(unit.Segments, seg)
+				return elements
+			})
+
+			file.Units = append(file.Units, unit)
+		}
+	}
+
+	return file
+}
+
+// isBlank reports whether elements are only whitespace, which is not
+// translated.
+func isBlank(elements []syntax.PatternElement) bool {
+	for _, element := range elements {
+		text, ok := element.(syntax.TextElement)
+		if !ok || strings.TrimSpace(text.Value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+type exporter struct {
+	unit *Unit
+}
+
+// inlines converts elements to inlines. Placeables of a target reuse the
+// placeholders of the source with the same original data.
+func (e *exporter) inlines(elements []syntax.PatternElement, source []Inline) []Inline {
+	inlines := make([]Inline, 0, len(elements))
+	used := make(map[string]bool)
+	for _, element := range elements {
+		switch element := element.(type) {
+		case syntax.TextElement:
+			inlines = append(inlines, Inline{Text: element.Value})
+		case syntax.Placeable:
+			value := syntax.FormatPattern(syntax.Pattern{Elements: []syntax.PatternElement{element}})
+			ph := e.placeholder(value, source, used)
+			used[ph.ID] = true
+			inlines = append(inlines, Inline{Placeholder: ph})
+		}
+	}
+	return inlines
+}
+
+func (e *exporter) placeholder(value string, source []Inline, used map[string]bool) *Placeholder {
+	for _, inline := range source {
+		if ph := inline.Placeholder; ph != nil && !used[ph.ID] && ph.Disp == value {
+			return ph
+		}
+	}
+
+	n := len(e.unit.Data) + 1
+	data := Data{ID: "d" + strconv.Itoa(n), Value: value}
+	e.unit.Data = append(e.unit.Data, data)
+	return &Placeholder{ID: "ph" + strconv.Itoa(n), DataRef: data.ID, Disp: value}
+}
+
+// Import converts a file of an XLIFF document written by Export back to a
+// Fluent resource.
+//
+// The pattern of each unit is rebuilt from the pattern stored by Export, with
+// the text around select expressions and the values of variants replaced by
+// the targets of the segments, and placeholders replaced by their original
+// data. Messages and terms are only imported if all their segments have a
+// target.
+//
+// Units which cannot be rebuilt, e.g. because they have no pattern or a
+// placeholder without original data, are reported in the error, which joins
+// an error for each of them, and their messages are left out. The resource
+// holds the other messages even if the error is not nil.
+func Import(file File) (syntax.Resource, error) {
+	var (
+		b    segment.Builder
+		errs []error
+	)
+
+	for _, unit := range file.Units {
+		id, attribute, ok := segment.SplitID(unit.ID)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unit %q is not a message ID", unit.ID))
+			continue
+		}
+
+		msg := b.Message(id)
+		if msg.Comment == nil && len(unit.Notes) > 0 {
+			msg.Comment = &syntax.Comment{Content: strings.Join(unit.Notes, "\n")}
+		}
+
+		pattern, translated, err := importUnit(unit)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", unit.ID, err))
+			msg.Skip = true
+			continue
+		}
+		if !translated {
+			msg.Skip = true
+			continue
+		}
+		msg.SetPattern(attribute, pattern)
+	}
+
+	resource, err := b.Resource("")
+	return resource, errors.Join(append(errs, err)...)
+}
+
+// importUnit returns the translated pattern of the unit, and whether all its
+// segments have a target.
+func importUnit(unit Unit) (syntax.Pattern, bool, error) {
+	if unit.Pattern == "" {
+		return syntax.Pattern{}, false, errors.New("missing Fluent pattern")
+	}
+	source, err := syntax.ParsePattern(unit.Pattern)
+	if err != nil {
+		return syntax.Pattern{}, false, fmt.Errorf("invalid Fluent pattern: %v", err)
+	}
+
+	segments := make(map[string]Segment)
+	for _, seg := range unit.Segments {
+		segments[seg.ID] = seg
+	}
+	data := make(map[string]string)
+	for _, d := range unit.Data {
+		data[d.ID] = d.Value
+	}
+
+	translated := true
+	pattern := segment.MapRuns(source, "", func(id string, elements []syntax.PatternElement) []syntax.PatternElement {
+		if isBlank(elements) || err != nil {
+			return elements
+		}
+		seg, ok := segments[id]
+		if !ok {
+			err = fmt.Errorf("missing segment %s", id)
+			return elements
+		}
+		if strings.TrimSpace(Text(seg.Target)) == "" {
+			translated = false
+			return elements
+		}
+		var target []syntax.PatternElement
+		target, err = importInlines(seg.Target, data)
+		if err != nil {
+			err = fmt.Errorf("segment %s: %v", id, err)
+		}
+		return target
+	})
+	if err != nil || !translated {
+		return syntax.Pattern{}, translated, err
+	}
+
+	// Check that the pattern is valid, e.g. that no variant became empty.
+	pattern, err = syntax.ParsePattern(syntax.FormatPattern(pattern))
+	if err != nil {
+		return syntax.Pattern{}, false, fmt.Errorf("invalid translation: %v", err)
+	}
+	return pattern, true, nil
+}
+
+// importInlines converts inlines to pattern elements, with placeholders
+// replaced by the placeable of their original data.
+func importInlines(inlines []Inline, data map[string]string) ([]syntax.PatternElement, error) {
+	var elements []syntax.PatternElement
+	for _, inline := range inlines {
+		ph := inline.Placeholder
+		if ph == nil {
+			if n := len(elements); n > 0 {
+				if text, ok := elements[n-1].(syntax.TextElement); ok {
+					elements[n-1] = syntax.TextElement{Value: text.Value + inline.Text}
+					continue
+				}
+			}
+			elements = append(elements, syntax.TextElement{Value: inline.Text})
+			continue
+		}
+
+		value, ok := data[ph.DataRef]
+		if !ok {
+			return nil, fmt.Errorf("placeholder %s without original data", ph.ID)
+		}
+		pattern, err := syntax.ParsePattern(value)
+		if err != nil || len(pattern.Elements) != 1 {
+			return nil, fmt.Errorf("placeholder %s is not a placeable: %q", ph.ID, value)
+		}
+		placeable, ok := pattern.Elements[0].(syntax.Placeable)
+		if !ok {
+			return nil, fmt.Errorf("placeholder %s is not a placeable: %q", ph.ID, value)
+		}
+		elements = append(elements, placeable)
+	}
+	return elements, nil
+}
@@ -0,0 +1,188 @@
+package xliff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestExport(t *testing.T) {
+	reference, err := syntax.Parse([]byte(`# Greets the user.
+hello = Hello, { $name }!
+    .title = Greeting
+emails =
+    You have { $count ->
+        [one] one email
+       *[other] { $count } emails
+    }.
+`))
+	require.NoError(t, err)
+	translation, err := syntax.Parse([]byte(`hello = { $name }, hallo { $name }!
+emails =
+    Sie haben { $count ->
+        [one] eine E-Mail
+       *[other] { $count } E-Mails
+    }.
+`))
+	require.NoError(t, err)
+
+	file := Export("main.ftl", reference, &translation)
+	file.ID = "f1"
+	require.Equal(t, File{
+		ID:       "f1",
+		Original: "main.ftl",
+		Units: []Unit{
+			{
+				ID:      "hello",
+				Notes:   []string{"Greets the user."},
+				Pattern: "Hello, { $name }!",
+				Data: []Data{
+					{ID: "d1", Value: "{ $name }"},
+					{ID: "d2", Value: "{ $name }"},
+				},
+				Segments: []Segment{
+					{
+						ID:    "0",
+						State: "translated",
+						Source: []Inline{
+							{Text: "Hello, "},
+							{Placeholder: &Placeholder{ID: "ph1", DataRef: "d1", Disp: "{ $name }"}},
+							{Text: "!"},
+						},
+						Target: []Inline{
+							{Placeholder: &Placeholder{ID: "ph1", DataRef: "d1", Disp: "{ $name }"}},
+							{Text: ", hallo "},
+							{Placeholder: &Placeholder{ID: "ph2", DataRef: "d2", Disp: "{ $name }"}},
+							{Text: "!"},
+						},
+					},
+				},
+			},
+			{
+				ID:       "hello.title",
+				Pattern:  "Greeting",
+				Segments: []Segment{{ID: "0", Source: []Inline{{Text: "Greeting"}}}},
+			},
+			{
+				ID:      "emails",
+				Pattern: "You have { $count ->\n    [one] one email\n   *[other] { $count } emails\n}.",
+				Data: []Data{
+					{ID: "d1", Value: "{ $count }"},
+				},
+				Segments: []Segment{
+					{ID: "0", State: "translated", Source: []Inline{{Text: "You have "}}, Target: []Inline{{Text: "Sie haben "}}},
+					{ID: "1:one:0", State: "translated", Source: []Inline{{Text: "one email"}}, Target: []Inline{{Text: "eine E-Mail"}}},
+					{
+						ID:     "1:other:0",
+						State:  "translated",
+						Source: []Inline{{Placeholder: &Placeholder{ID: "ph1", DataRef: "d1", Disp: "{ $count }"}}, {Text: " emails"}},
+						Target: []Inline{{Placeholder: &Placeholder{ID: "ph1", DataRef: "d1", Disp: "{ $count }"}}, {Text: " E-Mails"}},
+					},
+					{ID: "2", State: "translated", Source: []Inline{{Text: "."}}, Target: []Inline{{Text: "."}}},
+				},
+			},
+		},
+	}, file)
+}
+
+func TestImport(t *testing.T) {
+	unit := func(id, pattern string, segments ...Segment) Unit {
+		return Unit{
+			ID:       id,
+			Pattern:  pattern,
+			Data:     []Data{{ID: "d1", Value: "{ $name }"}},
+			Segments: segments,
+		}
+	}
+	target := func(id string, target ...Inline) Segment {
+		return Segment{ID: id, Target: target}
+	}
+	name := Inline{Placeholder: &Placeholder{ID: "ph1", DataRef: "d1"}}
+
+	file := File{Units: []Unit{
+		unit("hello", "Hello, { $name }!", target("0", Inline{Text: "Hallo, "}, name, Inline{Text: "!"})),
+		unit("hello.title", "Greeting", target("0", Inline{Text: "Begrüßung {"})),
+		unit("-brand", "Firefox", target("0", Inline{Text: "Firefox"})),
+		unit("untranslated", "Untranslated", target("0")),
+		unit("partial", "Value", target("0", Inline{Text: "Wert"})),
+		unit("partial.title", "Title", Segment{ID: "0"}),
+		unit("emails", "{ $n ->\n    [one] One\n   *[other] Many\n}",
+			target("0:one:0", Inline{Text: "Eine"}),
+			target("0:other:0", Inline{Text: "Viele "}, name),
+		),
+		unit("missing-data", "Hello", target("0", Inline{Text: "Hallo "}, Inline{Placeholder: &Placeholder{ID: "ph9", DataRef: "d9"}})),
+		unit("missing-segment", "{ $n ->\n   *[other] Many\n}", target("0")),
+		unit("not an id", "x", target("0", Inline{Text: "y"})),
+	}}
+
+	resource, err := Import(file)
+	require.EqualError(t, err, "missing-data: segment 0: placeholder ph9 without original data\n"+
+		"missing-segment: missing segment 0:other:0\n"+
+		"unit \"not an id\" is not a message ID")
+
+	var buf bytes.Buffer
+	require.NoError(t, syntax.Fprint(&buf, resource))
+	require.Equal(t, `hello = Hallo, { $name }!
+    .title = Begrüßung { "{" }
+-brand = Firefox
+emails =
+    { $n ->
+        [one] Eine
+       *[other] Viele { $name }
+    }
+`, buf.String())
+}
+
+// TestExportImport checks that a resource exported with itself as the
+// translation imports to the same resource.
+func TestExportImport(t *testing.T) {
+	input := []byte(`# Comment
+key = Value
+    .attr = { "[" }Attribute
+-term =
+    { $case ->
+       *[nominative] Term
+        [genitive] Term's
+    }
+    .gender = masculine
+attributes-only =
+    .a = A
+multiline =
+    Line 1
+      Line 2 { $x }
+nested =
+    { $a ->
+        [1]
+            { $b ->
+               *[x] One X
+            }
+       *[other] Other
+    } { $c ->
+       *[y] Y
+    }
+`)
+	resource, err := syntax.Parse(input)
+	require.NoError(t, err)
+
+	doc := &Document{
+		SourceLanguage: "en",
+		TargetLanguage: "en",
+		Files:          []File{Export("main.ftl", resource, &resource)},
+	}
+	doc.Files[0].ID = "f1"
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, doc))
+	parsed, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+
+	imported, err := Import(parsed.Files[0])
+	require.NoError(t, err)
+
+	var actual bytes.Buffer
+	require.NoError(t, syntax.Fprint(&actual, imported))
+	require.Equal(t, string(input), actual.String())
+}
@@ -0,0 +1,370 @@
+// Package xliff converts Fluent resources to and from XLIFF 2.0 documents, for
+// translation with CAT tools.
+package xliff
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/michalnicp/fluent-go/internal/segment"
+)
+
+const (
+	// Namespace is the namespace of XLIFF 2.0 documents.
+	Namespace = "urn:oasis:names:tc:xliff:document:2.0"
+
+	// MetadataNamespace is the namespace of the metadata module, which holds
+	// the Fluent patterns of units.
+	MetadataNamespace = "urn:oasis:names:tc:xliff:metadata:2.0"
+)
+
+// A Document is an XLIFF 2.0 document.
+type Document struct {
+	// SourceLanguage and TargetLanguage are BCP 47 language tags, e.g.
+	// "en-US". TargetLanguage is optional if no segment has a target.
+	SourceLanguage string
+	TargetLanguage string
+
+	Files []File
+}
+
+// A File holds the units of a file, e.g. a Fluent file.
+type File struct {
+	ID string
+
+	// Original is the path of the file.
+	Original string
+
+	Units []Unit
+}
+
+// A Unit is a translatable message.
+type Unit struct {
+	ID    string
+	Notes []string
+
+	// Pattern is the source pattern in Fluent syntax, which is stored as
+	// metadata so that select expressions can be rebuilt.
+	Pattern string
+
+	// Data holds the original data of placeholders.
+	Data []Data
+
+	Segments []Segment
+}
+
+// A Data element holds the original data of placeholders, e.g. "{ $name }".
+type Data struct {
+	ID    string
+	Value string
+}
+
+// A Segment is a translatable part of a unit.
+type Segment struct {
+	ID string
+
+	// State is "initial", "translated", "reviewed" or "final", or empty.
+	State string
+
+	Source []Inline
+
+	// Target is nil if the segment has no target.
+	Target []Inline
+}
+
+// An Inline is text or a placeholder in the source or target of a segment.
+type Inline struct {
+	Text string
+
+	// Placeholder is set for a <ph> element, in which case Text is empty.
+	Placeholder *Placeholder
+}
+
+// A Placeholder is a <ph> element, a code which cannot be changed and is
+// displayed as Disp.
+type Placeholder struct {
+	ID      string
+	DataRef string
+	Disp    string
+}
+
+// Text returns the text of inlines, with placeholders written as their Disp.
+func Text(inlines []Inline) string {
+	var b strings.Builder
+	for _, inline := range inlines {
+		if inline.Placeholder != nil {
+			b.WriteString(inline.Placeholder.Disp)
+		} else {
+			b.WriteString(inline.Text)
+		}
+	}
+	return b.String()
+}
+
+// Parse parses an XLIFF 2.0 document. Units in groups are read as if they
+// were not grouped, and inline elements other than placeholders are replaced
+// by their content.
+func Parse(data []byte) (*Document, error) {
+	var doc document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Version != "2.0" {
+		return nil, fmt.Errorf("unsupported XLIFF version %q", doc.Version)
+	}
+
+	d := &Document{
+		SourceLanguage: doc.SrcLang,
+		TargetLanguage: doc.TrgLang,
+		Files:          make([]File, len(doc.Files)),
+	}
+	for i, f := range doc.Files {
+		file := File{ID: f.ID, Original: f.Original}
+		for _, u := range f.units() {
+			unit := Unit{ID: u.ID}
+			for _, note := range u.Notes {
+				unit.Notes = append(unit.Notes, note.Value)
+			}
+			for _, group := range u.Metadata.Groups {
+				for _, meta := range group.Meta {
+					if group.Category == metadataCategory && meta.Type == "pattern" {
+						unit.Pattern = meta.Value
+					}
+				}
+			}
+			for _, data := range u.Data {
+				unit.Data = append(unit.Data, Data{ID: data.ID, Value: data.Value})
+			}
+			for _, s := range u.Segments {
+				segment := Segment{ID: s.ID, State: s.State, Source: s.Source.Inlines}
+				if s.Target != nil {
+					segment.Target = s.Target.Inlines
+					if segment.Target == nil {
+						segment.Target = make([]Inline, 0)
+					}
+				}
+				unit.Segments = append(unit.Segments, segment)
+			}
+			file.Units = append(file.Units, unit)
+		}
+		d.Files[i] = file
+	}
+	return d, nil
+}
+
+// metadataCategory is the category of the metadata group of units holding
+// their Fluent pattern.
+const metadataCategory = "fluent"
+
+// document, file, group and unit are the elements read by Parse.
+type document struct {
+	XMLName xml.Name    `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string      `xml:"version,attr"`
+	SrcLang string      `xml:"srcLang,attr"`
+	TrgLang string      `xml:"trgLang,attr"`
+	Files   []groupElem `xml:"urn:oasis:names:tc:xliff:document:2.0 file"`
+}
+
+type groupElem struct {
+	ID       string      `xml:"id,attr"`
+	Original string      `xml:"original,attr"`
+	Groups   []groupElem `xml:"urn:oasis:names:tc:xliff:document:2.0 group"`
+	Units    []unitElem  `xml:"urn:oasis:names:tc:xliff:document:2.0 unit"`
+}
+
+// units returns the units of the file or group, followed by those of its
+// groups.
+func (g groupElem) units() []unitElem {
+	units := g.Units
+	for _, group := range g.Groups {
+		units = append(units, group.units()...)
+	}
+	return units
+}
+
+type unitElem struct {
+	ID       string `xml:"id,attr"`
+	Metadata struct {
+		Groups []struct {
+			Category string `xml:"category,attr"`
+			Meta     []struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"urn:oasis:names:tc:xliff:metadata:2.0 meta"`
+		} `xml:"urn:oasis:names:tc:xliff:metadata:2.0 metaGroup"`
+	} `xml:"urn:oasis:names:tc:xliff:metadata:2.0 metadata"`
+	Notes []struct {
+		Value string `xml:",chardata"`
+	} `xml:"urn:oasis:names:tc:xliff:document:2.0 notes>note"`
+	Data []struct {
+		ID    string `xml:"id,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"urn:oasis:names:tc:xliff:document:2.0 originalData>data"`
+	Segments []struct {
+		ID     string   `xml:"id,attr"`
+		State  string   `xml:"state,attr"`
+		Source content  `xml:"urn:oasis:names:tc:xliff:document:2.0 source"`
+		Target *content `xml:"urn:oasis:names:tc:xliff:document:2.0 target"`
+	} `xml:"urn:oasis:names:tc:xliff:document:2.0 segment"`
+}
+
+// content is the content of a <source> or <target> element.
+type content struct {
+	Inlines []Inline
+}
+
+func (c *content) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	depth := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.CharData:
+			c.appendText(string(token))
+		case xml.StartElement:
+			switch token.Name.Local {
+			case "ph":
+				ph := &Placeholder{}
+				for _, attr := range token.Attr {
+					switch attr.Name.Local {
+					case "id":
+						ph.ID = attr.Value
+					case "dataRef":
+						ph.DataRef = attr.Value
+					case "disp":
+						ph.Disp = attr.Value
+					}
+				}
+				c.Inlines = append(c.Inlines, Inline{Placeholder: ph})
+			case "cp":
+				for _, attr := range token.Attr {
+					if attr.Name.Local == "hex" {
+						r, err := strconv.ParseUint(attr.Value, 16, 32)
+						if err != nil {
+							return fmt.Errorf("invalid code point %q", attr.Value)
+						}
+						c.appendText(string(rune(r)))
+					}
+				}
+			}
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return nil
+			}
+			depth--
+		}
+	}
+}
+
+func (c *content) appendText(s string) {
+	if n := len(c.Inlines); n > 0 && c.Inlines[n-1].Placeholder == nil {
+		c.Inlines[n-1].Text += s
+		return
+	}
+	c.Inlines = append(c.Inlines, Inline{Text: s})
+}
+
+// Write writes the document as XLIFF 2.0. Nothing is written if the document
+// has characters which XML does not allow, e.g. U+0001.
+func Write(w io.Writer, doc *Document) error {
+	var (
+		buf bytes.Buffer
+		esc segment.XMLEscaper
+	)
+
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(&buf, `<xliff xmlns="%s" xmlns:mda="%s" version="2.0" srcLang="%s"`, Namespace, MetadataNamespace, esc.Attr(doc.SourceLanguage))
+	if doc.TargetLanguage != "" {
+		fmt.Fprintf(&buf, ` trgLang="%s"`, esc.Attr(doc.TargetLanguage))
+	}
+	buf.WriteString(">\n")
+
+	for _, file := range doc.Files {
+		fmt.Fprintf(&buf, `  <file id="%s"`, esc.Attr(file.ID))
+		if file.Original != "" {
+			fmt.Fprintf(&buf, ` original="%s"`, esc.Attr(file.Original))
+		}
+		buf.WriteString(" xml:space=\"preserve\">\n")
+		for _, unit := range file.Units {
+			writeUnit(&buf, &esc, unit)
+			if err := esc.Err(); err != nil {
+				return fmt.Errorf("unit %s: %v", unit.ID, err)
+			}
+		}
+		buf.WriteString("  </file>\n")
+	}
+
+	buf.WriteString("</xliff>\n")
+	if err := esc.Err(); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeUnit(buf *bytes.Buffer, esc *segment.XMLEscaper, unit Unit) {
+	fmt.Fprintf(buf, "    <unit id=\"%s\">\n", esc.Attr(unit.ID))
+	if unit.Pattern != "" {
+		buf.WriteString("      <mda:metadata>\n")
+		fmt.Fprintf(buf, "        <mda:metaGroup category=\"%s\">\n", metadataCategory)
+		fmt.Fprintf(buf, "          <mda:meta type=\"pattern\">%s</mda:meta>\n", esc.Text(unit.Pattern))
+		buf.WriteString("        </mda:metaGroup>\n")
+		buf.WriteString("      </mda:metadata>\n")
+	}
+	if len(unit.Notes) > 0 {
+		buf.WriteString("      <notes>\n")
+		for _, note := range unit.Notes {
+			fmt.Fprintf(buf, "        <note>%s</note>\n", esc.Text(note))
+		}
+		buf.WriteString("      </notes>\n")
+	}
+	if len(unit.Data) > 0 {
+		buf.WriteString("      <originalData>\n")
+		for _, data := range unit.Data {
+			fmt.Fprintf(buf, "        <data id=\"%s\">%s</data>\n", esc.Attr(data.ID), esc.Text(data.Value))
+		}
+		buf.WriteString("      </originalData>\n")
+	}
+	for _, seg := range unit.Segments {
+		fmt.Fprintf(buf, "      <segment id=\"%s\"", esc.Attr(seg.ID))
+		if seg.State != "" {
+			fmt.Fprintf(buf, " state=\"%s\"", esc.Attr(seg.State))
+		}
+		buf.WriteString(">\n")
+		fmt.Fprintf(buf, "        <source>%s</source>\n", inlinesString(esc, seg.Source))
+		if seg.Target != nil {
+			fmt.Fprintf(buf, "        <target>%s</target>\n", inlinesString(esc, seg.Target))
+		}
+		buf.WriteString("      </segment>\n")
+	}
+	buf.WriteString("    </unit>\n")
+}
+
+// inlinesString returns the inlines as XML. Placeholders cannot be copied or
+// deleted.
+func inlinesString(esc *segment.XMLEscaper, inlines []Inline) string {
+	var b strings.Builder
+	for _, inline := range inlines {
+		ph := inline.Placeholder
+		if ph == nil {
+			b.WriteString(esc.Text(inline.Text))
+			continue
+		}
+		fmt.Fprintf(&b, `<ph id="%s"`, esc.Attr(ph.ID))
+		if ph.DataRef != "" {
+			fmt.Fprintf(&b, ` dataRef="%s"`, esc.Attr(ph.DataRef))
+		}
+		if ph.Disp != "" {
+			fmt.Fprintf(&b, ` disp="%s"`, esc.Attr(ph.Disp))
+		}
+		b.WriteString(` canCopy="no" canDelete="no"/>`)
+	}
+	return b.String()
+}
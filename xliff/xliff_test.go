@@ -0,0 +1,118 @@
+package xliff
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/michalnicp/fluent-go/syntax"
+)
+
+func TestWrite(t *testing.T) {
+	name := &Placeholder{ID: "ph1", DataRef: "d1", Disp: "{ $name }"}
+	doc := &Document{
+		SourceLanguage: "en-US",
+		TargetLanguage: "de",
+		Files: []File{{
+			ID:       "f1",
+			Original: "main.ftl",
+			Units: []Unit{{
+				ID:      "hello",
+				Notes:   []string{"Greets the user.\n<b>Short</b> & sweet."},
+				Pattern: "Hello, { $name }!",
+				Data:    []Data{{ID: "d1", Value: "{ $name }"}},
+				Segments: []Segment{{
+					ID:     "0",
+					State:  "translated",
+					Source: []Inline{{Text: "Hello, "}, {Placeholder: name}, {Text: "!"}},
+					Target: []Inline{{Text: "Hallo, "}, {Placeholder: name}, {Text: "!"}},
+				}},
+			}},
+		}},
+	}
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" xmlns:mda="urn:oasis:names:tc:xliff:metadata:2.0" version="2.0" srcLang="en-US" trgLang="de">
+  <file id="f1" original="main.ftl" xml:space="preserve">
+    <unit id="hello">
+      <mda:metadata>
+        <mda:metaGroup category="fluent">
+          <mda:meta type="pattern">Hello, { $name }!</mda:meta>
+        </mda:metaGroup>
+      </mda:metadata>
+      <notes>
+        <note>Greets the user.
+&lt;b&gt;Short&lt;/b&gt; &amp; sweet.</note>
+      </notes>
+      <originalData>
+        <data id="d1">{ $name }</data>
+      </originalData>
+      <segment id="0" state="translated">
+        <source>Hello, <ph id="ph1" dataRef="d1" disp="{ $name }" canCopy="no" canDelete="no"/>!</source>
+        <target>Hallo, <ph id="ph1" dataRef="d1" disp="{ $name }" canCopy="no" canDelete="no"/>!</target>
+      </segment>
+    </unit>
+  </file>
+</xliff>
+`
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, doc))
+	require.Equal(t, expected, buf.String())
+
+	parsed, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, doc, parsed)
+}
+
+func TestParse(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en">
+  <file id="f1">
+    <unit id="a">
+      <segment>
+        <source>A<mrk id="m1" translate="no">B</mrk><cp hex="0001"/></source>
+        <target/>
+      </segment>
+    </unit>
+    <group id="g1">
+      <unit id="b">
+        <segment>
+          <source>B</source>
+        </segment>
+      </unit>
+    </group>
+  </file>
+</xliff>
+`
+
+	doc, err := Parse([]byte(input))
+	require.NoError(t, err)
+	require.Equal(t, &Document{
+		SourceLanguage: "en",
+		Files: []File{{
+			ID: "f1",
+			Units: []Unit{
+				{ID: "a", Segments: []Segment{{Source: []Inline{{Text: "AB\x01"}}, Target: []Inline{}}}},
+				{ID: "b", Segments: []Segment{{Source: []Inline{{Text: "B"}}}}},
+			},
+		}},
+	}, doc)
+
+	_, err = Parse([]byte(`<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2"></xliff>`))
+	require.Error(t, err)
+}
+
+func TestWriteInvalidCharacter(t *testing.T) {
+	reference, err := syntax.Parse([]byte("hello = Hello\x01!\n"))
+	require.NoError(t, err)
+	doc := &Document{
+		SourceLanguage: "en-US",
+		Files:          []File{Export("main.ftl", reference, nil)},
+	}
+
+	var buf bytes.Buffer
+	require.EqualError(t, Write(&buf, doc), "unit hello: character U+0001 is not allowed in XML")
+	require.Empty(t, buf.String())
+}